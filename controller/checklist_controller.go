@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// タスク内のチェックリストに関連する操作を定義したインターフェース
+type IChecklistController interface {
+	GetChecklistItems(c echo.Context) error     // チェックリストを取得
+	CreateChecklistItem(c echo.Context) error   // 項目を追加
+	UpdateChecklistItem(c echo.Context) error   // 項目を更新
+	ToggleChecklistItem(c echo.Context) error   // チェック状態を切り替える
+	ReorderChecklistItems(c echo.Context) error // 項目を並べ替える
+	DeleteChecklistItem(c echo.Context) error   // 項目を削除
+	ConvertChecklistItem(c echo.Context) error  // 項目をサブタスクに変換
+}
+
+type checklistController struct {
+	clu usecase.IChecklistUsecase
+}
+
+// コンストラクタ関数
+func NewChecklistController(clu usecase.IChecklistUsecase) IChecklistController {
+	return &checklistController{clu}
+}
+
+// 指定されたタスクのチェックリストを取得
+func (clc *checklistController) GetChecklistItems(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	itemsRes, err := clc.clu.GetChecklistItems(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, itemsRes)
+}
+
+// 指定されたタスクのチェックリストに項目を追加
+func (clc *checklistController) CreateChecklistItem(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	// リクエストボディから項目をバインド
+	item := model.ChecklistItem{}
+	if err := c.Bind(&item); err != nil {
+		return err
+	}
+
+	itemRes, err := clc.clu.CreateChecklistItem(item, uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, itemRes)
+}
+
+// 指定された項目のテキストとチェック状態を更新
+func (clc *checklistController) UpdateChecklistItem(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDと項目IDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	id = c.Param("itemId")
+	itemId, _ := strconv.Atoi(id)
+
+	// リクエストボディから項目をバインド
+	item := model.ChecklistItem{}
+	if err := c.Bind(&item); err != nil {
+		return err
+	}
+
+	itemRes, err := clc.clu.UpdateChecklistItem(item, uint(userId.(float64)), uint(taskId), uint(itemId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, itemRes)
+}
+
+// 指定された項目のチェック状態を切り替える
+func (clc *checklistController) ToggleChecklistItem(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDと項目IDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	id = c.Param("itemId")
+	itemId, _ := strconv.Atoi(id)
+
+	itemRes, err := clc.clu.ToggleChecklistItem(uint(userId.(float64)), uint(taskId), uint(itemId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, itemRes)
+}
+
+// 指定されたタスクのチェックリストを並べ替える
+func (clc *checklistController) ReorderChecklistItems(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	// リクエストボディから新しい順序をバインド
+	req := model.ChecklistReorderRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	itemsRes, err := clc.clu.ReorderChecklistItems(uint(userId.(float64)), uint(taskId), req.ItemIds)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, itemsRes)
+}
+
+// 指定された項目を削除
+func (clc *checklistController) DeleteChecklistItem(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDと項目IDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	id = c.Param("itemId")
+	itemId, _ := strconv.Atoi(id)
+
+	if err := clc.clu.DeleteChecklistItem(uint(userId.(float64)), uint(taskId), uint(itemId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 指定された項目をサブタスクに変換
+func (clc *checklistController) ConvertChecklistItem(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDと項目IDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+	id = c.Param("itemId")
+	itemId, _ := strconv.Atoi(id)
+
+	taskRes, err := clc.clu.ConvertChecklistItem(uint(userId.(float64)), uint(taskId), uint(itemId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, taskRes)
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// タスクへのコメントに関連する操作を定義したインターフェース
+type ICommentController interface {
+	GetComments(c echo.Context) error   // コメント一覧を取得
+	CreateComment(c echo.Context) error // コメントを作成
+	DeleteComment(c echo.Context) error // コメントを削除
+}
+
+type commentController struct {
+	cu usecase.ICommentUsecase
+}
+
+// コンストラクタ関数
+func NewCommentController(cu usecase.ICommentUsecase) ICommentController {
+	return &commentController{cu}
+}
+
+// 指定されたタスクのコメントを取得
+func (cc *commentController) GetComments(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	commentRes, err := cc.cu.GetComments(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, commentRes)
+}
+
+// 指定されたタスクにコメントを作成
+func (cc *commentController) CreateComment(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	// リクエストボディからコメントをバインド
+	comment := model.Comment{}
+	if err := c.Bind(&comment); err != nil {
+		return err
+	}
+	comment.TaskId = uint(taskId)
+	comment.UserId = uint(userId.(float64))
+
+	commentRes, err := cc.cu.CreateComment(comment)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, commentRes)
+}
+
+// 指定されたIDのコメントを削除（自分のコメントのみ）
+func (cc *commentController) DeleteComment(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからコメントIDを取得
+	id := c.Param("commentId")
+	commentId, _ := strconv.Atoi(id)
+
+	if err := cc.cu.DeleteComment(uint(userId.(float64)), uint(commentId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
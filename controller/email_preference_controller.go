@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// メールの配信設定に関連する操作を定義したインターフェース
+type IEmailPreferenceController interface {
+	GetEmailPreference(c echo.Context) error    // 配信設定を取得
+	UpdateEmailPreference(c echo.Context) error // 配信設定を更新
+	Unsubscribe(c echo.Context) error           // 配信停止リンクからメールを停止（認証不要）
+}
+
+type emailPreferenceController struct {
+	eu usecase.IEmailUsecase
+}
+
+// コンストラクタ関数
+func NewEmailPreferenceController(eu usecase.IEmailUsecase) IEmailPreferenceController {
+	return &emailPreferenceController{eu}
+}
+
+// ログインしているユーザーのメール配信設定を取得
+func (ec *emailPreferenceController) GetEmailPreference(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	prefRes, err := ec.eu.GetEmailPreference(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, prefRes)
+}
+
+// ログインしているユーザーのメール配信設定を更新
+func (ec *emailPreferenceController) UpdateEmailPreference(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// リクエストボディから配信設定をバインド
+	pref := model.EmailPreference{}
+	if err := c.Bind(&pref); err != nil {
+		return err
+	}
+	prefRes, err := ec.eu.UpdateEmailPreference(pref, uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, prefRes)
+}
+
+// 配信停止リンク（?token=...&type=...）から指定された種類のメールを停止
+// メール内のリンク（GET）と、メールクライアントのワンクリック配信停止（POST）の両方に対応
+func (ec *emailPreferenceController) Unsubscribe(c echo.Context) error {
+	emailType := c.QueryParam("type")
+	if err := ec.eu.Unsubscribe(c.QueryParam("token"), emailType); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"unsubscribed": emailType,
+	})
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/query"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// ドメインエラーの種類ごとのステータスコード
+var errorKindStatus = map[usecase.ErrorKind]int{
+	usecase.KindInvalid:       http.StatusBadRequest,
+	usecase.KindUnauthorized:  http.StatusUnauthorized,
+	usecase.KindForbidden:     http.StatusForbidden,
+	usecase.KindNotFound:      http.StatusNotFound,
+	usecase.KindConflict:      http.StatusConflict,
+	usecase.KindUnprocessable: http.StatusUnprocessableEntity,
+}
+
+// 個別のエラーの種類を表す type のURIの接頭辞（末尾にエラーコードを付ける）
+const problemTypePrefix = "urn:problem-type:"
+
+// Echo の HTTPErrorHandler
+// ハンドラーやミドルウェアが返したエラーを、RFC 7807 の application/problem+json 形式で返す
+// - 入力値の検証エラーは 422（フィールドごとのエラーを含み、Accept-Language の言語で返す）
+// - ドメインエラーは種類に応じて 400 / 401 / 403 / 404 / 409 / 422
+// - 検索クエリの解析エラーは 400、Echo のエラー（バインドの失敗や認証エラーなど）はそのステータス
+// - それ以外は 500 とし、内部のエラー内容はレスポンスに含めずログにのみ出力する
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	problem := toProblem(c, err)
+	problem.Instance = c.Request().URL.Path
+	problem.RequestId = c.Response().Header().Get(echo.HeaderXRequestID)
+	if problem.Status >= http.StatusInternalServerError {
+		log.Printf("request %s: %s %s: %v", problem.RequestId, c.Request().Method, problem.Instance, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, model.ProblemContentType)
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(problem.Status)
+	} else {
+		err = c.JSON(problem.Status, problem)
+	}
+	if err != nil {
+		log.Printf("request %s: failed to write error response: %v", problem.RequestId, err)
+	}
+}
+
+// エラーを problem+json のレスポンスに変換
+func toProblem(c echo.Context, err error) model.Problem {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		problem := statusProblem(he.Code)
+		if message := fmt.Sprint(he.Message); message != http.StatusText(he.Code) {
+			problem.Detail = message
+		}
+		return problem
+	}
+
+	var verr *validator.ValidationError
+	if errors.As(err, &verr) {
+		lang := validator.NegotiateLanguage(c.Request().Header.Get("Accept-Language"))
+		c.Response().Header().Set("Content-Language", lang)
+		res := verr.Response(lang)
+		return model.Problem{
+			Type:   problemTypePrefix + "validation_failed",
+			Title:  res.Message,
+			Status: http.StatusUnprocessableEntity,
+			Code:   "validation_failed",
+			Errors: res.Errors,
+		}
+	}
+
+	if derr, ok := usecase.AsError(err); ok {
+		status := errorKindStatus[derr.Kind]
+		// ユースケースで付け加えた詳細（"invalid stats parameter: ..." など）は含め、
+		// リポジトリのエラーから変換したものはドメインエラーのメッセージのみを返す
+		detail := derr.Message
+		if errors.As(err, new(*usecase.Error)) {
+			detail = err.Error()
+		}
+		return model.Problem{
+			Type:   problemTypePrefix + derr.Code,
+			Title:  http.StatusText(status),
+			Status: status,
+			Detail: detail,
+			Code:   derr.Code,
+		}
+	}
+
+	if errors.As(err, new(*query.ParseError)) {
+		return model.Problem{
+			Type:   problemTypePrefix + "invalid_query",
+			Title:  http.StatusText(http.StatusBadRequest),
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+			Code:   "invalid_query",
+		}
+	}
+
+	problem := statusProblem(http.StatusInternalServerError)
+	problem.Detail = "an unexpected error occurred"
+	return problem
+}
+
+// ステータスコードのみで表すエラー（type は about:blank、code はステータスの名前）
+func statusProblem(status int) model.Problem {
+	title := http.StatusText(status)
+	return model.Problem{
+		Type:   "about:blank",
+		Title:  title,
+		Status: status,
+		Code:   strings.ReplaceAll(strings.ToLower(title), " ", "_"),
+	}
+}
+
+// 400 Bad Request のエラーを作成（パラメータの形式が正しくない場合など）
+func badRequest(message string) error {
+	return echo.NewHTTPError(http.StatusBadRequest, message)
+}
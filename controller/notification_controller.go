@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// リアルタイム配信の接続を維持するためのコメント送信間隔
+const streamKeepAliveInterval = 30 * time.Second
+
+// 通知に関連する操作を定義したインターフェース
+type INotificationController interface {
+	GetNotifications(c echo.Context) error // 通知一覧を取得
+	GetUnreadCount(c echo.Context) error   // 未読件数を取得
+	MarkAsRead(c echo.Context) error       // 通知を既読にする
+	MarkAllAsRead(c echo.Context) error    // すべての通知を既読にする
+	Stream(c echo.Context) error           // 通知をリアルタイムに受信（Server-Sent Events）
+}
+
+type notificationController struct {
+	nu usecase.INotificationUsecase
+}
+
+// コンストラクタ関数
+func NewNotificationController(nu usecase.INotificationUsecase) INotificationController {
+	return &notificationController{nu}
+}
+
+// ログインしているユーザーの通知を新しい順に取得（?unread=true で未読のみ）
+func (nc *notificationController) GetNotifications(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	notificationRes, err := nc.nu.GetNotifications(uint(userId.(float64)), c.QueryParam("unread") == "true")
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, notificationRes)
+}
+
+// 未読件数を取得
+func (nc *notificationController) GetUnreadCount(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	count, err := nc.nu.GetUnreadCount(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"count": count,
+	})
+}
+
+// 指定されたIDの通知を既読にする
+func (nc *notificationController) MarkAsRead(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータから通知IDを取得
+	id := c.Param("notificationId")
+	notificationId, _ := strconv.Atoi(id)
+
+	if err := nc.nu.MarkAsRead(uint(userId.(float64)), uint(notificationId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// すべての通知を既読にする
+func (nc *notificationController) MarkAllAsRead(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	if err := nc.nu.MarkAllAsRead(uint(userId.(float64))); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 通知をServer-Sent Eventsでリアルタイムに配信
+// クライアントが切断するまで接続を維持する
+func (nc *notificationController) Stream(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	notifications, unsubscribe := nc.nu.Subscribe(uint(userId.(float64)))
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ticker := time.NewTicker(streamKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			// クライアントが切断した
+			return nil
+		case n, ok := <-notifications:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(n)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(res, "id: %d\nevent: notification\ndata: %s\n\n", n.ID, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-ticker.C:
+			// プロキシによる切断を防ぐためのコメント行
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
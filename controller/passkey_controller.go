@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// 認証器の応答（PublicKeyCredential の JSON）として受け付ける最大サイズ
+const maxPasskeyResponseSize = 64 * 1024
+
+// パスキー（WebAuthn）に関連する操作を定義したインターフェース
+type IPasskeyController interface {
+	GetPasskeys(c echo.Context) error        // 登録済みのパスキーの一覧を取得
+	BeginRegistration(c echo.Context) error  // パスキーの登録を開始する
+	FinishRegistration(c echo.Context) error // パスキーを登録する
+	DeletePasskey(c echo.Context) error      // パスキーを削除する
+	BeginLogin(c echo.Context) error         // パスワードなしのログインを開始する
+	LogIn(c echo.Context) error              // パスキーでログインし、トークンをCookieでセットする
+	IssueToken(c echo.Context) error         // パスキーでログインし、トークンをレスポンスボディで返す
+	BeginMfaLogin(c echo.Context) error      // ログインの2段階目をパスキーで開始する
+	LogInMfa(c echo.Context) error           // ログインの2段階目をパスキーで完了し、トークンをCookieでセットする
+	IssueTokenMfa(c echo.Context) error      // ログインの2段階目をパスキーで完了し、トークンをレスポンスボディで返す
+}
+
+type passkeyController struct {
+	ku usecase.IPasskeyUsecase
+}
+
+// コンストラクタ関数
+func NewPasskeyController(ku usecase.IPasskeyUsecase) IPasskeyController {
+	return &passkeyController{ku}
+}
+
+// ログインしているユーザーのパスキーの一覧を取得
+func (kc *passkeyController) GetPasskeys(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	passkeysRes, err := kc.ku.GetPasskeys(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, passkeysRes)
+}
+
+// パスキーの登録を開始し、navigator.credentials.create() に渡すオプションを返す
+func (kc *passkeyController) BeginRegistration(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.PasskeyRegistrationRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	creation, err := kc.ku.BeginRegistration(uint(userId.(float64)), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, creation)
+}
+
+// navigator.credentials.create() の結果を検証して、パスキーを登録する
+func (kc *passkeyController) FinishRegistration(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	body, err := readPasskeyResponse(c)
+	if err != nil {
+		return err
+	}
+	passkeyRes, err := kc.ku.FinishRegistration(uint(userId.(float64)), body)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, passkeyRes)
+}
+
+// 指定されたIDのパスキーを削除
+func (kc *passkeyController) DeletePasskey(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからパスキーIDを取得
+	id := c.Param("passkeyId")
+	passkeyId, _ := strconv.Atoi(id)
+
+	if err := kc.ku.DeletePasskey(uint(userId.(float64)), uint(passkeyId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// パスワードなしのログインを開始し、navigator.credentials.get() に渡すオプションを返す
+func (kc *passkeyController) BeginLogin(c echo.Context) error {
+	assertion, err := kc.ku.BeginLogin()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, assertion)
+}
+
+// navigator.credentials.get() の結果を検証してログインし、トークンをCookieとしてセットする
+func (kc *passkeyController) LogIn(c echo.Context) error {
+	body, err := readPasskeyResponse(c)
+	if err != nil {
+		return err
+	}
+	tokens, err := kc.ku.FinishLogin(body, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+	setTokenCookies(c, tokens)
+	return c.NoContent(http.StatusOK)
+}
+
+// navigator.credentials.get() の結果を検証してログインし、トークンをレスポンスボディで返す（モバイルアプリ向け）
+func (kc *passkeyController) IssueToken(c echo.Context) error {
+	body, err := readPasskeyResponse(c)
+	if err != nil {
+		return err
+	}
+	tokens, err := kc.ku.FinishLogin(body, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// mfa_token を受け取り、ログインの2段階目に使う navigator.credentials.get() のオプションを返す
+func (kc *passkeyController) BeginMfaLogin(c echo.Context) error {
+	req := model.MfaPasskeyRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	assertion, err := kc.ku.BeginMfaLogin(req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, assertion)
+}
+
+// ログインの2段階目をパスキーで完了し、トークンをCookieとしてセットする
+func (kc *passkeyController) LogInMfa(c echo.Context) error {
+	body, err := readPasskeyResponse(c)
+	if err != nil {
+		return err
+	}
+	tokens, err := kc.ku.FinishMfaLogin(body, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+	setTokenCookies(c, tokens)
+	return c.NoContent(http.StatusOK)
+}
+
+// ログインの2段階目をパスキーで完了し、トークンをレスポンスボディで返す（モバイルアプリ向け）
+func (kc *passkeyController) IssueTokenMfa(c echo.Context) error {
+	body, err := readPasskeyResponse(c)
+	if err != nil {
+		return err
+	}
+	tokens, err := kc.ku.FinishMfaLogin(body, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// リクエストボディの認証器の応答を読み込む（ライブラリが JSON を直接解析するため、バインドはしない）
+func readPasskeyResponse(c echo.Context) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxPasskeyResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxPasskeyResponseSize {
+		return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, "passkey response is too large")
+	}
+	return body, nil
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// パーソナルアクセストークンに関連する操作を定義したインターフェース
+type IPersonalAccessTokenController interface {
+	GetPersonalAccessTokens(c echo.Context) error    // トークン一覧を取得
+	GetPersonalAccessTokenById(c echo.Context) error // IDによるトークンの取得
+	CreatePersonalAccessToken(c echo.Context) error  // トークンの作成
+	UpdatePersonalAccessToken(c echo.Context) error  // トークンの名前とスコープの更新
+	DeletePersonalAccessToken(c echo.Context) error  // トークンの削除
+}
+
+type personalAccessTokenController struct {
+	pu usecase.IPersonalAccessTokenUsecase
+}
+
+// コンストラクタ関数
+func NewPersonalAccessTokenController(pu usecase.IPersonalAccessTokenUsecase) IPersonalAccessTokenController {
+	return &personalAccessTokenController{pu}
+}
+
+// ログインしているユーザーのトークンをすべて取得（トークンの平文は含まない）
+func (pc *personalAccessTokenController) GetPersonalAccessTokens(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	tokensRes, err := pc.pu.GetPersonalAccessTokens(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tokensRes)
+}
+
+// 指定されたIDのトークンを取得
+func (pc *personalAccessTokenController) GetPersonalAccessTokenById(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからトークンIDを取得
+	id := c.Param("tokenId")
+	tokenId, _ := strconv.Atoi(id)
+
+	tokenRes, err := pc.pu.GetPersonalAccessTokenById(uint(userId.(float64)), uint(tokenId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tokenRes)
+}
+
+// 新しいトークンを作成
+// レスポンスの token は再表示できないため、クライアントで保存する必要がある
+func (pc *personalAccessTokenController) CreatePersonalAccessToken(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// リクエストボディからトークンの名前・スコープ・有効期限をバインド
+	token := model.PersonalAccessToken{}
+	if err := c.Bind(&token); err != nil {
+		return err
+	}
+	token.UserId = uint(userId.(float64))
+	tokenRes, err := pc.pu.CreatePersonalAccessToken(token)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, tokenRes)
+}
+
+// 指定されたIDのトークンの名前とスコープを更新
+func (pc *personalAccessTokenController) UpdatePersonalAccessToken(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからトークンIDを取得
+	id := c.Param("tokenId")
+	tokenId, _ := strconv.Atoi(id)
+
+	// リクエストボディからトークンの名前とスコープをバインド
+	token := model.PersonalAccessToken{}
+	if err := c.Bind(&token); err != nil {
+		return err
+	}
+	tokenRes, err := pc.pu.UpdatePersonalAccessToken(token, uint(userId.(float64)), uint(tokenId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tokenRes)
+}
+
+// 指定されたIDのトークンを削除
+func (pc *personalAccessTokenController) DeletePersonalAccessToken(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからトークンIDを取得
+	id := c.Param("tokenId")
+	tokenId, _ := strconv.Atoi(id)
+
+	if err := pc.pu.DeletePersonalAccessToken(uint(userId.(float64)), uint(tokenId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// プロジェクトとカスタムフィールドの定義に関連する操作を定義したインターフェース
+type IProjectController interface {
+	GetAllProjects(c echo.Context) error    // プロジェクト一覧を取得
+	GetProjectById(c echo.Context) error    // IDによるプロジェクトの取得
+	CreateProject(c echo.Context) error     // プロジェクトの作成
+	UpdateProject(c echo.Context) error     // プロジェクトの更新
+	DeleteProject(c echo.Context) error     // プロジェクトの削除
+	GetCustomFields(c echo.Context) error   // カスタムフィールドの一覧を取得
+	CreateCustomField(c echo.Context) error // カスタムフィールドの追加
+	UpdateCustomField(c echo.Context) error // カスタムフィールドの更新
+	DeleteCustomField(c echo.Context) error // カスタムフィールドの削除
+}
+
+type projectController struct {
+	pu usecase.IProjectUsecase
+}
+
+// コンストラクタ関数
+func NewProjectController(pu usecase.IProjectUsecase) IProjectController {
+	return &projectController{pu}
+}
+
+// ログインしているユーザーのプロジェクトをすべて取得
+func (pc *projectController) GetAllProjects(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	projectRes, err := pc.pu.GetAllProjects(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projectRes)
+}
+
+// 指定されたIDのプロジェクトを取得
+func (pc *projectController) GetProjectById(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからプロジェクトIDを取得
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	projectRes, err := pc.pu.GetProjectById(uint(userId.(float64)), uint(projectId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projectRes)
+}
+
+// 新しいプロジェクトを作成
+func (pc *projectController) CreateProject(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// リクエストボディからプロジェクト情報をバインド
+	project := model.Project{}
+	if err := c.Bind(&project); err != nil {
+		return err
+	}
+	project.UserId = uint(userId.(float64))
+
+	projectRes, err := pc.pu.CreateProject(project)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, projectRes)
+}
+
+// 指定されたIDのプロジェクトを更新
+func (pc *projectController) UpdateProject(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからプロジェクトIDを取得
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	// リクエストボディからプロジェクト情報をバインド
+	project := model.Project{}
+	if err := c.Bind(&project); err != nil {
+		return err
+	}
+
+	projectRes, err := pc.pu.UpdateProject(project, uint(userId.(float64)), uint(projectId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projectRes)
+}
+
+// 指定されたIDのプロジェクトを削除
+func (pc *projectController) DeleteProject(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからプロジェクトIDを取得
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	if err := pc.pu.DeleteProject(uint(userId.(float64)), uint(projectId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 指定されたプロジェクトのカスタムフィールドを取得
+func (pc *projectController) GetCustomFields(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからプロジェクトIDを取得
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	fieldRes, err := pc.pu.GetCustomFields(uint(userId.(float64)), uint(projectId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, fieldRes)
+}
+
+// 指定されたプロジェクトにカスタムフィールドを追加
+func (pc *projectController) CreateCustomField(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからプロジェクトIDを取得
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+
+	// リクエストボディからカスタムフィールドの定義をバインド
+	field := model.CustomField{}
+	if err := c.Bind(&field); err != nil {
+		return err
+	}
+
+	fieldRes, err := pc.pu.CreateCustomField(field, uint(userId.(float64)), uint(projectId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, fieldRes)
+}
+
+// 指定されたカスタムフィールドを更新
+func (pc *projectController) UpdateCustomField(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからプロジェクトIDとフィールドIDを取得
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	id = c.Param("fieldId")
+	fieldId, _ := strconv.Atoi(id)
+
+	// リクエストボディからカスタムフィールドの定義をバインド
+	field := model.CustomField{}
+	if err := c.Bind(&field); err != nil {
+		return err
+	}
+
+	fieldRes, err := pc.pu.UpdateCustomField(field, uint(userId.(float64)), uint(projectId), uint(fieldId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, fieldRes)
+}
+
+// 指定されたカスタムフィールドを削除
+func (pc *projectController) DeleteCustomField(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからプロジェクトIDとフィールドIDを取得
+	id := c.Param("projectId")
+	projectId, _ := strconv.Atoi(id)
+	id = c.Param("fieldId")
+	fieldId, _ := strconv.Atoi(id)
+
+	if err := pc.pu.DeleteCustomField(uint(userId.(float64)), uint(projectId), uint(fieldId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// リマインダーに関連する操作を定義したインターフェース
+type IReminderController interface {
+	GetReminders(c echo.Context) error        // タスクに設定した自分のリマインダーを取得
+	CreateReminder(c echo.Context) error      // リマインダーを作成
+	DeleteReminder(c echo.Context) error      // リマインダーを削除
+	GetWebhookSecret(c echo.Context) error    // webhook の署名のシークレットを取得
+	RotateWebhookSecret(c echo.Context) error // webhook の署名のシークレットを再発行する
+}
+
+type reminderController struct {
+	ru usecase.IReminderUsecase
+}
+
+// コンストラクタ関数
+func NewReminderController(ru usecase.IReminderUsecase) IReminderController {
+	return &reminderController{ru}
+}
+
+// タスクに設定した自分のリマインダーを取得
+func (rc *reminderController) GetReminders(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	remindersRes, err := rc.ru.GetReminders(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, remindersRes)
+}
+
+// タスクにリマインダーを作成（例: {"offset_minutes": 30, "channel": "email"} で期限の30分前にメール）
+func (rc *reminderController) CreateReminder(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	// リクエストボディからリマインダーをバインド
+	reminder := model.Reminder{}
+	if err := c.Bind(&reminder); err != nil {
+		return err
+	}
+
+	reminderRes, err := rc.ru.CreateReminder(reminder, uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, reminderRes)
+}
+
+// 指定されたIDのリマインダーを削除
+func (rc *reminderController) DeleteReminder(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDとリマインダーIDを取得
+	taskId, _ := strconv.Atoi(c.Param("taskId"))
+	reminderId, _ := strconv.Atoi(c.Param("reminderId"))
+
+	if err := rc.ru.DeleteReminder(uint(userId.(float64)), uint(taskId), uint(reminderId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// webhook の署名のシークレットを取得
+func (rc *reminderController) GetWebhookSecret(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	secretRes, err := rc.ru.GetWebhookSecret(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, secretRes)
+}
+
+// webhook の署名のシークレットを再発行する
+func (rc *reminderController) RotateWebhookSecret(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	secretRes, err := rc.ru.RotateWebhookSecret(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, secretRes)
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// 保存済みフィルターに関連する操作を定義したインターフェース
+type ISavedFilterController interface {
+	GetAllSavedFilters(c echo.Context) error // フィルター一覧を取得
+	GetSavedFilterById(c echo.Context) error // IDによるフィルターの取得
+	CreateSavedFilter(c echo.Context) error  // フィルターの作成
+	UpdateSavedFilter(c echo.Context) error  // フィルターの更新
+	DeleteSavedFilter(c echo.Context) error  // フィルターの削除
+	GetFilteredTasks(c echo.Context) error   // フィルターを適用したタスク一覧を取得
+}
+
+type savedFilterController struct {
+	fu usecase.ISavedFilterUsecase
+}
+
+// コンストラクタ関数
+func NewSavedFilterController(fu usecase.ISavedFilterUsecase) ISavedFilterController {
+	return &savedFilterController{fu}
+}
+
+// ログインしているユーザーのフィルターをすべて取得
+func (fc *savedFilterController) GetAllSavedFilters(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	filterRes, err := fc.fu.GetAllSavedFilters(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, filterRes)
+}
+
+// 指定されたIDのフィルターを取得
+func (fc *savedFilterController) GetSavedFilterById(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからフィルターIDを取得
+	id := c.Param("filterId")
+	filterId, _ := strconv.Atoi(id)
+
+	filterRes, err := fc.fu.GetSavedFilterById(uint(userId.(float64)), uint(filterId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, filterRes)
+}
+
+// 新しいフィルターを作成
+func (fc *savedFilterController) CreateSavedFilter(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// リクエストボディからフィルター情報をバインド
+	filter := model.SavedFilter{}
+	if err := c.Bind(&filter); err != nil {
+		return err
+	}
+	filter.UserId = uint(userId.(float64))
+
+	filterRes, err := fc.fu.CreateSavedFilter(filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, filterRes)
+}
+
+// 指定されたIDのフィルターを更新
+func (fc *savedFilterController) UpdateSavedFilter(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからフィルターIDを取得
+	id := c.Param("filterId")
+	filterId, _ := strconv.Atoi(id)
+
+	// リクエストボディからフィルター情報をバインド
+	filter := model.SavedFilter{}
+	if err := c.Bind(&filter); err != nil {
+		return err
+	}
+
+	filterRes, err := fc.fu.UpdateSavedFilter(filter, uint(userId.(float64)), uint(filterId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, filterRes)
+}
+
+// 指定されたIDのフィルターを削除
+func (fc *savedFilterController) DeleteSavedFilter(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからフィルターIDを取得
+	id := c.Param("filterId")
+	filterId, _ := strconv.Atoi(id)
+
+	if err := fc.fu.DeleteSavedFilter(uint(userId.(float64)), uint(filterId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 保存済みのクエリを適用してタスクを取得
+func (fc *savedFilterController) GetFilteredTasks(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからフィルターIDを取得
+	id := c.Param("filterId")
+	filterId, _ := strconv.Atoi(id)
+
+	taskRes, err := fc.fu.GetFilteredTasks(uint(userId.(float64)), uint(filterId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, taskRes)
+}
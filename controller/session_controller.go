@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// セッション（ログインした端末）に関連する操作を定義したインターフェース
+type ISessionController interface {
+	GetSessions(c echo.Context) error   // 有効なセッションの一覧を取得
+	RevokeSession(c echo.Context) error // セッションを失効させる
+}
+
+type sessionController struct {
+	su usecase.ISessionUsecase
+}
+
+// コンストラクタ関数
+func NewSessionController(su usecase.ISessionUsecase) ISessionController {
+	return &sessionController{su}
+}
+
+// ログインしているユーザーの有効なセッションの一覧を取得
+// リクエストに使われたセッションには current: true が付く
+func (sc *sessionController) GetSessions(c echo.Context) error {
+	// JWTトークンからユーザーIDとセッションIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	sessionId := claims["sid"]
+
+	sessionsRes, err := sc.su.GetSessions(uint(userId.(float64)), uint(sessionId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sessionsRes)
+}
+
+// 指定されたIDのセッションを失効させ、その端末をログアウトさせる
+// 自分自身のセッションを指定した場合は、Cookieのトークンも削除する
+func (sc *sessionController) RevokeSession(c echo.Context) error {
+	// JWTトークンからユーザーIDとセッションIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+	currentSessionId := claims["sid"]
+
+	// パスパラメータからセッションIDを取得
+	id := c.Param("sessionId")
+	sessionId, _ := strconv.Atoi(id)
+
+	if err := sc.su.RevokeSession(uint(userId.(float64)), uint(sessionId)); err != nil {
+		return err
+	}
+	if uint(sessionId) == uint(currentSessionId.(float64)) {
+		clearTokenCookies(c)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// 統計情報に関連する操作を定義したインターフェース
+type IStatsController interface {
+	GetStats(c echo.Context) error // 統計情報を取得
+}
+
+type statsController struct {
+	su usecase.IStatsUsecase
+}
+
+// コンストラクタ関数
+func NewStatsController(su usecase.IStatsUsecase) IStatsController {
+	return &statsController{su}
+}
+
+// ログインしているユーザーの統計情報を取得
+// クエリパラメータ: from, to (YYYY-MM-DD), interval (day / week), tz (例: Asia/Tokyo)
+func (sc *statsController) GetStats(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// tz を空で指定した場合は省略とみなさず、タイムゾーン名の検証エラーとする
+	if c.QueryParams().Has("tz") && c.QueryParam("tz") == "" {
+		return validator.NewFieldError("tz", validator.CodeInvalidTimezone, nil)
+	}
+
+	statsRes, err := sc.su.GetStats(uint(userId.(float64)), c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("interval"), c.QueryParam("tz"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, statsRes)
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// 差分同期に関連する操作を定義したインターフェース
+type ISyncController interface {
+	GetChanges(c echo.Context) error   // since トークン以降の変更を取得
+	ApplyChanges(c echo.Context) error // オフラインでの変更をまとめて適用
+}
+
+type syncController struct {
+	su usecase.ISyncUsecase
+}
+
+// コンストラクタ関数
+func NewSyncController(su usecase.ISyncUsecase) ISyncController {
+	return &syncController{su}
+}
+
+// since トークン（?since=...）以降に作成・更新・削除されたタスクとコメントを取得
+func (sc *syncController) GetChanges(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	syncRes, err := sc.su.GetChanges(uint(userId.(float64)), c.QueryParam("since"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, syncRes)
+}
+
+// オフラインでの変更をまとめて適用し、変更ごとの結果を返す
+func (sc *syncController) ApplyChanges(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// リクエストボディから変更の一覧をバインド
+	req := model.SyncRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	syncRes, err := sc.su.ApplyChanges(uint(userId.(float64)), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, syncRes)
+}
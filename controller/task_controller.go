@@ -12,11 +12,18 @@ import (
 
 // ITaskController は、タスクに関連する操作を定義したインターフェース
 type ITaskController interface {
-	GetAllTasks(c echo.Context) error // すべてのタスクを取得
-	GetTaskById(c echo.Context) error // IDによるタスクの取得
-	CreateTask(c echo.Context) error  // タスクの作成
-	UpdateTask(c echo.Context) error  // タスクの更新
-	DeleteTask(c echo.Context) error  // タスクの削除
+	GetAllTasks(c echo.Context) error       // すべてのタスクを取得
+	GetTaskById(c echo.Context) error       // IDによるタスクの取得
+	CreateTask(c echo.Context) error        // タスクの作成
+	QuickAddTask(c echo.Context) error      // 1行のテキストからタスクを作成
+	UpdateTask(c echo.Context) error        // タスクの更新
+	DeleteTask(c echo.Context) error        // タスクの削除
+	AssignTask(c echo.Context) error        // タスクを他のユーザーに割り当て
+	UnassignTask(c echo.Context) error      // 割り当ての解除
+	AcceptAssignment(c echo.Context) error  // 割り当ての承諾
+	DeclineAssignment(c echo.Context) error // 割り当ての辞退
+	SnoozeTask(c echo.Context) error        // タスクのスヌーズ
+	UnsnoozeTask(c echo.Context) error      // スヌーズの解除
 }
 
 // タスクに関連する操作を実装する構造体
@@ -36,11 +43,25 @@ func (tc taskController) GetAllTasks(c echo.Context) error {
 	claims := user.Claims.(jwt.MapClaims)
 	userId := claims["user_id"]
 
-	// ユーザーIDを基にタスクを取得
-	taskRes, err := tc.tu.GetAllTasks(uint(userId.(float64)))
+	// 検索クエリ（?q=）が指定されていれば条件に一致するタスクのみ取得
+	// ?assigned_to=me の場合は自分が担当者になっているタスクを取得
+	var taskRes []model.TaskResponse
+	var err error
+	assignedTo := c.QueryParam("assigned_to")
+	if assignedTo != "" && assignedTo != "me" {
+		return badRequest("assigned_to must be me")
+	}
+	if assignedTo == "me" {
+		taskRes, err = tc.tu.GetAssignedTasks(uint(userId.(float64)), c.QueryParam("q"))
+	} else if q := c.QueryParam("q"); q != "" {
+		taskRes, err = tc.tu.SearchTasks(uint(userId.(float64)), q)
+	} else {
+		// ユーザーIDを基にタスクを取得
+		taskRes, err = tc.tu.GetAllTasks(uint(userId.(float64)))
+	}
 	if err != nil {
-		// エラーがあれば内部サーバーエラーを返す
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		// 検索クエリの解析エラーなどは共通のエラーハンドラーでレスポンスにする
+		return err
 	}
 	return c.JSON(http.StatusOK, taskRes) // 成功した場合、タスクのリストを返す
 }
@@ -59,7 +80,7 @@ func (tc taskController) GetTaskById(c echo.Context) error {
 	// ユーザーIDとタスクIDを基にタスクを取得
 	taskRes, err := tc.tu.GetTaskById(uint(userId.(float64)), uint(taskId))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error()) // エラーがあれば内部サーバーエラーを返す
+		return err // エラーのステータスは共通のエラーハンドラーで決まる
 	}
 	return c.JSON(http.StatusOK, taskRes) // 成功した場合、タスク情報を返す
 }
@@ -74,7 +95,7 @@ func (tc taskController) CreateTask(c echo.Context) error {
 	// リクエストボディからタスク情報をバインド
 	task := model.Task{}
 	if err := c.Bind(&task); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error()) // バインディングエラーがあれば、400 Bad Requestを返す
+		return err // バインディングエラー（400 Bad Request）をそのまま返す
 	}
 
 	// ユーザーIDをタスクに設定
@@ -82,11 +103,38 @@ func (tc taskController) CreateTask(c echo.Context) error {
 	// タスク作成処理を呼び出し
 	taskRes, err := tc.tu.CreateTask(task)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error()) // エラーがあれば内部サーバーエラーを返す
+		return err // エラーのステータスは共通のエラーハンドラーで決まる
 	}
 	return c.JSON(http.StatusCreated, taskRes) // 成功した場合、作成したタスクを返す
 }
 
+// 1行のテキストを解析してタスクを作成
+// dry_run（ボディまたはクエリパラメータ）が true の場合は解析結果のみ返す
+func (tc taskController) QuickAddTask(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// リクエストボディからテキストをバインド
+	req := model.QuickAddRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err // バインディングエラー（400 Bad Request）をそのまま返す
+	}
+	if c.QueryParam("dry_run") == "true" {
+		req.DryRun = true
+	}
+
+	quickRes, err := tc.tu.QuickAddTask(uint(userId.(float64)), req)
+	if err != nil {
+		return err // エラーのステータスは共通のエラーハンドラーで決まる
+	}
+	if req.DryRun {
+		return c.JSON(http.StatusOK, quickRes) // 解析結果のみを返す
+	}
+	return c.JSON(http.StatusCreated, quickRes) // 成功した場合、解析結果と作成したタスクを返す
+}
+
 // 指定されたIDのタスクを更新
 func (tc taskController) UpdateTask(c echo.Context) error {
 	// JWTトークンからユーザーIDを取得
@@ -98,16 +146,16 @@ func (tc taskController) UpdateTask(c echo.Context) error {
 	id := c.Param("taskId")
 	taskId, _ := strconv.Atoi(id)
 
-	// リクエストボディからタスク情報をバインド
-	task := model.Task{}
-	if err := c.Bind(&task); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error()) // バインディングエラーがあれば、400 Bad Requestを返す
+	// リクエストボディから更新する項目をバインド（省略された項目は変更しない）
+	update := model.TaskUpdate{}
+	if err := c.Bind(&update); err != nil {
+		return err // バインディングエラー（400 Bad Request）をそのまま返す
 	}
 
 	// タスク更新処理を呼び出し
-	taskRes, err := tc.tu.UpdateTask(task, uint(userId.(float64)), uint(taskId))
+	taskRes, err := tc.tu.UpdateTask(update, uint(userId.(float64)), uint(taskId))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error()) // エラーがあれば内部サーバーエラーを返す
+		return err // エラーのステータスは共通のエラーハンドラーで決まる
 	}
 	return c.JSON(http.StatusOK, taskRes) // 成功した場合、更新したタスク情報を返す
 }
@@ -126,7 +174,119 @@ func (tc taskController) DeleteTask(c echo.Context) error {
 	// タスク削除処理を呼び出し
 	err := tc.tu.DeleteTask(uint(userId.(float64)), uint(taskId))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error()) // エラーがあれば内部サーバーエラーを返す
+		return err // エラーのステータスは共通のエラーハンドラーで決まる
 	}
 	return c.NoContent(http.StatusNoContent) // 成功した場合、No Contentレスポンスを返す
 }
+
+// タスクを他のユーザーに割り当てる（作成者のみ）
+func (tc taskController) AssignTask(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得し、整数に変換
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	// リクエストボディから担当者のメールアドレスをバインド
+	req := model.TaskAssignRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	taskRes, err := tc.tu.AssignTask(uint(userId.(float64)), uint(taskId), req.Email)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, taskRes)
+}
+
+// 割り当てを解除する（作成者のみ）
+func (tc taskController) UnassignTask(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得し、整数に変換
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	taskRes, err := tc.tu.UnassignTask(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, taskRes)
+}
+
+// 割り当てを承諾する（担当者のみ）
+func (tc taskController) AcceptAssignment(c echo.Context) error {
+	return tc.respondToAssignment(c, true)
+}
+
+// 割り当てを辞退する（担当者のみ）
+func (tc taskController) DeclineAssignment(c echo.Context) error {
+	return tc.respondToAssignment(c, false)
+}
+
+// 割り当ての承諾・辞退の共通処理
+func (tc taskController) respondToAssignment(c echo.Context, accept bool) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得し、整数に変換
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	taskRes, err := tc.tu.RespondToAssignment(uint(userId.(float64)), uint(taskId), accept)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, taskRes)
+}
+
+// 指定された日時までタスクをスヌーズする（その間はタスクの一覧に表示されない）
+func (tc taskController) SnoozeTask(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得し、整数に変換
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	// リクエストボディからスヌーズの期限をバインド
+	req := model.TaskSnoozeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	taskRes, err := tc.tu.SnoozeTask(uint(userId.(float64)), uint(taskId), req.Until)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, taskRes)
+}
+
+// スヌーズを解除する
+func (tc taskController) UnsnoozeTask(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得し、整数に変換
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	taskRes, err := tc.tu.UnsnoozeTask(uint(userId.(float64)), uint(taskId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, taskRes)
+}
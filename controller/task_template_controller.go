@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// タスクテンプレートに関連する操作を定義したインターフェース
+type ITaskTemplateController interface {
+	GetAllTaskTemplates(c echo.Context) error // テンプレート一覧を取得
+	GetTaskTemplateById(c echo.Context) error // IDによるテンプレートの取得
+	CreateTaskTemplate(c echo.Context) error  // テンプレートの作成
+	UpdateTaskTemplate(c echo.Context) error  // テンプレートの更新
+	DeleteTaskTemplate(c echo.Context) error  // テンプレートの削除
+	Instantiate(c echo.Context) error         // テンプレートからタスクを作成
+}
+
+type taskTemplateController struct {
+	ttu usecase.ITaskTemplateUsecase
+}
+
+// コンストラクタ関数
+func NewTaskTemplateController(ttu usecase.ITaskTemplateUsecase) ITaskTemplateController {
+	return &taskTemplateController{ttu}
+}
+
+// ログインしているユーザーのテンプレートをすべて取得
+func (ttc *taskTemplateController) GetAllTaskTemplates(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	templateRes, err := ttc.ttu.GetAllTaskTemplates(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, templateRes)
+}
+
+// 指定されたIDのテンプレートを取得
+func (ttc *taskTemplateController) GetTaskTemplateById(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからテンプレートIDを取得
+	id := c.Param("templateId")
+	templateId, _ := strconv.Atoi(id)
+
+	templateRes, err := ttc.ttu.GetTaskTemplateById(uint(userId.(float64)), uint(templateId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, templateRes)
+}
+
+// 新しいテンプレートを作成
+func (ttc *taskTemplateController) CreateTaskTemplate(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// リクエストボディからテンプレート情報をバインド
+	template := model.TaskTemplate{}
+	if err := c.Bind(&template); err != nil {
+		return err
+	}
+	template.UserId = uint(userId.(float64))
+
+	templateRes, err := ttc.ttu.CreateTaskTemplate(template)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, templateRes)
+}
+
+// 指定されたIDのテンプレートを更新
+func (ttc *taskTemplateController) UpdateTaskTemplate(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからテンプレートIDを取得
+	id := c.Param("templateId")
+	templateId, _ := strconv.Atoi(id)
+
+	// リクエストボディからテンプレート情報をバインド
+	template := model.TaskTemplate{}
+	if err := c.Bind(&template); err != nil {
+		return err
+	}
+
+	templateRes, err := ttc.ttu.UpdateTaskTemplate(template, uint(userId.(float64)), uint(templateId))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, templateRes)
+}
+
+// 指定されたIDのテンプレートを削除
+func (ttc *taskTemplateController) DeleteTaskTemplate(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからテンプレートIDを取得
+	id := c.Param("templateId")
+	templateId, _ := strconv.Atoi(id)
+
+	if err := ttc.ttu.DeleteTaskTemplate(uint(userId.(float64)), uint(templateId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// テンプレートからタスクを一括作成
+func (ttc *taskTemplateController) Instantiate(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからテンプレートIDを取得
+	id := c.Param("templateId")
+	templateId, _ := strconv.Atoi(id)
+
+	// リクエストボディから変数と基準日時をバインド
+	req := model.TaskTemplateInstantiateRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	taskRes, err := ttc.ttu.Instantiate(uint(userId.(float64)), uint(templateId), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, taskRes)
+}
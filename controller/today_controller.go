@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// 「今日」の計画（My Day）に関連する操作を定義したインターフェース
+type ITodayController interface {
+	GetToday(c echo.Context) error        // My Day と今日が期限・期限切れのタスクを取得
+	AddToMyDay(c echo.Context) error      // My Day にタスクを追加
+	RemoveFromMyDay(c echo.Context) error // My Day からタスクを外す
+}
+
+type todayController struct {
+	tu usecase.ITaskUsecase
+}
+
+// コンストラクタ関数
+func NewTodayController(tu usecase.ITaskUsecase) ITodayController {
+	return &todayController{tu}
+}
+
+// My Day に選んだタスクと、今日が期限・期限切れのタスクを取得
+func (tc *todayController) GetToday(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	todayRes, err := tc.tu.GetToday(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, todayRes)
+}
+
+// 今日の My Day にタスクを追加
+func (tc *todayController) AddToMyDay(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	if err := tc.tu.AddToMyDay(uint(userId.(float64)), uint(taskId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 今日の My Day からタスクを外す
+func (tc *todayController) RemoveFromMyDay(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	// パスパラメータからタスクIDを取得
+	id := c.Param("taskId")
+	taskId, _ := strconv.Atoi(id)
+
+	if err := tc.tu.RemoveFromMyDay(uint(userId.(float64)), uint(taskId)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
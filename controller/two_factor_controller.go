@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// 2段階認証（TOTP）の設定に関連する操作を定義したインターフェース
+type ITwoFactorController interface {
+	GetStatus(c echo.Context) error               // 設定状況を取得
+	Setup(c echo.Context) error                   // 設定を開始する
+	Confirm(c echo.Context) error                 // 設定を確定して有効にする
+	RegenerateRecoveryCodes(c echo.Context) error // リカバリーコードを再発行する
+	Disable(c echo.Context) error                 // 無効にする
+}
+
+type twoFactorController struct {
+	tu usecase.ITwoFactorUsecase
+}
+
+// コンストラクタ関数
+func NewTwoFactorController(tu usecase.ITwoFactorUsecase) ITwoFactorController {
+	return &twoFactorController{tu}
+}
+
+// 2段階認証が有効かどうかと、未使用のリカバリーコードの数を取得
+func (tc *twoFactorController) GetStatus(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	statusRes, err := tc.tu.GetStatus(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, statusRes)
+}
+
+// 2段階認証の設定を開始し、認証アプリに登録するシークレットと provisioning_uri を返す
+func (tc *twoFactorController) Setup(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	setupRes, err := tc.tu.Setup(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, setupRes)
+}
+
+// 認証アプリの確認コードで設定を確定し、リカバリーコードを返す
+func (tc *twoFactorController) Confirm(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.TwoFactorCodeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	codesRes, err := tc.tu.Confirm(uint(userId.(float64)), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, codesRes)
+}
+
+// 確認コードかリカバリーコードを確認して、新しいリカバリーコードを返す
+func (tc *twoFactorController) RegenerateRecoveryCodes(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.TwoFactorCodeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	codesRes, err := tc.tu.RegenerateRecoveryCodes(uint(userId.(float64)), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, codesRes)
+}
+
+// 確認コードかリカバリーコードを確認して、2段階認証を無効にする
+func (tc *twoFactorController) Disable(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.TwoFactorCodeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := tc.tu.Disable(uint(userId.(float64)), req); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
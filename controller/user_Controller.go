@@ -5,20 +5,41 @@ import (
 	"os"
 	"time"
 
+	appmiddleware "github.com/DaigoSugiyama0317/Echo-REST-API/middleware"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 )
 
 // ユーザーに関するコントローラーのインターフェース定義
 // 各関数は HTTP リクエストを受け取って処理を行う
 type IUserController interface {
-	SignUp(c echo.Context) error    // ユーザー登録
-	LogIn(c echo.Context) error     // ログイン
-	LogOut(c echo.Context) error    // ログアウト
-	CsrfToken(c echo.Context) error // CSRFトークンの取得
+	SignUp(c echo.Context) error                  // ユーザー登録
+	LogIn(c echo.Context) error                   // ログイン
+	LogInMfa(c echo.Context) error                // 2段階認証の確認コードによるログインの2段階目
+	LogOut(c echo.Context) error                  // ログアウト
+	LogOutAll(c echo.Context) error               // すべての端末からログアウト
+	ChangePassword(c echo.Context) error          // パスワードの変更
+	GetMe(c echo.Context) error                   // ログイン中のユーザーの情報の取得
+	ChangeEmail(c echo.Context) error             // メールアドレスの変更
+	DeleteAccount(c echo.Context) error           // アカウントの削除
+	RefreshToken(c echo.Context) error            // リフレッシュトークンによるトークンの再発行
+	IssueToken(c echo.Context) error              // ログインしてトークンをレスポンスボディで返す（CLIやモバイルアプリ向け）
+	IssueTokenMfa(c echo.Context) error           // ログインの2段階目でトークンをレスポンスボディで返す（CLIやモバイルアプリ向け）
+	RefreshTokenInBody(c echo.Context) error      // リクエストボディのリフレッシュトークンによるトークンの再発行
+	VerifyEmail(c echo.Context) error             // 確認リンクのトークンでメールアドレスを確認
+	ResendVerificationEmail(c echo.Context) error // 確認リンクの再送
+	ForgotPassword(c echo.Context) error          // パスワードの再設定リンクの送信
+	ResetPassword(c echo.Context) error           // 再設定リンクのトークンによるパスワードの再設定
+	CsrfToken(c echo.Context) error               // CSRFトークンの取得
 }
 
+const (
+	accessTokenCookie  = "token"         // アクセストークン（JWT）のCookie名
+	refreshTokenCookie = "refresh_token" // リフレッシュトークンのCookie名
+)
+
 // コントローラー構造体：ユースケース層を保持して依存注入
 type userController struct {
 	uu usecase.IUserUsecase
@@ -35,57 +56,325 @@ func (uc *userController) SignUp(c echo.Context) error {
 	user := model.User{}
 	// リクエストボディを user 構造体にバインド
 	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	// ユースケース層に登録処理を呼び出し
 	userRes, err := uc.uu.SignUp(user)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	// 成功したら201 Createdでユーザー情報を返す
 	return c.JSON(http.StatusCreated, userRes)
 }
 
 // ログイン処理のハンドラー
+// 2段階目が必要なユーザー（2段階認証が有効、またはパスキーを登録している）には Cookie をセットせず、
+// POST /login/mfa（または /login/mfa/passkey）に送信する mfa_token を返す
 func (uc *userController) LogIn(c echo.Context) error {
 	user := model.User{}
 	// リクエストボディを user 構造体にバインド
 	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	// ユースケース層でトークンの発行
-	tokenString, err := uc.uu.LogIn(user)
+	result, err := uc.uu.LogIn(user, sessionMeta(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return err
 	}
-	// トークンをCookieとしてセット（セキュリティ設定付き）
-	cookie := new(http.Cookie)
-	cookie.Name = "token"
-	cookie.Value = tokenString
-	cookie.Expires = time.Now().Add(24 * time.Hour)
-	cookie.Path = "/"
-	cookie.Domain = os.Getenv("API_DOMAIN") // ドメインは環境変数から取得
-	//cookie.Secure = true                    // HTTPS限定
-	cookie.HttpOnly = true                  // JSからアクセス不可
-	cookie.SameSite = http.SameSiteNoneMode // クロスサイト送信許可
-	c.SetCookie(cookie)
+	if result.MfaToken != "" {
+		return c.JSON(http.StatusOK, mfaRequired(result))
+	}
+	// アクセストークンとリフレッシュトークンをCookieとしてセット
+	setTokenCookies(c, result.Tokens)
+	return c.NoContent(http.StatusOK)
+}
+
+// ログインの2段階目のハンドラー
+// mfa_token と認証アプリの確認コード（またはリカバリーコード）を確認して、トークンをCookieとしてセットする
+func (uc *userController) LogInMfa(c echo.Context) error {
+	req := model.MfaLoginRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	tokens, err := uc.uu.LogInMfa(req, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+	setTokenCookies(c, tokens)
 	return c.NoContent(http.StatusOK)
 }
 
 // ログアウト処理のハンドラー
+// Cookieのトークンをサーバー側でも失効させるため、コピーされたトークンも使えなくなる
+// Authorization: Bearer ヘッダーがある場合は、そのアクセストークンのセッションを失効させる
 func (uc *userController) LogOut(c echo.Context) error {
+	accessToken, refreshToken := "", ""
+	if token, ok := appmiddleware.BearerToken(c); ok {
+		accessToken = token
+	} else if cookie, err := c.Cookie(accessTokenCookie); err == nil {
+		accessToken = cookie.Value
+	}
+	if cookie, err := c.Cookie(refreshTokenCookie); err == nil {
+		refreshToken = cookie.Value
+	}
 	// Cookieの内容を無効にして上書き（即時期限切れ）
+	clearTokenCookies(c)
+	if err := uc.uu.LogOut(accessToken, refreshToken); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// すべての端末からログアウトするハンドラー
+// 発行済みのアクセストークンとリフレッシュトークンをすべて失効させる
+func (uc *userController) LogOutAll(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	if err := uc.uu.LogOutAll(uint(userId.(float64))); err != nil {
+		return err
+	}
+	clearTokenCookies(c)
+	return c.NoContent(http.StatusOK)
+}
+
+// パスワードを変更するハンドラー
+// ほかの端末のトークンはすべて失効し、この端末には新しいトークンをCookieでセットする
+// Authorization: Bearer で認証したリクエストには、新しいトークンをレスポンスボディで返す
+func (uc *userController) ChangePassword(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.PasswordChangeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	tokens, err := uc.uu.ChangePassword(uint(userId.(float64)), req, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+	if _, ok := appmiddleware.BearerToken(c); ok {
+		return c.JSON(http.StatusOK, tokens)
+	}
+	setTokenCookies(c, tokens)
+	return c.NoContent(http.StatusOK)
+}
+
+// ログイン中のユーザーの情報を取得するハンドラー
+func (uc *userController) GetMe(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	userRes, err := uc.uu.GetUser(uint(userId.(float64)))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, userRes)
+}
+
+// メールアドレスを変更するハンドラー
+// 新しいアドレスに確認リンクを送信し、確認が済むまでは未確認として扱う
+func (uc *userController) ChangeEmail(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.EmailChangeRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	userRes, err := uc.uu.ChangeEmail(uint(userId.(float64)), req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, userRes)
+}
+
+// アカウントを削除するハンドラー
+// タスクなどのデータもすべて削除し、トークンのCookieを削除する
+func (uc *userController) DeleteAccount(c echo.Context) error {
+	// JWTトークンからユーザーIDを取得
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(jwt.MapClaims)
+	userId := claims["user_id"]
+
+	req := model.AccountDeleteRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := uc.uu.DeleteAccount(uint(userId.(float64)), req); err != nil {
+		return err
+	}
+	clearTokenCookies(c)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// リフレッシュトークン（Cookie）を使ってトークンを再発行するハンドラー
+// リフレッシュトークンは使うたびに新しいものに置き換わる
+func (uc *userController) RefreshToken(c echo.Context) error {
+	refreshToken := ""
+	if cookie, err := c.Cookie(refreshTokenCookie); err == nil {
+		refreshToken = cookie.Value
+	}
+	tokens, err := uc.uu.RefreshTokens(refreshToken)
+	if err != nil {
+		// 無効なリフレッシュトークンは再度使われないようCookieから削除する
+		if derr, ok := usecase.AsError(err); ok && derr.Kind == usecase.KindUnauthorized {
+			clearTokenCookies(c)
+		}
+		return err
+	}
+	setTokenCookies(c, tokens)
+	return c.NoContent(http.StatusOK)
+}
+
+// ログインしてトークンをレスポンスボディで返すハンドラー（CLIやモバイルアプリ向け）
+// 以降のリクエストでは Authorization: Bearer ヘッダーにアクセストークンを付ける
+func (uc *userController) IssueToken(c echo.Context) error {
+	user := model.User{}
+	// リクエストボディを user 構造体にバインド
+	if err := c.Bind(&user); err != nil {
+		return err
+	}
+	result, err := uc.uu.LogIn(user, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+	// 2段階目が必要なユーザーは POST /auth/token/mfa（または /auth/token/mfa/passkey）で2段階目を行う
+	if result.MfaToken != "" {
+		return c.JSON(http.StatusOK, mfaRequired(result))
+	}
+	return c.JSON(http.StatusOK, result.Tokens)
+}
+
+// ログインの2段階目で、トークンをレスポンスボディで返すハンドラー（CLIやモバイルアプリ向け）
+func (uc *userController) IssueTokenMfa(c echo.Context) error {
+	req := model.MfaLoginRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	tokens, err := uc.uu.LogInMfa(req, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// リクエストボディのリフレッシュトークンでトークンを再発行し、レスポンスボディで返すハンドラー
+func (uc *userController) RefreshTokenInBody(c echo.Context) error {
+	req := model.RefreshTokenRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	tokens, err := uc.uu.RefreshTokens(req.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// 確認リンクのトークンでメールアドレスを確認するハンドラー
+// ログインしていなくても（別の端末でリンクを開いた場合も）確認できる
+func (uc *userController) VerifyEmail(c echo.Context) error {
+	req := model.EmailVerificationRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := uc.uu.VerifyEmail(req.Token); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 確認リンクを再送するハンドラー
+// 登録されているかどうかに関わらず 202 を返す
+func (uc *userController) ResendVerificationEmail(c echo.Context) error {
+	req := model.ResendVerificationRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := uc.uu.ResendVerificationEmail(req.Email); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+// パスワードの再設定リンクをメールで送信するハンドラー
+// 登録されているかどうかに関わらず 202 を返す
+func (uc *userController) ForgotPassword(c echo.Context) error {
+	req := model.PasswordForgotRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := uc.uu.ForgotPassword(req.Email); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+// 再設定リンクのトークンでパスワードを再設定するハンドラー
+// すべての端末からログアウトされるため、新しいパスワードで再度ログインする
+func (uc *userController) ResetPassword(c echo.Context) error {
+	req := model.PasswordResetRequest{}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := uc.uu.ResetPassword(req); err != nil {
+		return err
+	}
+	clearTokenCookies(c)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 確認コードの入力が必要なことを表すレスポンス
+func mfaRequired(result model.LoginResult) model.MfaRequiredResponse {
+	return model.MfaRequiredResponse{
+		MfaRequired: true,
+		MfaToken:    result.MfaToken,
+		ExpiresAt:   result.MfaTokenExpiresAt,
+		Methods:     result.MfaMethods,
+	}
+}
+
+// セッションに記録する端末の情報をリクエストから取得
+func sessionMeta(c echo.Context) model.SessionMeta {
+	return model.SessionMeta{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}
+}
+
+// アクセストークンとリフレッシュトークンをCookieとしてセット
+func setTokenCookies(c echo.Context, tokens model.AuthTokens) {
+	c.SetCookie(newTokenCookie(accessTokenCookie, tokens.AccessToken, tokens.AccessTokenExpiresAt))
+	c.SetCookie(newTokenCookie(refreshTokenCookie, tokens.RefreshToken, tokens.RefreshTokenExpiresAt))
+}
+
+// トークンのCookieを空の値で上書きして削除
+func clearTokenCookies(c echo.Context) {
+	c.SetCookie(newTokenCookie(accessTokenCookie, "", time.Now()))
+	c.SetCookie(newTokenCookie(refreshTokenCookie, "", time.Now()))
+}
+
+// トークン用のCookieを作成（セキュリティ設定付き）
+func newTokenCookie(name string, value string, expires time.Time) *http.Cookie {
 	cookie := new(http.Cookie)
-	cookie.Name = "token"
-	cookie.Value = ""
-	cookie.Expires = time.Now()
+	cookie.Name = name
+	cookie.Value = value
+	cookie.Expires = expires
 	cookie.Path = "/"
-	cookie.Domain = os.Getenv("API_DOMAIN")
-	//cookie.Secure = true
-	cookie.HttpOnly = true
-	cookie.SameSite = http.SameSiteNoneMode
-	c.SetCookie(cookie)
-	return c.NoContent(http.StatusOK)
+	cookie.Domain = os.Getenv("API_DOMAIN") // ドメインは環境変数から取得
+	//cookie.Secure = true                    // HTTPS限定
+	cookie.HttpOnly = true                  // JSからアクセス不可
+	cookie.SameSite = http.SameSiteNoneMode // クロスサイト送信許可
+	return cookie
 }
 
 // CSRFトークンをJSONで返すハンドラー
@@ -30,7 +30,9 @@ func NewDB() *gorm.DB {
 		os.Getenv("POSTGRES_PORT"), os.Getenv("POSTGRES_DB"))
 
 	// GORMを使用してPostgreSQLデータベースに接続します。
-	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{
+		TranslateError: true, // 一意制約の違反などを gorm.ErrDuplicatedKey などの共通のエラーに変換
+	})
 	if err != nil {
 		log.Fatalln(err) // データベース接続に失敗した場合はエラーログを出力して終了
 	}
@@ -0,0 +1,146 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"sort"
+	"time"
+)
+
+// 送信するメール
+type Message struct {
+	To      string
+	Subject string
+	Text    string            // テキスト版の本文
+	HTML    string            // HTML版の本文（空の場合はテキストのみ）
+	Headers map[string]string // 追加のヘッダー（List-Unsubscribe など）
+}
+
+// メール送信のインターフェース
+type IMailer interface {
+	Send(msg Message) error
+}
+
+// SMTPの接続設定
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // 空の場合は認証しない（MailHogなどのローカル環境向け）
+	Password string
+	From     string
+}
+
+// 環境変数からSMTPの接続設定を読み込む
+func LoadSMTPConfig() SMTPConfig {
+	cfg := SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+	if cfg.Port == "" {
+		cfg.Port = "25"
+	}
+	if cfg.From == "" {
+		cfg.From = "no-reply@localhost"
+	}
+	return cfg
+}
+
+// SMTPでメールを送信する実装
+type smtpMailer struct {
+	cfg SMTPConfig
+}
+
+// コンストラクタ関数
+func NewSMTPMailer(cfg SMTPConfig) IMailer {
+	return &smtpMailer{cfg}
+}
+
+// メールを送信
+func (m *smtpMailer) Send(msg Message) error {
+	body, err := buildMessage(m.cfg.From, msg)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(m.cfg.Host, m.cfg.Port), auth, m.cfg.From, []string{msg.To}, body)
+}
+
+// テキスト版とHTML版を含むMIMEメッセージを組み立てる
+func buildMessage(from string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	headers := map[string]string{
+		"From":         from,
+		"To":           msg.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"MIME-Version": "1.0",
+	}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	boundary := ""
+	if msg.HTML != "" {
+		b := make([]byte, 12)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		boundary = "boundary-" + hex.EncodeToString(b)
+		headers["Content-Type"] = fmt.Sprintf("multipart/alternative; boundary=%q", boundary)
+	} else {
+		headers["Content-Type"] = "text/plain; charset=utf-8"
+		headers["Content-Transfer-Encoding"] = "quoted-printable"
+	}
+
+	// ヘッダーは名前順に出力
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, headers[k])
+	}
+	buf.WriteString("\r\n")
+
+	if boundary == "" {
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\nContent-Type: %s\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", boundary, part.contentType)
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+// 本文をquoted-printableでエンコードして書き込む（改行はCRLFに変換される）
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	return w.Close()
+}
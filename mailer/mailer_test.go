@@ -0,0 +1,329 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// 受信したメール
+type receivedMail struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// テスト用のSMTPサーバー
+// 認証やSTARTTLSには対応せず、受け取ったメールを mails に送る
+type fakeSMTPServer struct {
+	ln    net.Listener
+	mails chan receivedMail
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTPServer{ln: ln, mails: make(chan receivedMail, 10)}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+// SMTPConfig の接続先
+func (s *fakeSMTPServer) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	return SMTPConfig{Host: host, Port: port, From: "no-reply@example.com"}
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	m := receivedMail{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); verb {
+		case "EHLO":
+			reply("250 localhost")
+		case "HELO", "RSET", "NOOP":
+			reply("250 OK")
+		case "MAIL":
+			m.from = strings.Trim(strings.TrimPrefix(cmd, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			m.to = append(m.to, strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data bytes.Buffer
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				// ドットで始まる行は送信側でドットが重ねられている
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			m.data = data.Bytes()
+			s.mails <- m
+			m = receivedMail{}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// 送信したメールを受け取る
+func (s *fakeSMTPServer) receive(t *testing.T) receivedMail {
+	t.Helper()
+	select {
+	case m := <-s.mails:
+		return m
+	default:
+		t.Fatal("no mail received")
+		return receivedMail{}
+	}
+}
+
+// 受信したメールをパースし、テキスト版とHTML版の本文をデコードする
+func parseMail(t *testing.T, data []byte) (*mail.Message, string, string) {
+	t.Helper()
+	// 日本語を含むメールでも、ヘッダー・本文ともにASCIIのみでエンコードされていること
+	for i, b := range data {
+		if b >= 0x80 {
+			t.Fatalf("non-ASCII byte at offset %d", i)
+		}
+	}
+	for _, line := range strings.Split(string(data), "\r\n") {
+		if len(line) > 998 {
+			t.Fatalf("line exceeds 998 characters: %q", line)
+		}
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "multipart/alternative" {
+		if msg.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Fatalf("Content-Transfer-Encoding = %q", msg.Header.Get("Content-Transfer-Encoding"))
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg, string(body), ""
+	}
+
+	bodies := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if part.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Fatalf("part Content-Transfer-Encoding = %q", part.Header.Get("Content-Transfer-Encoding"))
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodies[part.Header.Get("Content-Type")] = string(body)
+	}
+	return msg, bodies["text/plain; charset=utf-8"], bodies["text/html; charset=utf-8"]
+}
+
+// 件名のエンコードを解除
+func decodeSubject(t *testing.T, msg *mail.Message) string {
+	t.Helper()
+	raw := msg.Header.Get("Subject")
+	subject, err := new(mime.WordDecoder).DecodeHeader(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return subject
+}
+
+// 本文に含まれるべき文字列をすべて含むか確認
+func assertContains(t *testing.T, name, body string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(body, w) {
+			t.Errorf("%s does not contain %q:\n%s", name, w, body)
+		}
+	}
+}
+
+func TestSendDueSoon(t *testing.T) {
+	s := newFakeSMTPServer(t)
+	msg, err := Render(TemplateDueSoon, "ja", "user@example.com", DueSoonData{
+		Tasks: []TaskItem{
+			{Title: "牛乳を買う", Due: "2026-10-20 09:00"},
+			{Title: "<レポート> & 提出", Due: "2026-10-20 18:00"},
+		},
+		UnsubscribeURL: "https://example.com/unsubscribe?token=abc&type=due_soon",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.Headers = map[string]string{
+		"List-Unsubscribe":      "<https://example.com/unsubscribe?token=abc&type=due_soon>",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	if err := NewSMTPMailer(s.config()).Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	received := s.receive(t)
+	if received.from != "no-reply@example.com" {
+		t.Errorf("MAIL FROM = %q", received.from)
+	}
+	if len(received.to) != 1 || received.to[0] != "user@example.com" {
+		t.Errorf("RCPT TO = %v", received.to)
+	}
+
+	m, text, html := parseMail(t, received.data)
+	headers := map[string]string{
+		"From":                  "no-reply@example.com",
+		"To":                    "user@example.com",
+		"MIME-Version":          "1.0",
+		"List-Unsubscribe":      "<https://example.com/unsubscribe?token=abc&type=due_soon>",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	for k, v := range headers {
+		if got := m.Header.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if _, err := m.Header.Date(); err != nil {
+		t.Errorf("Date: %v", err)
+	}
+	if raw := m.Header.Get("Subject"); !strings.HasPrefix(raw, "=?utf-8?q?") {
+		t.Errorf("Subject is not Q-encoded: %q", raw)
+	}
+	if subject := decodeSubject(t, m); subject != "期限が近づいているタスクが2件あります" {
+		t.Errorf("Subject = %q", subject)
+	}
+
+	assertContains(t, "text", text,
+		"以下のタスクの期限が近づいています。\r\n",
+		"- 牛乳を買う（期限: 2026-10-20 09:00）\r\n",
+		"- <レポート> & 提出（期限: 2026-10-20 18:00）\r\n",
+		"このお知らせの配信停止: https://example.com/unsubscribe?token=abc&type=due_soon",
+	)
+	assertContains(t, "html", html,
+		`<html lang="ja">`,
+		"<li>牛乳を買う（期限: 2026-10-20 09:00）</li>",
+		"<li>&lt;レポート&gt; &amp; 提出（期限: 2026-10-20 18:00）</li>",
+		`<a href="https://example.com/unsubscribe?token=abc&amp;type=due_soon">このお知らせの配信を停止する</a>`,
+	)
+}
+
+func TestSendDigest(t *testing.T) {
+	s := newFakeSMTPServer(t)
+	msg, err := Render(TemplateDigest, "ja", "user@example.com", DigestData{
+		Date:           "2026-10-20",
+		Today:          []TaskItem{{Title: "定例ミーティングの準備", Due: "10:00"}},
+		UnsubscribeURL: "https://example.com/unsubscribe?token=abc&type=daily_digest",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewSMTPMailer(s.config()).Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	m, text, html := parseMail(t, s.receive(t).data)
+	if subject := decodeSubject(t, m); subject != "2026-10-20 のタスク（今日 1件・期限切れ 0件）" {
+		t.Errorf("Subject = %q", subject)
+	}
+	wantText := "2026-10-20 のタスクのまとめです。\r\n" +
+		"\r\n" +
+		"■ 今日が期限のタスク\r\n" +
+		"- 定例ミーティングの準備（10:00）\r\n" +
+		"\r\n" +
+		"■ 期限切れのタスク\r\n" +
+		"なし\r\n" +
+		"\r\n" +
+		"日次ダイジェストの配信停止: https://example.com/unsubscribe?token=abc&type=daily_digest\r\n"
+	if text != wantText {
+		t.Errorf("text =\n%q\nwant\n%q", text, wantText)
+	}
+	assertContains(t, "html", html,
+		"<li>定例ミーティングの準備（10:00）</li>",
+		"<li>なし</li>",
+		`<a href="https://example.com/unsubscribe?token=abc&amp;type=daily_digest">日次ダイジェストの配信を停止する</a>`,
+	)
+}
+
+func TestSendTextOnly(t *testing.T) {
+	s := newFakeSMTPServer(t)
+	// 76文字を超える行は quoted-printable のソフト改行で折り返される
+	long := strings.Repeat("あ", 60)
+	msg := Message{To: "user@example.com", Subject: "お知らせ", Text: "本文です。\n" + long + "\n"}
+	if err := NewSMTPMailer(s.config()).Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	m, text, _ := parseMail(t, s.receive(t).data)
+	if got := m.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if subject := decodeSubject(t, m); subject != "お知らせ" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if want := "本文です。\r\n" + long + "\r\n"; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+func TestRenderFallsBackToDefaultLanguage(t *testing.T) {
+	msg, err := Render(TemplateReminder, "fr", "user@example.com", ReminderData{Title: "牛乳を買う", Due: "2026-10-20 09:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "リマインダー: 牛乳を買う" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+}
@@ -0,0 +1,119 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// メールテンプレートの種類
+const (
+	TemplateSignup   = "signup"   // サインアップ確認
+	TemplateDueSoon  = "due_soon" // 期限間近のリマインダー
+	TemplateDigest   = "digest"   // 日次ダイジェスト
+	TemplateReminder = "reminder" // タスクごとのリマインダー
+	TemplateVerify   = "verify"   // メールアドレスの確認リンクの再送
+	TemplateReset    = "reset"    // パスワードの再設定
+)
+
+// 対応している言語（最初の要素がデフォルト）
+var Languages = []string{"ja", "en"}
+
+// templates/<言語>/<種類>.txt と .html を埋め込む
+// .txt には件名を {{define "subject"}} で定義する
+//
+//go:embed templates
+var templateFS embed.FS
+
+// テンプレートに渡すタスクの情報
+type TaskItem struct {
+	Title string
+	Due   string // 表示用に整形済みの期限
+}
+
+// サインアップ確認メールのデータ
+type SignupData struct {
+	Email     string
+	AppURL    string
+	VerifyURL string // メールアドレスの確認リンク
+}
+
+// メールアドレスの確認メールのデータ
+type VerifyData struct {
+	Email     string
+	VerifyURL string
+}
+
+// パスワードの再設定メールのデータ
+type ResetData struct {
+	Email    string
+	ResetURL string
+}
+
+// 期限間近のリマインダーメールのデータ
+type DueSoonData struct {
+	Tasks          []TaskItem
+	UnsubscribeURL string
+}
+
+// タスクごとのリマインダーメールのデータ
+type ReminderData struct {
+	Title string
+	Due   string
+}
+
+// 日次ダイジェストメールのデータ
+type DigestData struct {
+	Date           string
+	Today          []TaskItem
+	Overdue        []TaskItem
+	UnsubscribeURL string
+}
+
+// テンプレートからメールの件名と本文を生成する
+// 未対応の言語が指定された場合はデフォルトの言語を使用
+func Render(name string, lang string, to string, data interface{}) (Message, error) {
+	if !isSupported(lang) {
+		lang = Languages[0]
+	}
+	base := "templates/" + lang + "/" + name
+
+	tt, err := texttemplate.ParseFS(templateFS, base+".txt")
+	if err != nil {
+		return Message{}, err
+	}
+	var subject, text, html bytes.Buffer
+	if err := tt.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := tt.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+
+	ht, err := htmltemplate.ParseFS(templateFS, base+".html")
+	if err != nil {
+		return Message{}, err
+	}
+	if err := ht.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// 対応している言語かどうか
+func isSupported(lang string) bool {
+	for _, l := range Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
+	"log"
+	"time"
+
 	"github.com/DaigoSugiyama0317/Echo-REST-API/controller"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/db"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/mailer"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/middleware"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/migrate"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/push"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/router"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/scheduler"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+	"github.com/go-webauthn/webauthn/webauthn"
 )
 
 func main() {
@@ -17,24 +27,120 @@ func main() {
 	//一次的にmain.goでマイグレーションを実施
 	migrate.Migrate(db)
 
-	// バリデーションのインスタンス生成
-	userValidator := validator.NewUserValidator()
-	taskValidator := validator.NewTaskValidator()
+	// バリデーションのインスタンス生成（文字数の上限などは環境変数で設定）
+	validatorConfig := validator.LoadConfig()
+	userValidator := validator.NewUserValidator(validatorConfig)
+	taskValidator := validator.NewTaskValidator(validatorConfig)
+	savedFilterValidator := validator.NewSavedFilterValidator()
+	taskTemplateValidator := validator.NewTaskTemplateValidator()
+	commentValidator := validator.NewCommentValidator(validatorConfig)
+	emailPreferenceValidator := validator.NewEmailPreferenceValidator()
+	reminderValidator := validator.NewReminderValidator()
+	checklistValidator := validator.NewChecklistValidator(validatorConfig)
+	projectValidator := validator.NewProjectValidator()
+	personalAccessTokenValidator := validator.NewPersonalAccessTokenValidator()
+	passkeyValidator := validator.NewPasskeyValidator()
 
 	// リポジトリ層の初期化
 	userRepository := repository.NewUserRepository(db)
 	taskRepository := repository.NewTaskRepository(db)
+	savedFilterRepository := repository.NewSavedFilterRepository(db)
+	statsRepository := repository.NewStatsRepository(db)
+	taskTemplateRepository := repository.NewTaskTemplateRepository(db)
+	notificationRepository := repository.NewNotificationRepository(db)
+	commentRepository := repository.NewCommentRepository(db)
+	emailPreferenceRepository := repository.NewEmailPreferenceRepository(db)
+	reminderRepository := repository.NewReminderRepository(db)
+	idempotencyRepository := repository.NewIdempotencyRepository(db)
+	syncRepository := repository.NewSyncRepository(db)
+	checklistRepository := repository.NewChecklistRepository(db)
+	projectRepository := repository.NewProjectRepository(db)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+	revokedTokenRepository := repository.NewRevokedTokenRepository(db)
+	sessionRepository := repository.NewSessionRepository(db)
+	personalAccessTokenRepository := repository.NewPersonalAccessTokenRepository(db)
+	passwordResetRepository := repository.NewPasswordResetRepository(db)
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(db)
+	passkeyRepository := repository.NewPasskeyRepository(db)
+
+	// 接続中のユーザーへ通知を配信するハブ
+	notificationHub := push.NewHub()
+	// SMTPでメールを送信するメーラー（SMTP_HOST などの環境変数で設定）
+	smtpMailer := mailer.NewSMTPMailer(mailer.LoadSMTPConfig())
+	// メールアドレスを確認するまでログインやタスクの作成を制限するかどうか（EMAIL_VERIFICATION_POLICY で設定）
+	emailVerificationPolicy := usecase.LoadEmailVerificationPolicy()
+	// パスキー（WebAuthn）の Relying Party の設定（WEBAUTHN_RP_ID などの環境変数、既定は FE_URL から設定）
+	webAuthn, err := webauthn.New(usecase.LoadWebAuthnConfig())
+	if err != nil {
+		log.Fatalln(err)
+	}
 
 	// ユースケース（ビジネスロジック）層
-	userUsecase := usecase.NewUserUsecase(userRepository, userValidator)
-	taskUsecase := usecase.NewTaskUsecase(taskRepository, taskValidator)
+	emailUsecase := usecase.NewEmailUsecase(emailPreferenceRepository, emailPreferenceValidator, taskRepository, userRepository, smtpMailer)
+	userUsecase := usecase.NewUserUsecase(userRepository, userValidator, emailUsecase, refreshTokenRepository, revokedTokenRepository,
+		sessionRepository, passwordResetRepository, recoveryCodeRepository, passkeyRepository, emailVerificationPolicy)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(userRepository, userValidator, recoveryCodeRepository)
+	passkeyUsecase := usecase.NewPasskeyUsecase(userRepository, passkeyValidator, passkeyRepository, sessionRepository, refreshTokenRepository,
+		emailVerificationPolicy, webAuthn)
+	sessionUsecase := usecase.NewSessionUsecase(sessionRepository, refreshTokenRepository)
+	personalAccessTokenUsecase := usecase.NewPersonalAccessTokenUsecase(personalAccessTokenRepository, personalAccessTokenValidator)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepository, userRepository, notificationHub)
+	taskUsecase := usecase.NewTaskUsecase(taskRepository, taskValidator, userRepository, projectRepository, notificationUsecase)
+	savedFilterUsecase := usecase.NewSavedFilterUsecase(savedFilterRepository, savedFilterValidator, taskUsecase)
+	statsUsecase := usecase.NewStatsUsecase(statsRepository, userRepository)
+	taskTemplateUsecase := usecase.NewTaskTemplateUsecase(taskTemplateRepository, taskTemplateValidator, taskRepository, taskValidator)
+	commentUsecase := usecase.NewCommentUsecase(commentRepository, commentValidator, taskRepository, notificationUsecase)
+	checklistUsecase := usecase.NewChecklistUsecase(checklistRepository, checklistValidator, taskValidator)
+	projectUsecase := usecase.NewProjectUsecase(projectRepository, projectValidator)
+	// リマインダーの通知方法ごとの送信処理
+	reminderChannels := map[string]usecase.IReminderChannel{
+		model.ReminderChannelEmail:   usecase.NewEmailReminderChannel(emailPreferenceRepository, userRepository, smtpMailer),
+		model.ReminderChannelWebhook: usecase.NewWebhookReminderChannel(userRepository),
+		model.ReminderChannelInApp:   usecase.NewInAppReminderChannel(notificationUsecase),
+	}
+	syncUsecase := usecase.NewSyncUsecase(syncRepository, taskUsecase)
+	reminderUsecase := usecase.NewReminderUsecase(reminderRepository, reminderValidator, taskRepository, userRepository, reminderChannels)
 
 	// コントローラー層の初期化
 	userController := controller.NewUserController(userUsecase)
 	taskController := controller.NewTaskController(taskUsecase)
+	savedFilterController := controller.NewSavedFilterController(savedFilterUsecase)
+	statsController := controller.NewStatsController(statsUsecase)
+	taskTemplateController := controller.NewTaskTemplateController(taskTemplateUsecase)
+	notificationController := controller.NewNotificationController(notificationUsecase)
+	commentController := controller.NewCommentController(commentUsecase)
+	checklistController := controller.NewChecklistController(checklistUsecase)
+	projectController := controller.NewProjectController(projectUsecase)
+	emailPreferenceController := controller.NewEmailPreferenceController(emailUsecase)
+	reminderController := controller.NewReminderController(reminderUsecase)
+	syncController := controller.NewSyncController(syncUsecase)
+	todayController := controller.NewTodayController(taskUsecase)
+	sessionController := controller.NewSessionController(sessionUsecase)
+	personalAccessTokenController := controller.NewPersonalAccessTokenController(personalAccessTokenUsecase)
+	twoFactorController := controller.NewTwoFactorController(twoFactorUsecase)
+	passkeyController := controller.NewPasskeyController(passkeyUsecase)
 
 	// ルーターを構築して、エンドポイントを登録
-	e := router.NewRouter(userController, taskController)
+	e := router.NewRouter(userController, taskController, savedFilterController, statsController, taskTemplateController,
+		notificationController, commentController, checklistController, emailPreferenceController, reminderController,
+		syncController, todayController, projectController, sessionController, personalAccessTokenController, twoFactorController,
+		passkeyController, middleware.Idempotency(idempotencyRepository), middleware.TokenRevocation(revokedTokenRepository, sessionRepository),
+		middleware.PersonalAccessTokenAuth(personalAccessTokenUsecase), middleware.RequireVerifiedEmail(userRepository, emailVerificationPolicy))
+
+	// バックグラウンドで定期実行するジョブ
+	// 複数のインスタンスで起動しても、各ジョブは同じ通知を二重に送信しない
+	jobScheduler := scheduler.NewScheduler(scheduler.NewSystemClock())
+	jobScheduler.Register("reminders", 30*time.Second, reminderUsecase.SendDueReminders)                                // タスクごとのリマインダー
+	jobScheduler.Register("due-soon-emails", time.Minute, emailUsecase.SendDueSoonReminders)                            // 期限間近のメール
+	jobScheduler.Register("daily-digests", time.Minute, emailUsecase.SendDailyDigests)                                  // 日次ダイジェスト
+	jobScheduler.Register("my-day-entries", time.Hour, taskRepository.DeleteExpiredMyDayEntries)                        // 過去の日付の My Day の選択の削除
+	jobScheduler.Register("idempotency-keys", time.Hour, idempotencyRepository.DeleteExpiredIdempotencyKeys)            // 期限切れの Idempotency-Key の削除
+	jobScheduler.Register("refresh-tokens", time.Hour, refreshTokenRepository.DeleteExpiredRefreshTokens)               // 期限切れのリフレッシュトークンの削除
+	jobScheduler.Register("revoked-tokens", time.Hour, revokedTokenRepository.DeleteExpiredRevokedTokens)               // 期限切れの失効済みアクセストークンの記録の削除
+	jobScheduler.Register("sessions", time.Hour, sessionRepository.DeleteExpiredSessions)                               // 期限切れのセッションの削除
+	jobScheduler.Register("password-reset-tokens", time.Hour, passwordResetRepository.DeleteExpiredPasswordResetTokens) // 期限切れのパスワード再設定トークンの削除
+	jobScheduler.Register("webauthn-sessions", time.Hour, passkeyRepository.DeleteExpiredWebAuthnSessions)              // 完了しなかったパスキーの登録・認証の状態の削除
+	jobScheduler.Start(context.Background())
 
 	// サーバー起動（:8080で待ち受け）
 	e.Logger.Fatal(e.Start(":8080"))
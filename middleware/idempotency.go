@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed" // 保存済みのレスポンスを返した場合に付与するヘッダー
+	idempotencyKeyTTL        = 24 * time.Hour        // キーの有効期限
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency-Key ヘッダーが付いたPOSTリクエストの最初のレスポンスを保存し、同じキーでの再送には保存したレスポンスを返す
+// キーはユーザー（JWT認証の後に適用した場合）、キー、ルートの組み合わせごとに管理する
+// - 同じキーで異なるボディが送信された場合は 422
+// - 最初のリクエストがまだ処理中の場合は 409
+// - 5xx のレスポンスは保存しないため、同じキーで再試行できる
+func Idempotency(ir repository.IIdempotencyRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			k := c.Request().Header.Get(HeaderIdempotencyKey)
+			if c.Request().Method != http.MethodPost || k == "" {
+				return next(c)
+			}
+			if len(k) > maxIdempotencyKeyLength {
+				return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key is too long")
+			}
+
+			// ボディのハッシュを計算し、ハンドラーで再度読めるように戻す
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+			sum := sha256.Sum256(body)
+
+			now := time.Now()
+			key := model.IdempotencyKey{
+				UserId:      requestUserId(c),
+				Key:         k,
+				Route:       c.Request().Method + " " + c.Request().URL.Path,
+				RequestHash: hex.EncodeToString(sum[:]),
+				ExpiresAt:   now.Add(idempotencyKeyTTL),
+			}
+			stored := model.IdempotencyKey{}
+			created, err := claimIdempotencyKey(ir, &key, &stored, now)
+			if err != nil {
+				return err
+			}
+			if !created {
+				switch {
+				case stored.RequestHash != key.RequestHash:
+					return echo.NewHTTPError(http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request body")
+				case stored.ResponseStatus == 0:
+					return echo.NewHTTPError(http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+				}
+				// 保存済みのレスポンスをそのまま返す
+				c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				return c.Blob(stored.ResponseStatus, stored.ContentType, stored.ResponseBody)
+			}
+
+			// ハンドラーを実行し、書き込まれたレスポンスを記録する
+			rec := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+			// ハンドラーが返したエラーもここでエラーハンドラーに渡し、書き込まれたエラーレスポンスを記録する
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			// 5xx の場合は保存せず、再試行できるようにする
+			if c.Response().Status >= http.StatusInternalServerError {
+				if err := ir.DeleteIdempotencyKey(key.ID); err != nil {
+					log.Printf("idempotency: failed to delete key %d: %v", key.ID, err)
+				}
+				return nil
+			}
+			if err := ir.SaveResponse(key.ID, c.Response().Status, c.Response().Header().Get(echo.HeaderContentType), rec.body.Bytes()); err != nil {
+				log.Printf("idempotency: failed to save response for key %d: %v", key.ID, err)
+			}
+			return nil
+		}
+	}
+}
+
+// キーを処理中として保存する
+// すでに存在する場合は stored に保存済みの内容を読み込んで false を返す（期限切れのものは削除して作り直す）
+func claimIdempotencyKey(ir repository.IIdempotencyRepository, key *model.IdempotencyKey, stored *model.IdempotencyKey, now time.Time) (bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		created := false
+		if err := ir.CreateIdempotencyKey(&created, key); err != nil {
+			return false, err
+		}
+		if created {
+			return true, nil
+		}
+		if err := ir.GetIdempotencyKey(stored, key.UserId, key.Key, key.Route); err != nil {
+			return false, err
+		}
+		if stored.ExpiresAt.After(now) {
+			return false, nil
+		}
+		if err := ir.DeleteIdempotencyKey(stored.ID); err != nil {
+			return false, err
+		}
+	}
+	return false, fmt.Errorf("failed to store idempotency key")
+}
+
+// JWT認証済みであればユーザーIDを、未ログインであれば0を返す
+func requestUserId(c echo.Context) uint {
+	user, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return 0
+	}
+	claims, ok := user.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0
+	}
+	userId, _ := claims["user_id"].(float64)
+	return uint(userId)
+}
+
+// レスポンスを書き込みつつ、ボディを記録する
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
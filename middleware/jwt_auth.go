@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"strings"
+
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+const bearerPrefix = "Bearer "
+
+// Authorization: Bearer ヘッダーのトークンを取り出す
+// ヘッダーがない、または Bearer 形式でない場合は ok が false になる
+func BearerToken(c echo.Context) (token string, ok bool) {
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(auth) <= len(bearerPrefix) || !strings.EqualFold(auth[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+	return auth[len(bearerPrefix):], true
+}
+
+// JWT認証ミドルウェア
+// Authorization: Bearer ヘッダーがあるリクエストはヘッダーのトークンのみで、それ以外は Cookie のトークンで認証する
+// Bearer のリクエストでは Cookie を使わないため、CSRFの確認を省略しても Cookie を悪用したリクエストは通らない
+// （ブラウザはクロスサイトのリクエストに Authorization ヘッダーを自動では付けない）
+func JWTAuth(signingKey []byte) echo.MiddlewareFunc {
+	bearerAuth := echojwt.WithConfig(echojwt.Config{
+		SigningKey:  signingKey,
+		TokenLookup: "header:" + echo.HeaderAuthorization + ":" + bearerPrefix, // CLIやモバイルアプリ
+	})
+	cookieAuth := echojwt.WithConfig(echojwt.Config{
+		SigningKey:  signingKey,
+		TokenLookup: "cookie:token", // ブラウザ
+	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withBearer, withCookie := bearerAuth(next), cookieAuth(next)
+		return func(c echo.Context) error {
+			if _, ok := BearerToken(c); ok {
+				return withBearer(c)
+			}
+			return withCookie(c)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// 認証したパーソナルアクセストークンのスコープを保存するコンテキストのキー
+const contextKeyTokenScopes = "token_scopes"
+
+// Authorization: Bearer ヘッダーのトークンがパーソナルアクセストークンかどうか
+func IsPersonalAccessToken(c echo.Context) bool {
+	token, ok := BearerToken(c)
+	return ok && strings.HasPrefix(token, model.PersonalAccessTokenPrefix)
+}
+
+// パーソナルアクセストークンで認証するミドルウェア
+// コントローラーが JWT と同じ方法でユーザーIDを取得できるよう、"user" に user_id のクレームを持つトークンをセットする
+// スコープの確認は RequireScope で行う
+func PersonalAccessTokenAuth(pu usecase.IPersonalAccessTokenUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			secret, ok := BearerToken(c)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed token")
+			}
+			token, err := pu.Authenticate(secret)
+			if err != nil {
+				return err
+			}
+			c.Set("user", &jwt.Token{
+				Claims: jwt.MapClaims{"user_id": float64(token.UserId)},
+				Valid:  true,
+			})
+			c.Set(contextKeyTokenScopes, []string(token.Scopes))
+			return next(c)
+		}
+	}
+}
+
+// パーソナルアクセストークンにスコープがあることを確認するミドルウェア
+// GET（と HEAD）のリクエストには readScope、それ以外には writeScope が必要で、ない場合は 403
+func RequireScope(readScope string, writeScope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			required := writeScope
+			if m := c.Request().Method; m == http.MethodGet || m == http.MethodHead {
+				required = readScope
+			}
+			scopes, _ := c.Get(contextKeyTokenScopes).([]string)
+			for _, scope := range scopes {
+				if scope == required {
+					return next(c)
+				}
+			}
+			return echo.NewHTTPError(http.StatusForbidden, "token does not have the required scope: "+required)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// テスト用のパーソナルアクセストークンのユースケース（平文のトークンからトークンを引く）
+type fakePersonalAccessTokenUsecase struct {
+	usecase.IPersonalAccessTokenUsecase
+	tokens map[string]model.PersonalAccessToken
+}
+
+func (pu *fakePersonalAccessTokenUsecase) Authenticate(secret string) (model.PersonalAccessToken, error) {
+	token, ok := pu.tokens[secret]
+	if !ok {
+		return model.PersonalAccessToken{}, usecase.ErrInvalidPersonalAccessToken
+	}
+	return token, nil
+}
+
+func TestRequireScope(t *testing.T) {
+	pu := &fakePersonalAccessTokenUsecase{tokens: map[string]model.PersonalAccessToken{
+		"pat_readonly":  {UserId: 1, Scopes: model.Labels{model.ScopeTasksRead}},
+		"pat_readwrite": {UserId: 2, Scopes: model.Labels{model.ScopeTasksRead, model.ScopeTasksWrite}},
+		"pat_projects":  {UserId: 3, Scopes: model.Labels{model.ScopeProjectsRead, model.ScopeProjectsWrite}},
+	}}
+
+	// router と同じく、パーソナルアクセストークンで認証した後にスコープを確認する
+	e := echo.New()
+	// 共通のエラーハンドラー（controller.HTTPErrorHandler）と同じく、無効なトークンは 401 とする
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		if errors.Is(err, usecase.ErrInvalidPersonalAccessToken) {
+			err = echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+		e.DefaultHTTPErrorHandler(err, c)
+	}
+	tasks := e.Group("/tasks", PersonalAccessTokenAuth(pu), RequireScope(model.ScopeTasksRead, model.ScopeTasksWrite))
+	handler := func(status int) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := c.Get("user").(*jwt.Token).Claims.(jwt.MapClaims)
+			return c.JSON(status, map[string]any{"user_id": claims["user_id"]})
+		}
+	}
+	tasks.GET("", handler(http.StatusOK))
+	tasks.POST("", handler(http.StatusCreated))
+	tasks.PUT("/:taskId", handler(http.StatusOK))
+	tasks.DELETE("/:taskId", handler(http.StatusNoContent))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"読み取り専用のトークンで取得", http.MethodGet, "/tasks", "pat_readonly", http.StatusOK},
+		{"読み取り専用のトークンで作成", http.MethodPost, "/tasks", "pat_readonly", http.StatusForbidden},
+		{"読み取り専用のトークンで更新", http.MethodPut, "/tasks/1", "pat_readonly", http.StatusForbidden},
+		{"読み取り専用のトークンで削除", http.MethodDelete, "/tasks/1", "pat_readonly", http.StatusForbidden},
+		{"書き込みできるトークンで作成", http.MethodPost, "/tasks", "pat_readwrite", http.StatusCreated},
+		{"ほかのスコープのトークンで取得", http.MethodGet, "/tasks", "pat_projects", http.StatusForbidden},
+		{"存在しないトークン", http.MethodPost, "/tasks", "pat_unknown", http.StatusUnauthorized},
+		{"トークンなし", http.MethodPost, "/tasks", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"title":"task"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.path, rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == http.StatusForbidden && !strings.Contains(rec.Body.String(), "required scope") {
+				t.Errorf("body = %s", rec.Body.String())
+			}
+		})
+	}
+}
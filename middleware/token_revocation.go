@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// JWT認証の後に適用し、失効したアクセストークンを拒否する
+// - ログアウトで失効させた jti のトークン
+// - 失効させたセッションで発行したトークン
+// - すべての端末からのログアウトやパスワードの変更より前に発行されたトークン
+// jti や sid、iat を含まない（失効を確認できない）トークンも拒否する
+// 有効なトークンの場合は、セッションの最終アクセス日時とIPアドレスを更新する
+func TokenRevocation(rr repository.IRevokedTokenRepository, sr repository.ISessionRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed jwt")
+			}
+			claims, ok := user.Claims.(jwt.MapClaims)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed jwt")
+			}
+			jti, _ := claims["jti"].(string)
+			sessionId, _ := claims["sid"].(float64)
+			issuedAt, _ := claims["iat"].(float64)
+			userId, _ := claims["user_id"].(float64)
+			if jti == "" || sessionId == 0 || issuedAt == 0 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "token has been revoked")
+			}
+
+			revoked := false
+			if err := rr.IsTokenRevoked(&revoked, jti, uint(sessionId), uint(userId), time.Unix(int64(issuedAt), 0)); err != nil {
+				return err
+			}
+			if revoked {
+				return echo.NewHTTPError(http.StatusUnauthorized, "token has been revoked")
+			}
+			// 最終アクセス日時の更新に失敗してもリクエストは処理する
+			if err := sr.TouchSession(uint(sessionId), c.RealIP(), time.Now()); err != nil {
+				log.Printf("failed to update session %d: %v", uint(sessionId), err)
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// 認証の後に適用し、メールアドレスを確認していないユーザーのリクエストを 403 で拒否する
+// 方針がタスクの作成を制限しない場合は何もしない
+func RequireVerifiedEmail(ur repository.IUserRepository, policy usecase.EmailVerificationPolicy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !policy.BlocksTaskCreation() {
+			return next
+		}
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed jwt")
+			}
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed jwt")
+			}
+			userId, _ := claims["user_id"].(float64)
+
+			user := model.User{}
+			if err := ur.GetUserById(&user, uint(userId)); err != nil {
+				return err
+			}
+			if user.EmailVerifiedAt == nil {
+				return usecase.ErrEmailNotVerified
+			}
+			return next(c)
+		}
+	}
+}
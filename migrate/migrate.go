@@ -12,10 +12,19 @@ func Migrate(dbConn *gorm.DB) {
 	// //データベースと接続
 	// dbConn := db.NewDB()
 	// defer fmt.Println("successfully Migrated")
-	
+
 	// //接続の終了
 	// defer db.CloseDB(dbConn)
-	
+
+	// 差分同期で使用する変更シーケンス（各テーブルの change_seq の既定値として使うため先に作成）
+	dbConn.Exec("CREATE SEQUENCE IF NOT EXISTS " + model.ChangeSeqName)
+
 	//マイグレーションを実行
-	dbConn.AutoMigrate(&model.User{}, &model.Task{})
-}
\ No newline at end of file
+	dbConn.AutoMigrate(&model.User{}, &model.Project{}, &model.CustomField{}, &model.Task{}, &model.SavedFilter{}, &model.TaskTemplate{},
+		&model.Notification{}, &model.Comment{}, &model.ChecklistItem{}, &model.EmailPreference{}, &model.Reminder{}, &model.IdempotencyKey{},
+		&model.Tombstone{}, &model.MyDayEntry{}, &model.RefreshToken{}, &model.RevokedToken{}, &model.Session{}, &model.PersonalAccessToken{},
+		&model.PasswordResetToken{},
+		&model.RecoveryCode{},
+		&model.Passkey{},
+		&model.WebAuthnSession{})
+}
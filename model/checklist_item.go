@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// タスク内のチェックリスト項目（タスクとしては扱わない軽量な項目）
+type ChecklistItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Text      string    `json:"text" gorm:"not null"`
+	Checked   bool      `json:"checked" gorm:"not null;default:false"`
+	Position  int       `json:"position" gorm:"not null;default:0"` // 表示順（0始まり）
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Task      Task      `json:"-" gorm:"foreignKey:TaskId; constraint:OnDelete:CASCADE"`
+	TaskId    uint      `json:"task_id" gorm:"not null;index"`
+}
+
+type ChecklistItemResponse struct {
+	ID        uint      `json:"id"`
+	Text      string    `json:"text"`
+	Checked   bool      `json:"checked"`
+	Position  int       `json:"position"`
+	TaskId    uint      `json:"task_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// チェックリストの進捗（TaskResponse に含める）
+type ChecklistProgress struct {
+	Checked int `json:"checked"`
+	Total   int `json:"total"`
+}
+
+// チェックリストを並べ替える際のリクエスト
+type ChecklistReorderRequest struct {
+	ItemIds []uint `json:"item_ids"` // タスクのすべての項目のIDを新しい順序で指定
+}
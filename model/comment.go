@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// タスクへのコメント
+type Comment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Body      string    `json:"body" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Task      Task      `json:"-" gorm:"foreignKey:TaskId; constraint:OnDelete:CASCADE"`
+	TaskId    uint      `json:"task_id" gorm:"not null;index"`
+	User      User      `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint      `json:"user_id" gorm:"not null"`
+	ChangeSeq int64     `json:"-" gorm:"not null;default:nextval('change_seq');index"` // 差分同期に使用
+}
+
+type CommentResponse struct {
+	ID        uint      `json:"id"`
+	Body      string    `json:"body"`
+	TaskId    uint      `json:"task_id"`
+	UserId    uint      `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
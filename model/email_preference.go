@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// 配信停止できるメールの種類
+const (
+	EmailTypeDueSoon     = "due_soon"     // 期限間近のリマインダー
+	EmailTypeDailyDigest = "daily_digest" // 日次ダイジェスト
+)
+
+// ユーザーごとのメール配信設定
+type EmailPreference struct {
+	UserId           uint       `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	User             User       `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	Language         string     `json:"language" gorm:"not null;default:ja"` // メールの言語（ja / en）
+	DueSoon          bool       `json:"due_soon" gorm:"not null;default:true"`
+	DailyDigest      bool       `json:"daily_digest" gorm:"not null;default:true"`
+	DigestHour       int        `json:"digest_hour" gorm:"not null;default:7"` // ダイジェストを送信する時刻（ユーザーのタイムゾーンでの時）
+	UnsubscribeToken string     `json:"-" gorm:"not null;uniqueIndex"`         // ワンクリック配信停止リンク用のトークン
+	LastDigestOn     *time.Time `json:"-" gorm:"type:date"`                    // 最後にダイジェストを送信した日付（重複送信の防止）
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type EmailPreferenceResponse struct {
+	Language    string    `json:"language"`
+	DueSoon     bool      `json:"due_soon"`
+	DailyDigest bool      `json:"daily_digest"`
+	DigestHour  int       `json:"digest_hour"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Idempotency-Key ヘッダーで送信されたリクエストと、その最初のレスポンス
+// (UserId, Key, Route) の組み合わせごとに1件保存し、同じキーでの再送にはこのレスポンスを返す
+type IdempotencyKey struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	UserId         uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_scope"` // 未ログインのリクエスト（サインアップなど）は0
+	Key            string    `json:"key" gorm:"not null;uniqueIndex:idx_idempotency_scope"`
+	Route          string    `json:"route" gorm:"not null;uniqueIndex:idx_idempotency_scope"` // メソッドとパス（例: POST /tasks）
+	RequestHash    string    `json:"request_hash" gorm:"not null"`                            // リクエストボディのSHA-256
+	ResponseStatus int       `json:"response_status" gorm:"not null;default:0"`               // 0の場合は処理中
+	ContentType    string    `json:"content_type" gorm:"not null;default:''"`
+	ResponseBody   []byte    `json:"-"`
+	ExpiresAt      time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt      time.Time `json:"created_at"`
+}
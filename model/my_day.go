@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// 「My Day」に追加したタスク（ユーザーのタイムゾーンでの日付ごと）
+// 日付が変わると新しい日の選択は空になる
+type MyDayEntry struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Date      time.Time `json:"date" gorm:"type:date;not null;uniqueIndex:idx_my_day_entry"`
+	Task      Task      `json:"-" gorm:"foreignKey:TaskId; constraint:OnDelete:CASCADE"`
+	TaskId    uint      `json:"task_id" gorm:"not null;uniqueIndex:idx_my_day_entry"`
+	User      User      `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_my_day_entry"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// タスクをスヌーズする際のリクエスト
+type TaskSnoozeRequest struct {
+	Until time.Time `json:"until"` // この日時まで一覧に表示しない
+}
+
+// GET /today のレスポンス
+type TodayResponse struct {
+	Date     string         `json:"date"`      // ユーザーのタイムゾーンでの今日の日付
+	Selected []TaskResponse `json:"selected"`  // 今日取り組むタスクとして選んだもの
+	DueToday []TaskResponse `json:"due_today"` // 今日が期限のタスク（選択済みのものは除く）
+	Overdue  []TaskResponse `json:"overdue"`   // 期限切れのタスク（選択済みのものは除く）
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// 通知の種類
+const (
+	NotificationAssigned    = "assigned"     // タスクが割り当てられた
+	NotificationMentioned   = "mentioned"    // タスクまたはコメントで @メールアドレス によりメンションされた
+	NotificationTaskUpdated = "task_updated" // 関わっているタスクが変更された
+	NotificationCommented   = "commented"    // 関わっているタスクにコメントが追加された
+	NotificationReminder    = "reminder"     // 設定したリマインダーの時刻になった
+)
+
+// ユーザーごとの通知（受信箱）
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Type      string     `json:"type" gorm:"not null"`
+	Message   string     `json:"message" gorm:"not null"`
+	Task      *Task      `json:"-" gorm:"foreignKey:TaskId; constraint:OnDelete:CASCADE"`
+	TaskId    *uint      `json:"task_id"`
+	Actor     *User      `json:"-" gorm:"foreignKey:ActorId; constraint:OnDelete:SET NULL"`
+	ActorId   *uint      `json:"actor_id"` // 通知のきっかけとなったユーザー
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint       `json:"user_id" gorm:"not null;index"` // 通知を受け取るユーザー
+}
+
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Message   string     `json:"message"`
+	TaskId    *uint      `json:"task_id"`
+	ActorId   *uint      `json:"actor_id"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// ユーザーが登録したパスキー（WebAuthn の公開鍵クレデンシャル）
+// パスワードなしでのログインと、2段階認証の確認コードの代わりに使用する
+type Passkey struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Name            string     `json:"name" gorm:"not null"`                 // ユーザーが付けた名前（「MacBook の Touch ID」など）
+	CredentialId    []byte     `json:"-" gorm:"not null;uniqueIndex"`        // 認証器が発行したクレデンシャルID
+	PublicKey       []byte     `json:"-" gorm:"not null"`                    // COSE 形式の公開鍵
+	AttestationType string     `json:"-" gorm:"not null;default:''"`         // 登録時のアテステーションの形式
+	Transports      Labels     `json:"-" gorm:"type:jsonb;default:'[]'"`     // 認証器との通信方法（usb, internal, hybrid など）
+	AAGUID          []byte     `json:"-"`                                    // 認証器の機種を表すID
+	SignCount       uint32     `json:"-" gorm:"not null;default:0"`          // 署名カウンター（複製された認証器の検出に使用）
+	BackupEligible  bool       `json:"-" gorm:"not null;default:false"`      // 複数の端末に同期できるパスキーかどうか
+	BackupState     bool       `json:"synced" gorm:"not null;default:false"` // 同期されているかどうか
+	LastUsedAt      *time.Time `json:"last_used_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	User            User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId          uint       `json:"user_id" gorm:"not null;index"`
+}
+
+// レスポンス用のパスキーの情報
+type PasskeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Synced     bool       `json:"synced"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// パスキーの登録を開始するリクエスト
+type PasskeyRegistrationRequest struct {
+	Name string `json:"name"`
+}
+
+// 2段階認証の確認コードの代わりにパスキーを使うリクエスト（POST /login、/auth/token が返した mfa_token）
+type MfaPasskeyRequest struct {
+	MfaToken string `json:"mfa_token"`
+}
+
+// WebAuthn の登録・認証の途中の状態（開始時に発行したチャレンジごとに保存し、完了時に一度だけ使う）
+type WebAuthnSession struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Challenge string    `json:"-" gorm:"not null;uniqueIndex"`
+	Purpose   string    `json:"purpose" gorm:"not null"`           // 登録、パスワードなしのログイン、2段階認証のいずれか
+	UserId    uint      `json:"user_id" gorm:"not null;default:0"` // パスワードなしのログインでは0（完了時にパスキーからユーザーを特定する）
+	Name      string    `json:"name" gorm:"not null;default:''"`   // 登録するパスキーの名前
+	Data      string    `json:"-" gorm:"type:text;not null"`       // ライブラリのセッションデータ（JSON）
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
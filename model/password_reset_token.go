@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// パスワードの再設定に使用する、メールで送信するトークン
+// トークン自体は保存せず、SHA-256 のハッシュのみを保存する（一度使うと無効になる）
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"` // パスワードを再設定した日時
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint       `json:"user_id" gorm:"not null;index"`
+}
+
+// パスワードの再設定メールの送信のリクエスト
+type PasswordForgotRequest struct {
+	Email string `json:"email"`
+}
+
+// パスワードの再設定のリクエスト
+type PasswordResetRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
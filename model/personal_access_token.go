@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// パーソナルアクセストークンの接頭辞（JWT と区別するために付ける）
+const PersonalAccessTokenPrefix = "pat_"
+
+// パーソナルアクセストークンのスコープ（GET には :read、それ以外のメソッドには :write が必要）
+const (
+	ScopeTasksRead          = "tasks:read"          // タスク、保存済みフィルター、テンプレート、今日の計画、差分同期の取得
+	ScopeTasksWrite         = "tasks:write"         // 上記の作成・更新・削除
+	ScopeProjectsRead       = "projects:read"       // プロジェクトとカスタムフィールドの取得
+	ScopeProjectsWrite      = "projects:write"      // プロジェクトとカスタムフィールドの作成・更新・削除
+	ScopeStatsRead          = "stats:read"          // 統計情報の取得
+	ScopeNotificationsRead  = "notifications:read"  // 通知とメール配信設定の取得
+	ScopeNotificationsWrite = "notifications:write" // 通知の既読、メール配信設定の更新
+)
+
+// パーソナルアクセストークンに指定できるスコープ
+var PersonalAccessTokenScopes = []string{
+	ScopeTasksRead, ScopeTasksWrite, ScopeProjectsRead, ScopeProjectsWrite,
+	ScopeStatsRead, ScopeNotificationsRead, ScopeNotificationsWrite,
+}
+
+// ユーザーが作成する、スクリプトや外部サービス向けのアクセストークン
+// トークンはハッシュのみを保存し、平文は作成時のレスポンスでのみ返す
+type PersonalAccessToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"not null"`
+	TokenHash  string     `json:"-" gorm:"not null;uniqueIndex"`                  // トークンのハッシュ（SHA-256）
+	Scopes     Labels     `json:"scopes" gorm:"type:jsonb;not null;default:'[]'"` // 許可するスコープ
+	ExpiresAt  *time.Time `json:"expires_at"`                                     // 有効期限（nil の場合は無期限）
+	LastUsedAt *time.Time `json:"last_used_at"`                                   // 最後に使われた日時
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	User       User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId     uint       `json:"user_id" gorm:"not null;index"`
+}
+
+type PersonalAccessTokenResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     Labels     `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// トークンの作成時のレスポンス（トークンの平文はこのときだけ返す）
+type PersonalAccessTokenCreatedResponse struct {
+	PersonalAccessTokenResponse
+	Token string `json:"token"`
+}
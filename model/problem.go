@@ -0,0 +1,16 @@
+package model
+
+// エラーレスポンスの Content-Type
+const ProblemContentType = "application/problem+json"
+
+// RFC 7807（Problem Details for HTTP APIs）形式のエラーレスポンス
+type Problem struct {
+	Type      string               `json:"type"`             // エラーの種類を表すURI（個別の種類がない場合は about:blank）
+	Title     string               `json:"title"`            // エラーの種類の概要
+	Status    int                  `json:"status"`           // HTTPのステータスコード
+	Detail    string               `json:"detail,omitempty"` // 今回のエラーの詳細
+	Instance  string               `json:"instance"`         // エラーが発生したリクエストのパス
+	Code      string               `json:"code"`             // エラーコード（クライアントはこの値で判定する）
+	RequestId string               `json:"request_id"`       // リクエストID（X-Request-Id ヘッダーと同じ値）
+	Errors    []FieldErrorResponse `json:"errors,omitempty"` // 入力値の検証エラーの場合はフィールドごとのエラー
+}
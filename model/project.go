@@ -0,0 +1,94 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// カスタムフィールドの種類
+const (
+	CustomFieldText         = "text"          // 文字列
+	CustomFieldNumber       = "number"        // 数値
+	CustomFieldDate         = "date"          // 日付（YYYY-MM-DD）
+	CustomFieldSingleSelect = "single_select" // 選択肢から1つ
+	CustomFieldMultiSelect  = "multi_select"  // 選択肢から複数
+	CustomFieldUser         = "user"          // ユーザー（ユーザーID）
+)
+
+// タスクをまとめるプロジェクト（カスタムフィールドの定義を持つ）
+type Project struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint      `json:"user_id" gorm:"not null;index"`
+}
+
+type ProjectResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// プロジェクトのタスクに設定できるカスタムフィールドの定義
+type CustomField struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Key       string    `json:"key" gorm:"not null;uniqueIndex:idx_custom_field_key"` // タスクの値や検索クエリで使うキー（作成後は変更できない）
+	Name      string    `json:"name" gorm:"not null"`
+	Type      string    `json:"type" gorm:"not null"`                            // 種類（作成後は変更できない）
+	Options   Labels    `json:"options" gorm:"type:jsonb;not null;default:'[]'"` // 選択肢（single_select / multi_select の場合）
+	Required  bool      `json:"required" gorm:"not null;default:false"`
+	Position  int       `json:"position" gorm:"not null;default:0"` // 表示順
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Project   Project   `json:"-" gorm:"foreignKey:ProjectId; constraint:OnDelete:CASCADE"`
+	ProjectId uint      `json:"project_id" gorm:"not null;uniqueIndex:idx_custom_field_key"`
+}
+
+type CustomFieldResponse struct {
+	ID        uint      `json:"id"`
+	Key       string    `json:"key"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Options   Labels    `json:"options"`
+	Required  bool      `json:"required"`
+	Position  int       `json:"position"`
+	ProjectId uint      `json:"project_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// タスクのカスタムフィールドの値（キーごとの値。PostgreSQLのjsonb列に保存）
+// 値の型はフィールドの種類に応じて、文字列、数値、日付の文字列、文字列の配列、ユーザーIDのいずれか
+type CustomFieldValues map[string]any
+
+// DBへ保存する際にJSON文字列へ変換
+func (v CustomFieldValues) Value() (driver.Value, error) {
+	if v == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// DBから読み込んだJSONを変換
+func (v *CustomFieldValues) Scan(src any) error {
+	switch s := src.(type) {
+	case nil:
+		*v = CustomFieldValues{}
+		return nil
+	case []byte:
+		return json.Unmarshal(s, v)
+	case string:
+		return json.Unmarshal([]byte(s), v)
+	default:
+		return fmt.Errorf("cannot scan %T into CustomFieldValues", src)
+	}
+}
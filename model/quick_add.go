@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// クイック追加のリクエスト
+type QuickAddRequest struct {
+	Text   string `json:"text"`    // 例: "Pay rent tomorrow 9am #home !high every month" / "明日 買い物"
+	DryRun bool   `json:"dry_run"` // true の場合は解析結果のみ返し、タスクは作成しない
+}
+
+// 解析で認識された文字列とその種類（due / label / priority / recurrence）
+type QuickAddToken struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"`
+}
+
+// クイック追加の解析結果
+type QuickAddInterpretation struct {
+	Title      string          `json:"title"`
+	DueAt      *time.Time      `json:"due_at"`
+	Labels     Labels          `json:"labels"`
+	Priority   string          `json:"priority"`
+	Recurrence string          `json:"recurrence"`
+	Tokens     []QuickAddToken `json:"tokens"` // UIで「何が認識されたか」を表示するための情報
+}
+
+type QuickAddResponse struct {
+	Parsed QuickAddInterpretation `json:"parsed"`
+	Task   *TaskResponse          `json:"task"` // dry_run の場合は null
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// アクセストークンの再発行に使用するリフレッシュトークン
+// トークン自体は保存せず、SHA-256 のハッシュのみを保存する
+// 使用するたびに同じファミリー（1回のログインで発行されたトークンの系列）の新しいトークンに置き換え、
+// 使用済みのトークンが再び使われた場合はファミリーのトークンをすべて失効させる
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	FamilyId  string     `json:"family_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"`    // 新しいトークンに置き換えた日時
+	RevokedAt *time.Time `json:"revoked_at"` // 失効させた日時
+	CreatedAt time.Time  `json:"created_at"`
+	SessionId uint       `json:"session_id" gorm:"not null;default:0;index"` // トークンを発行したセッション
+	User      User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint       `json:"user_id" gorm:"not null;index"`
+}
+
+// ログインやトークンの再発行で発行したトークン
+type AuthTokens struct {
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// リクエストボディでリフレッシュトークンを送信する場合（Cookieを使わないクライアント）
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// リマインダーの通知方法
+const (
+	ReminderChannelEmail   = "email"   // メール
+	ReminderChannelWebhook = "webhook" // 指定したURLへのPOST
+	ReminderChannelInApp   = "in_app"  // アプリ内通知
+)
+
+// タスクの期限に対するリマインダー（期限の OffsetMinutes 分前に通知する）
+type Reminder struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	OffsetMinutes int    `json:"offset_minutes" gorm:"not null"` // 期限の何分前に通知するか（0は期限ちょうど）
+	Channel       string `json:"channel" gorm:"not null"`
+	WebhookUrl    string `json:"webhook_url" gorm:"not null;default:''"` // Channel が webhook の場合の送信先
+	// 通知を送信したときのタスクの期限。期限が変更されると、新しい期限に対して再度通知される
+	SentForDueAt *time.Time `json:"-"`
+	SentAt       *time.Time `json:"sent_at"`
+	Attempts     int        `json:"-" gorm:"not null;default:0"` // 送信に失敗した回数
+	CreatedAt    time.Time  `json:"created_at"`
+	Task         Task       `json:"-" gorm:"foreignKey:TaskId; constraint:OnDelete:CASCADE"`
+	TaskId       uint       `json:"task_id" gorm:"not null;index"`
+	User         User       `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId       uint       `json:"user_id" gorm:"not null"` // 通知を受け取るユーザー
+}
+
+type ReminderResponse struct {
+	ID            uint       `json:"id"`
+	OffsetMinutes int        `json:"offset_minutes"`
+	Channel       string     `json:"channel"`
+	WebhookUrl    string     `json:"webhook_url"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	TaskId        uint       `json:"task_id"`
+}
+
+// webhook の署名に使うシークレット（受信側で X-Webhook-Signature を検証する）
+type WebhookSecretResponse struct {
+	Secret string `json:"secret"`
+}
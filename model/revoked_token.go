@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// ログアウトで失効させたアクセストークン
+// JWT の jti ごとに、トークンの有効期限まで保存する
+type RevokedToken struct {
+	Jti       string    `json:"jti" gorm:"primaryKey"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+	User      User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint      `json:"user_id" gorm:"not null"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// 名前を付けて保存した検索クエリ
+type SavedFilter struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	Query     string    `json:"query" gorm:"not null"`
+	Pinned    bool      `json:"pinned" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint      `json:"user_id" gorm:"not null"`
+}
+
+type SavedFilterResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Pinned    bool      `json:"pinned"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
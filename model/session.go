@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// ログインごとのセッション（ログインした端末）
+// アクセストークンとリフレッシュトークンはセッションに紐づき、セッションを失効させるとその端末はログアウトされる
+type Session struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserAgent  string     `json:"user_agent" gorm:"not null;default:''"`
+	IPAddress  string     `json:"ip_address" gorm:"not null;default:''"` // 最後にアクセスしたIPアドレス
+	LastSeenAt time.Time  `json:"last_seen_at" gorm:"not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null;index"` // 最後に発行したリフレッシュトークンの有効期限
+	RevokedAt  *time.Time `json:"revoked_at"`                       // ログアウトなどで失効させた日時
+	CreatedAt  time.Time  `json:"created_at"`
+	User       User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId     uint       `json:"user_id" gorm:"not null;index"`
+}
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"` // リクエストしたセッション自身かどうか
+}
+
+// ログインした端末の情報（セッションの作成時に記録する）
+type SessionMeta struct {
+	UserAgent string
+	IPAddress string
+}
@@ -0,0 +1,40 @@
+package model
+
+// ステータスごとのタスク数
+type StatusCount struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// 期間（日または週）ごとの作成数と完了数
+type ThroughputPoint struct {
+	Period    string `json:"period"` // 期間の開始日（YYYY-MM-DD）
+	Created   int64  `json:"created"`
+	Completed int64  `json:"completed"`
+}
+
+// バーンダウンの1日分（その日の終わり時点で未完了のタスク数）
+type BurndownPoint struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	Remaining int64  `json:"remaining"`
+}
+
+// 連続してタスクを完了した期間
+type Streak struct {
+	Start  string `json:"start"` // YYYY-MM-DD
+	End    string `json:"end"`   // YYYY-MM-DD
+	Length int    `json:"length"`
+}
+
+type StatsResponse struct {
+	Timezone             string            `json:"timezone"`
+	From                 string            `json:"from"`
+	To                   string            `json:"to"`
+	Interval             string            `json:"interval"`
+	CountsByStatus       []StatusCount     `json:"counts_by_status"`
+	Throughput           []ThroughputPoint `json:"throughput"`
+	AverageLeadTimeHours *float64          `json:"average_lead_time_hours"` // 完了タスクがない場合はnull
+	CurrentStreak        int               `json:"current_streak"`
+	LongestStreak        *Streak           `json:"longest_streak"`
+	Burndown             []BurndownPoint   `json:"burndown"`
+}
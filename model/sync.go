@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// 変更の順序を表すシーケンスの名前（tasks・comments・tombstones の change_seq の採番に使用）
+const ChangeSeqName = "change_seq"
+
+// 同期の対象となるエンティティの種類
+const (
+	SyncEntityTask    = "task"
+	SyncEntityComment = "comment"
+)
+
+// オフラインでの変更の種類
+const (
+	SyncOpCreate = "create"
+	SyncOpUpdate = "update"
+	SyncOpDelete = "delete"
+)
+
+// オフラインでの変更を適用した結果
+const (
+	SyncStatusApplied  = "applied"   // 適用された
+	SyncStatusConflict = "conflict"  // サーバー側で先に変更されていたため適用されなかった
+	SyncStatusNotFound = "not_found" // 対象が存在しない（削除済みなど）
+	SyncStatusError    = "error"     // バリデーションエラーなどで適用されなかった
+)
+
+// 削除の記録（差分同期で削除を伝えるために使用）
+// タスクを閲覧できなくなった場合（割り当ての解除など）も、そのユーザーに対して記録する
+type Tombstone struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	EntityType string    `json:"entity_type" gorm:"not null"`
+	EntityId   uint      `json:"entity_id" gorm:"not null"`
+	ChangeSeq  int64     `json:"change_seq" gorm:"not null;default:nextval('change_seq');index"`
+	CreatedAt  time.Time `json:"created_at"`
+	User       User      `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId     uint      `json:"user_id" gorm:"not null;index"` // 削除を伝える相手
+}
+
+// 差分同期で返す削除されたエンティティ
+type SyncDeleted struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// GET /sync のレスポンス
+// クライアントは deleted を先に適用してから tasks・comments を反映する
+type SyncResponse struct {
+	Tasks    []TaskResponse    `json:"tasks"`
+	Comments []CommentResponse `json:"comments"`
+	Deleted  []SyncDeleted     `json:"deleted"`
+	Next     string            `json:"next"`     // 次回の since に指定するトークン
+	HasMore  bool              `json:"has_more"` // true の場合は next ですぐに続きを取得する
+}
+
+// オフラインでの1件の変更
+type SyncChange struct {
+	ClientId    string     `json:"client_id"`    // クライアントが結果を対応付けるためのID
+	Op          string     `json:"op"`           // create / update / delete
+	ID          uint       `json:"id"`           // update・delete の対象のタスクID
+	BaseVersion *int64     `json:"base_version"` // update の場合、クライアントが最後に取得したタスクの version（省略時は上書き）
+	Task        TaskUpdate `json:"task"`         // create の内容、または update で変更する項目（省略された項目は変更しない）
+}
+
+// POST /sync のリクエスト
+type SyncRequest struct {
+	Changes []SyncChange `json:"changes"`
+}
+
+// オフラインでの1件の変更を適用した結果
+type SyncResult struct {
+	ClientId string        `json:"client_id"`
+	Status   string        `json:"status"`
+	Task     *TaskResponse `json:"task,omitempty"` // 適用後のタスク、または競合した場合はサーバー側のタスク
+	Error    string        `json:"error,omitempty"`
+}
+
+// POST /sync のレスポンス
+type SyncUploadResponse struct {
+	Results []SyncResult `json:"results"`
+}
@@ -1,19 +1,170 @@
 package model
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// タスクのステータス
+const (
+	TaskStatusOpen = "open" // 未完了
+	TaskStatusDone = "done" // 完了
+)
+
+// タスクの優先度
+const (
+	TaskPriorityNone   = "none"
+	TaskPriorityLow    = "low"
+	TaskPriorityMedium = "medium"
+	TaskPriorityHigh   = "high"
+)
+
+// 担当者への割り当て状態
+const (
+	AssignmentPending  = "pending"  // 承諾待ち
+	AssignmentAccepted = "accepted" // 承諾済み
+	AssignmentDeclined = "declined" // 辞退
+)
+
+// タスクの繰り返し
+const (
+	TaskRecurrenceDaily   = "daily"
+	TaskRecurrenceWeekly  = "weekly"
+	TaskRecurrenceMonthly = "monthly"
+	TaskRecurrenceYearly  = "yearly"
+)
 
 type Task struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	User      User      `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
-	UserId    uint      `json:"user_id" gorm:"not null"`
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Title       string     `json:"title" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;default:open"`
+	DueAt       *time.Time `json:"due_at"`
+	Labels      Labels     `json:"labels" gorm:"type:jsonb;not null;default:'[]'"`
+	Priority    string     `json:"priority" gorm:"not null;default:none"`
+	Recurrence  string     `json:"recurrence" gorm:"not null;default:''"` // 繰り返し（空文字は繰り返しなし）
+	CompletedAt *time.Time `json:"completed_at"`
+	// スヌーズの期限。この日時まではタスクの一覧（snoozed: の指定がない検索を含む）に表示されない
+	SnoozedUntil *time.Time `json:"snoozed_until"`
+	Parent       *Task      `json:"-" gorm:"foreignKey:ParentId; constraint:OnDelete:CASCADE"`
+	ParentId     *uint      `json:"parent_id"` // 親タスクのID（サブタスクの場合）
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	User         User       `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId       uint       `json:"user_id" gorm:"not null"`
+	// 担当者（作成者とは別のユーザー）。承諾待ちまたは承諾済みの担当者は閲覧でき、承諾済みの担当者は更新もできる
+	Assignee         *User  `json:"-" gorm:"foreignKey:AssigneeId; constraint:OnDelete:SET NULL"`
+	AssigneeId       *uint  `json:"assignee_id"`
+	AssignmentStatus string `json:"assignment_status" gorm:"not null;default:''"`
+	// 期限間近のリマインダーを送信した日時（期限が変更されるとクリアされる）
+	DueSoonNotifiedAt *time.Time `json:"-"`
+	// 作成・更新のたびに change_seq シーケンスから採番される値（差分同期と競合検出に使用）
+	ChangeSeq int64 `json:"-" gorm:"not null;default:nextval('change_seq');index"`
+	// チェックリストの項目数とチェック済みの数（項目の変更時に更新される）
+	ChecklistTotal   int `json:"-" gorm:"not null;default:0"`
+	ChecklistChecked int `json:"-" gorm:"not null;default:0"`
+	// プロジェクトと、プロジェクトで定義されたカスタムフィールドの値
+	Project      *Project          `json:"-" gorm:"foreignKey:ProjectId; constraint:OnDelete:SET NULL"`
+	ProjectId    *uint             `json:"project_id" gorm:"index"`
+	CustomFields CustomFieldValues `json:"custom_fields" gorm:"type:jsonb;not null;default:'{}'"`
 }
 
 type TaskResponse struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Title        string     `json:"title" gorm:"not null"`
+	Status       string     `json:"status"`
+	DueAt        *time.Time `json:"due_at"`
+	Labels       Labels     `json:"labels"`
+	Priority     string     `json:"priority"`
+	Recurrence   string     `json:"recurrence"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	SnoozedUntil *time.Time `json:"snoozed_until"`
+	ParentId     *uint      `json:"parent_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	// 作成者と担当者
+	UserId           uint   `json:"user_id"`
+	AssigneeId       *uint  `json:"assignee_id"`
+	AssignmentStatus string `json:"assignment_status"`
+	// 更新のたびに増える値（オフラインでの更新時に base_version として送信する）
+	Version int64 `json:"version"`
+	// チェックリストの進捗
+	Checklist ChecklistProgress `json:"checklist"`
+	// プロジェクトとカスタムフィールドの値
+	ProjectId    *uint             `json:"project_id"`
+	CustomFields CustomFieldValues `json:"custom_fields"`
+}
+
+// タスクを他のユーザーに割り当てる際のリクエスト
+type TaskAssignRequest struct {
+	Email string `json:"email"` // 担当者のメールアドレス
+}
+
+// タスクの更新リクエスト（PUT /tasks/:taskId と、同期の update）
+// 送信された項目のみを更新し、省略された項目は変更しない
+type TaskUpdate struct {
+	Task
+	Fields []string `json:"-"` // 送信された更新できる項目（JSONのキー、TaskUpdatableFields の順）
+}
+
+// PUT /tasks/:taskId で更新できる項目（JSONのキーとDBの列名は同じ）
+var TaskUpdatableFields = []string{"title", "status", "due_at", "labels", "priority", "recurrence", "project_id", "custom_fields"}
+
+// タスクの内容とあわせて、送信された項目を記録する
+func (u *TaskUpdate) UnmarshalJSON(b []byte) error {
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &u.Task); err != nil {
+		return err
+	}
+	u.Fields = []string{}
+	for _, field := range TaskUpdatableFields {
+		if _, ok := keys[field]; ok {
+			u.Fields = append(u.Fields, field)
+		}
+	}
+	return nil
+}
+
+// 指定された項目が送信されたかどうか
+func (u TaskUpdate) Has(field string) bool {
+	for _, f := range u.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// タスクに付与するラベルの一覧（PostgreSQLのjsonb列に保存）
+type Labels []string
+
+// DBへ保存する際にJSON文字列へ変換
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// DBから読み込んだJSONを変換
+func (l *Labels) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = Labels{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into Labels", src)
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 繰り返し使うタスクの構成（チェックリスト）を保存したテンプレート
+type TaskTemplate struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null"`
+	Description string         `json:"description"`
+	Items       TaskBlueprints `json:"items" gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	User        User           `json:"-" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId      uint           `json:"user_id" gorm:"not null"`
+}
+
+// テンプレートに含まれるタスクの設計図
+// タイトルとラベルには {{name}} 形式のプレースホルダを含めることができる
+type TaskBlueprint struct {
+	Title         string          `json:"title"`
+	Labels        Labels          `json:"labels,omitempty"`
+	DueOffsetDays *int            `json:"due_offset_days,omitempty"` // 基準日時から期限日までの日数（負の値も可）
+	Children      []TaskBlueprint `json:"children,omitempty"`        // サブタスク
+}
+
+// テンプレートのタスク一覧（PostgreSQLのjsonb列に保存）
+type TaskBlueprints []TaskBlueprint
+
+// DBへ保存する際にJSON文字列へ変換
+func (b TaskBlueprints) Value() (driver.Value, error) {
+	if b == nil {
+		return "[]", nil
+	}
+	j, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return string(j), nil
+}
+
+// DBから読み込んだJSONを変換
+func (b *TaskBlueprints) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*b = TaskBlueprints{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, b)
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	default:
+		return fmt.Errorf("cannot scan %T into TaskBlueprints", src)
+	}
+}
+
+type TaskTemplateResponse struct {
+	ID          uint           `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Items       TaskBlueprints `json:"items"`
+	Variables   []string       `json:"variables"` // テンプレート内で使われているプレースホルダ名
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// テンプレートからタスクを作成する際のリクエスト
+type TaskTemplateInstantiateRequest struct {
+	Variables map[string]string `json:"variables"` // プレースホルダに埋め込む値
+	BaseDate  *time.Time        `json:"base_date"` // 期限日の基準日時（省略時は現在時刻）
+}
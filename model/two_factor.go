@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// ログインの2段階目に使える方法
+const (
+	MfaMethodTotp    = "totp"    // 認証アプリの確認コード（またはリカバリーコード）
+	MfaMethodPasskey = "passkey" // 登録済みのパスキー
+)
+
+// 2段階認証のリカバリーコード（認証アプリを使えない場合に、確認コードの代わりに一度だけ使える）
+// コード自体は保存せず、SHA-256 のハッシュのみを保存する
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	CodeHash  string     `json:"-" gorm:"not null;uniqueIndex"`
+	UsedAt    *time.Time `json:"used_at"` // 使用した日時
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `json:"user" gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	UserId    uint       `json:"user_id" gorm:"not null;index"`
+}
+
+// 2段階認証の設定状況のレスポンス
+type TwoFactorStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"` // 未使用のリカバリーコードの数
+}
+
+// 2段階認証の設定を開始したときのレスポンス
+// 認証アプリに provisioning_uri（QRコード）またはシークレットを登録し、表示されたコードで設定を確定する
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// リカバリーコードを発行したときのレスポンス（コードはこのレスポンスでのみ返す）
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// 認証アプリの確認コード、またはリカバリーコードを送信するリクエスト
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// ログインの2段階目を確認コードで行うリクエスト
+type MfaLoginRequest struct {
+	MfaToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+// ログインの結果
+// 2段階認証が有効、またはパスキーを登録しているユーザーは、トークンの代わりに2段階目に使う MfaToken を返す
+type LoginResult struct {
+	Tokens            AuthTokens
+	MfaToken          string
+	MfaTokenExpiresAt time.Time
+	MfaMethods        []string // 2段階目に使える方法（MfaMethodTotp、MfaMethodPasskey）
+}
+
+// ログインの2段階目が必要なことを表すログインのレスポンス
+type MfaRequiredResponse struct {
+	MfaRequired bool      `json:"mfa_required"`
+	MfaToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Methods     []string  `json:"methods"` // 2段階目に使える方法（totp、passkey）
+}
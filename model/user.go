@@ -6,11 +6,58 @@ type User struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	Email     string    `json:"email" gorm:"unique"`
 	Password  string    `json:"password"`
+	Timezone  string    `json:"timezone" gorm:"not null;default:Asia/Tokyo"` // 日付の集計などに使用するタイムゾーン（IANA名）
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// この日時（秒単位）より前に発行されたアクセストークンは無効（すべての端末からのログアウトやパスワードの変更で更新）
+	TokensValidAfter *time.Time `json:"-"`
+	// メールアドレスの確認を完了した日時（nil の場合は未確認）
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// 確認リンクに含める使い捨ての値（確認するか、リンクを再送すると変わるため、古いリンクは使えなくなる）
+	EmailVerificationNonce string `json:"-" gorm:"not null;default:''"`
+	// 確認メールを最後に送信した日時（再送の間隔の制限に使用）
+	EmailVerificationSentAt *time.Time `json:"-"`
+	// 2段階認証（TOTP）のシークレット（設定を確定する前のものを含む）
+	TotpSecret string `json:"-" gorm:"not null;default:''"`
+	// 2段階認証を有効にした日時（nil の場合は無効）
+	TotpEnabledAt *time.Time `json:"-"`
+	// 最後に使用した確認コードのステップ（同じコードを再び使えないようにする）
+	TotpLastStep int64 `json:"-" gorm:"not null;default:0"`
+	// リマインダーの webhook の署名に使うシークレット（空の場合は最初に必要になったときに生成する）
+	WebhookSecret string `json:"-" gorm:"not null;default:''"`
+}
+
+// パスワード変更のリクエスト
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// メールアドレス変更のリクエスト（変更には現在のパスワードが必要）
+type EmailChangeRequest struct {
+	NewEmail        string `json:"new_email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// アカウント削除のリクエスト（確認のためパスワードが必要）
+type AccountDeleteRequest struct {
+	Password string `json:"password"`
+}
+
+// メールアドレスの確認のリクエスト（確認リンクに含まれるトークン）
+type EmailVerificationRequest struct {
+	Token string `json:"token"`
+}
+
+// 確認メールの再送のリクエスト
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
 }
 
 type UserResponse struct {
-	ID    uint   `json:"id" gorm:"primaryKey"`
-	Email string `json:"email" gorm:"unique"`
-}
\ No newline at end of file
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	Email            string     `json:"email" gorm:"unique"`
+	Timezone         string     `json:"timezone"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+}
@@ -0,0 +1,15 @@
+package model
+
+// 入力値の検証エラーのレスポンス（422 Unprocessable Entity）
+type ValidationErrorResponse struct {
+	Message string               `json:"message"`
+	Errors  []FieldErrorResponse `json:"errors"`
+}
+
+// フィールドごとの検証エラー
+type FieldErrorResponse struct {
+	Field   string         `json:"field"`            // フィールド名（ネストした値は "labels.0" のようにドットで区切る）
+	Code    string         `json:"code"`             // エラーコード（required, too_long など）
+	Message string         `json:"message"`          // Accept-Language の言語のメッセージ
+	Params  map[string]any `json:"params,omitempty"` // メッセージに埋め込まれたパラメータ（max など）
+}
@@ -0,0 +1,65 @@
+package push
+
+import (
+	"sync"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+)
+
+// 1接続あたりのバッファ件数（あふれた分はその接続へは配信しない）
+const subscriberBuffer = 16
+
+// 接続中のユーザーへ通知をリアルタイムに配信するハブ
+// 接続はプロセス内で管理するため、配信されるのは同じプロセスに接続しているクライアントのみ
+// （受信箱には常に保存されるので、取りこぼした通知は GET /notifications で取得できる）
+type IHub interface {
+	Subscribe(userId uint) (<-chan model.NotificationResponse, func()) // 購読を開始し、受信用チャネルと解除関数を返す
+	Publish(userId uint, notification model.NotificationResponse)      // ユーザーの全接続へ配信
+}
+
+type hub struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan model.NotificationResponse]struct{}
+}
+
+// コンストラクタ関数
+func NewHub() IHub {
+	return &hub{subs: map[uint]map[chan model.NotificationResponse]struct{}{}}
+}
+
+// 購読を開始し、受信用チャネルと解除関数を返す
+func (h *hub) Subscribe(userId uint) (<-chan model.NotificationResponse, func()) {
+	ch := make(chan model.NotificationResponse, subscriberBuffer)
+	h.mu.Lock()
+	if h.subs[userId] == nil {
+		h.subs[userId] = map[chan model.NotificationResponse]struct{}{}
+	}
+	h.subs[userId][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs[userId], ch)
+			if len(h.subs[userId]) == 0 {
+				delete(h.subs, userId)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// ユーザーの全接続へ配信（受信側が詰まっている場合は送信をスキップしてブロックしない）
+func (h *hub) Publish(userId uint, notification model.NotificationResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[userId] {
+		select {
+		case ch <- notification:
+		default:
+		}
+	}
+}
@@ -0,0 +1,423 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// タスク検索クエリで使用できるフィールド
+const (
+	FieldText   = ""       // フィールド指定のない語（タイトルの部分一致）
+	FieldTitle  = "title"  // タイトルの部分一致
+	FieldStatus = "status" // ステータス（open / done）
+	FieldDue    = "due"    // 期限日
+	FieldLabel  = "label"  // ラベル
+	// スヌーズ中かどうか（true / false / any）
+	// 指定がない場合はスヌーズ中のタスクを除外する
+	FieldSnoozed = "snoozed"
+	FieldProject = "project" // プロジェクトID
+	// カスタムフィールド（"cf.<キー>:値" の形式で指定する）
+	FieldCustom = "cf"
+	// 並び順（条件ではなく Query.Sort に格納される）
+	FieldSort = "sort"
+)
+
+// sort: で指定できる列
+var sortColumns = []string{"created_at", "updated_at", "due_at", "title"}
+
+// 比較演算子
+type Op string
+
+const (
+	OpEq Op = "="
+	OpLt Op = "<"
+	OpLe Op = "<="
+	OpGt Op = ">"
+	OpGe Op = ">="
+)
+
+// 期限日条件の種類
+type DueKind string
+
+const (
+	DueNone     DueKind = "none"     // 期限なし
+	DueToday    DueKind = "today"    // 今日が期限
+	DueOverdue  DueKind = "overdue"  // 期限切れ
+	DueRelative DueKind = "relative" // 現在からの相対日数（例: <7d）
+	DueDate     DueKind = "date"     // 日付指定（例: >=2025-01-01）
+)
+
+// 期限日の条件
+type Due struct {
+	Kind DueKind
+	Op   Op
+	Days int       // DueRelative の場合の日数
+	Date time.Time // DueDate の場合の日付（タイムゾーンなしの日付として扱う）
+}
+
+// カスタムフィールドの条件
+// Op が OpEq 以外の場合、Value は数値または日付（YYYY-MM-DD）
+type Custom struct {
+	Key   string // フィールドのキー
+	Op    Op
+	Value string
+}
+
+// 並び順の1項目
+type SortKey struct {
+	Column string // sortColumns のいずれか（カスタムフィールドの場合は空）
+	Custom string // カスタムフィールドのキー
+	Desc   bool   // 降順
+}
+
+// 検索条件の1項目
+type Term struct {
+	Pos    int      // クエリ文字列中の位置（1始まり、文字単位）
+	Negate bool     // 先頭に "-" が付いている場合は否定条件
+	Field  string   // フィールド名（FieldText の場合はフリーテキスト）
+	Value  string   // 値
+	Due    *Due     // FieldDue の場合の解析済み条件
+	Custom *Custom  // FieldCustom の場合の解析済み条件
+	Sort   *SortKey // FieldSort の場合の解析済みの並び順
+}
+
+// 解析済みの検索クエリ（各項目はANDで結合される）
+type Query struct {
+	Terms []Term
+	Sort  []SortKey // 並び順（先に指定したものが優先。指定がない場合は作成日時順）
+}
+
+// クエリの解析エラー（位置情報付き）
+type ParseError struct {
+	Pos int    // エラー位置（1始まり、文字単位）
+	Msg string // エラー内容
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("query error at position %d: %s", e.Pos, e.Msg)
+}
+
+// 検索クエリ文字列を解析する
+// 例: `status:open due:<7d label:work -label:someday`
+func Parse(s string) (Query, error) {
+	p := parser{src: []rune(s)}
+	q := Query{Terms: []Term{}}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return q, nil
+		}
+		term, err := p.parseTerm()
+		if err != nil {
+			return Query{}, err
+		}
+		if term.Sort != nil {
+			q.Sort = append(q.Sort, *term.Sort)
+			continue
+		}
+		q.Terms = append(q.Terms, term)
+	}
+}
+
+// 字句解析と構文解析を行う内部構造体
+type parser struct {
+	src []rune
+	pos int // 0始まりの現在位置
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) error {
+	return &ParseError{Pos: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// 1項目を解析する
+func (p *parser) parseTerm() (Term, error) {
+	start := p.pos
+	term := Term{Pos: start + 1}
+	if p.src[p.pos] == '-' {
+		term.Negate = true
+		p.pos++
+		if p.eof() || unicode.IsSpace(p.src[p.pos]) {
+			return Term{}, p.errorf(start, "expected term after \"-\"")
+		}
+	}
+
+	// 引用符で始まる場合はフリーテキスト
+	if p.src[p.pos] == '"' {
+		value, err := p.parseQuoted()
+		if err != nil {
+			return Term{}, err
+		}
+		term.Field = FieldText
+		term.Value = value
+		return term, nil
+	}
+
+	// "field:" の形式かどうかを判定
+	wordStart := p.pos
+	for !p.eof() && !unicode.IsSpace(p.src[p.pos]) && p.src[p.pos] != ':' && p.src[p.pos] != '"' {
+		p.pos++
+	}
+	if p.eof() || p.src[p.pos] != ':' {
+		// フィールド指定なしの単語
+		p.pos = wordStart
+		value, err := p.parseWord()
+		if err != nil {
+			return Term{}, err
+		}
+		term.Field = FieldText
+		term.Value = value
+		return term, nil
+	}
+
+	field := strings.ToLower(string(p.src[wordStart:p.pos]))
+	if field == "" {
+		return Term{}, p.errorf(wordStart, "expected field name before \":\"")
+	}
+	p.pos++ // ':' を読み飛ばす
+	valueStart := p.pos
+	var value string
+	var err error
+	if !p.eof() && p.src[p.pos] == '"' {
+		value, err = p.parseQuoted()
+	} else {
+		value, err = p.parseWord()
+	}
+	if err != nil {
+		return Term{}, err
+	}
+	if value == "" {
+		return Term{}, p.errorf(valueStart, "expected value for field %q", field)
+	}
+	term.Field = field
+	term.Value = value
+
+	// カスタムフィールド（cf.<キー>）
+	if strings.HasPrefix(field, FieldCustom+".") {
+		key := strings.TrimPrefix(field, FieldCustom+".")
+		if !isCustomFieldKey(key) {
+			return Term{}, p.errorf(wordStart, "invalid custom field key %q", key)
+		}
+		custom, err := parseCustom(key, value)
+		if err != nil {
+			return Term{}, p.errorf(valueStart, "%s", err.Error())
+		}
+		term.Field = FieldCustom
+		term.Custom = &custom
+		return term, nil
+	}
+
+	// フィールドごとの値の検証
+	switch field {
+	case FieldTitle, FieldLabel:
+	case FieldStatus:
+		term.Value = strings.ToLower(value)
+		if term.Value != "open" && term.Value != "done" {
+			return Term{}, p.errorf(valueStart, "unknown status %q (expected open or done)", value)
+		}
+	case FieldSnoozed:
+		term.Value = strings.ToLower(value)
+		if term.Value != "true" && term.Value != "false" && term.Value != "any" {
+			return Term{}, p.errorf(valueStart, "unknown snoozed value %q (expected true, false or any)", value)
+		}
+		if term.Negate && term.Value == "any" {
+			return Term{}, p.errorf(start, "snoozed:any cannot be negated")
+		}
+	case FieldProject:
+		if id, err := strconv.ParseUint(value, 10, 32); err != nil || id == 0 {
+			return Term{}, p.errorf(valueStart, "invalid project id %q", value)
+		}
+	case FieldSort:
+		if term.Negate {
+			return Term{}, p.errorf(start, "sort cannot be negated")
+		}
+		sort, err := parseSort(value)
+		if err != nil {
+			return Term{}, p.errorf(valueStart, "%s", err.Error())
+		}
+		term.Sort = &sort
+	case FieldDue:
+		due, err := parseDue(value)
+		if err != nil {
+			return Term{}, p.errorf(valueStart, "%s", err.Error())
+		}
+		term.Due = &due
+	default:
+		return Term{}, p.errorf(wordStart, "unknown field %q", field)
+	}
+	return term, nil
+}
+
+// 空白または引用符までの単語を読み取る
+func (p *parser) parseWord() (string, error) {
+	start := p.pos
+	for !p.eof() && !unicode.IsSpace(p.src[p.pos]) {
+		if p.src[p.pos] == '"' {
+			return "", p.errorf(p.pos, "unexpected quote")
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+// 引用符で囲まれた文字列を読み取る（\" と \\ のエスケープに対応）
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++ // 開始の '"'
+	var sb strings.Builder
+	for !p.eof() {
+		r := p.src[p.pos]
+		switch {
+		case r == '\\' && p.pos+1 < len(p.src):
+			sb.WriteRune(p.src[p.pos+1])
+			p.pos += 2
+		case r == '"':
+			p.pos++
+			if !p.eof() && !unicode.IsSpace(p.src[p.pos]) {
+				return "", p.errorf(p.pos, "expected space after closing quote")
+			}
+			return sb.String(), nil
+		default:
+			sb.WriteRune(r)
+			p.pos++
+		}
+	}
+	return "", p.errorf(start, "unterminated quoted string")
+}
+
+// 期限日の条件を解析する
+// today / overdue / none / <7d / >=2w / 2025-01-01 / <2025-01-01 などに対応
+func parseDue(s string) (Due, error) {
+	switch strings.ToLower(s) {
+	case "none":
+		return Due{Kind: DueNone}, nil
+	case "today":
+		return Due{Kind: DueToday}, nil
+	case "overdue":
+		return Due{Kind: DueOverdue}, nil
+	}
+
+	op := OpEq
+	for _, candidate := range []Op{OpLe, OpGe, OpLt, OpGt} {
+		if strings.HasPrefix(s, string(candidate)) {
+			op = candidate
+			s = s[len(candidate):]
+			break
+		}
+	}
+	if s == "" {
+		return Due{}, fmt.Errorf("expected date or relative days after %q", op)
+	}
+
+	// 相対日数（d: 日, w: 週）
+	if unit := s[len(s)-1]; unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n >= 0 {
+			if unit == 'w' {
+				n *= 7
+			}
+			if op == OpEq {
+				op = OpLe
+			}
+			return Due{Kind: DueRelative, Op: op, Days: n}, nil
+		}
+	}
+
+	// 日付指定
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return Due{}, fmt.Errorf("invalid due value %q (expected today, overdue, none, <Nd, <Nw or YYYY-MM-DD)", s)
+	}
+	return Due{Kind: DueDate, Op: op, Date: date}, nil
+}
+
+// カスタムフィールドのキーとして使える文字列か（英小文字・数字・アンダースコア）
+func isCustomFieldKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// カスタムフィールドの条件を解析する
+// acme（一致、複数選択の場合はいずれかが一致）/ >=3 / <2025-01-01 などに対応
+func parseCustom(key string, s string) (Custom, error) {
+	op := OpEq
+	for _, candidate := range []Op{OpLe, OpGe, OpLt, OpGt} {
+		if strings.HasPrefix(s, string(candidate)) {
+			op = candidate
+			s = s[len(candidate):]
+			break
+		}
+	}
+	if op == OpEq {
+		return Custom{Key: key, Op: op, Value: s}, nil
+	}
+	if s == "" {
+		return Custom{}, fmt.Errorf("expected number or date after %q", op)
+	}
+	// 大小の比較は数値または日付のみ
+	custom := Custom{Key: key, Op: op, Value: s}
+	if _, ok := custom.Number(); !ok {
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return Custom{}, fmt.Errorf("invalid value %q (expected number or YYYY-MM-DD after %q)", s, op)
+		}
+	}
+	return custom, nil
+}
+
+// 値を数値として解釈できる場合はその値を返す（NaN や無限大、16進表記は数値とみなさない）
+func (c Custom) Number() (float64, bool) {
+	if strings.ContainsAny(c.Value, "xXnN") {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
+
+// 並び順を解析する
+// created_at / -due_at / cf.points / -cf.points（先頭の "-" は降順）に対応
+func parseSort(s string) (SortKey, error) {
+	sort := SortKey{}
+	if strings.HasPrefix(s, "-") {
+		sort.Desc = true
+		s = s[1:]
+	}
+	s = strings.ToLower(s)
+	if strings.HasPrefix(s, FieldCustom+".") {
+		key := strings.TrimPrefix(s, FieldCustom+".")
+		if !isCustomFieldKey(key) {
+			return SortKey{}, fmt.Errorf("invalid custom field key %q", key)
+		}
+		sort.Custom = key
+		return sort, nil
+	}
+	for _, column := range sortColumns {
+		if s == column {
+			sort.Column = column
+			return sort, nil
+		}
+	}
+	return SortKey{}, fmt.Errorf("unknown sort %q (expected %s or cf.<key>)", s, strings.Join(sortColumns, ", "))
+}
@@ -0,0 +1,169 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		input string
+		want  Query
+	}{
+		{"空のクエリ", "  ", Query{Terms: []Term{}}},
+		{
+			"フリーテキスト",
+			`牛乳 "買い物 リスト"`,
+			Query{Terms: []Term{
+				{Pos: 1, Field: FieldText, Value: "牛乳"},
+				{Pos: 4, Field: FieldText, Value: "買い物 リスト"},
+			}},
+		},
+		{
+			"エスケープされた引用符",
+			`title:"say \"hi\" \\ bye"`,
+			Query{Terms: []Term{{Pos: 1, Field: FieldTitle, Value: `say "hi" \ bye`}}},
+		},
+		{
+			"否定とフィールド名の大文字小文字",
+			"Status:DONE -label:someday",
+			Query{Terms: []Term{
+				{Pos: 1, Field: FieldStatus, Value: "done"},
+				{Pos: 13, Negate: true, Field: FieldLabel, Value: "someday"},
+			}},
+		},
+		{
+			"期限のキーワード",
+			"due:today due:overdue -due:none",
+			Query{Terms: []Term{
+				{Pos: 1, Field: FieldDue, Value: "today", Due: &Due{Kind: DueToday}},
+				{Pos: 11, Field: FieldDue, Value: "overdue", Due: &Due{Kind: DueOverdue}},
+				{Pos: 23, Negate: true, Field: FieldDue, Value: "none", Due: &Due{Kind: DueNone}},
+			}},
+		},
+		{
+			"期限の相対日数",
+			"due:<7d due:2w due:>=0d",
+			Query{Terms: []Term{
+				{Pos: 1, Field: FieldDue, Value: "<7d", Due: &Due{Kind: DueRelative, Op: OpLt, Days: 7}},
+				{Pos: 9, Field: FieldDue, Value: "2w", Due: &Due{Kind: DueRelative, Op: OpLe, Days: 14}},
+				{Pos: 16, Field: FieldDue, Value: ">=0d", Due: &Due{Kind: DueRelative, Op: OpGe, Days: 0}},
+			}},
+		},
+		{
+			"期限の日付",
+			"due:2025-01-01 due:<=2025-01-01",
+			Query{Terms: []Term{
+				{Pos: 1, Field: FieldDue, Value: "2025-01-01", Due: &Due{Kind: DueDate, Op: OpEq, Date: date}},
+				{Pos: 16, Field: FieldDue, Value: "<=2025-01-01", Due: &Due{Kind: DueDate, Op: OpLe, Date: date}},
+			}},
+		},
+		{
+			"スヌーズとプロジェクト",
+			"snoozed:Any project:12",
+			Query{Terms: []Term{
+				{Pos: 1, Field: FieldSnoozed, Value: "any"},
+				{Pos: 13, Field: FieldProject, Value: "12"},
+			}},
+		},
+		{
+			"カスタムフィールド",
+			"cf.customer:acme cf.points:>=3 cf.deadline:<2025-01-01",
+			Query{Terms: []Term{
+				{Pos: 1, Field: FieldCustom, Value: "acme", Custom: &Custom{Key: "customer", Op: OpEq, Value: "acme"}},
+				{Pos: 18, Field: FieldCustom, Value: ">=3", Custom: &Custom{Key: "points", Op: OpGe, Value: "3"}},
+				{Pos: 32, Field: FieldCustom, Value: "<2025-01-01", Custom: &Custom{Key: "deadline", Op: OpLt, Value: "2025-01-01"}},
+			}},
+		},
+		{
+			"並び順は条件に含めない",
+			"sort:-due_at label:work sort:cf.points",
+			Query{
+				Terms: []Term{{Pos: 14, Field: FieldLabel, Value: "work"}},
+				Sort:  []SortKey{{Column: "due_at", Desc: true}, {Custom: "points"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{"-", 1, `expected term after "-"`},
+		{"- label:x", 1, `expected term after "-"`},
+		{":open", 1, "expected field name"},
+		{"status:", 8, `expected value for field "status"`},
+		{"status:pending", 8, `unknown status "pending"`},
+		{"owner:me", 1, `unknown field "owner"`},
+		{`title:"unterminated`, 7, "unterminated quoted string"},
+		{`"a"b`, 4, "expected space after closing quote"},
+		{`ab"c`, 3, "unexpected quote"},
+		{"due:<", 5, "expected date or relative days"},
+		{"due:2025-13-01", 5, "invalid due value"},
+		{"due:-1d", 5, "invalid due value"},
+		{"snoozed:maybe", 9, "unknown snoozed value"},
+		{"-snoozed:any", 1, "snoozed:any cannot be negated"},
+		{"project:0", 9, "invalid project id"},
+		{"project:abc", 9, "invalid project id"},
+		{"-sort:title", 1, "sort cannot be negated"},
+		{"sort:priority", 6, `unknown sort "priority"`},
+		{"cf.Bad-Key:x", 1, "invalid custom field key"},
+		{"cf.points:>abc", 11, "invalid value"},
+		{"cf.points:>NaN", 11, "invalid value"},
+		{"牛乳 status:x", 11, "unknown status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse(%q) error = %v, want *ParseError", tt.input, err)
+			}
+			if perr.Pos != tt.pos || !strings.Contains(perr.Msg, tt.msg) {
+				t.Errorf("Parse(%q) error = %d %q, want %d %q", tt.input, perr.Pos, perr.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
+
+func TestCustomNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+		ok    bool
+	}{
+		{"3", 3, true},
+		{"-1.5", -1.5, true},
+		{"1e3", 1000, true},
+		{"0x10", 0, false},
+		{"NaN", 0, false},
+		{"Inf", 0, false},
+		{"1e400", 0, false},
+		{"acme", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Custom{Value: tt.value}.Number()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Number(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+)
+
+// 解析結果に含まれる要素の種類
+const (
+	KindDue        = "due"        // 期限日・時刻
+	KindLabel      = "label"      // ラベル（#work）
+	KindPriority   = "priority"   // 優先度（!high）
+	KindRecurrence = "recurrence" // 繰り返し（every month / 毎月）
+)
+
+var (
+	// 英語の時刻表記（9am, 9:30pm, 21:00）
+	enTime = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	// 日本語の時刻表記（9時, 9時半, 21時30分, 午前9時, 午後3時）
+	jaTime = regexp.MustCompile(`^(午前|午後)?(\d{1,2})時(半|(\d{1,2})分)?`)
+	// 日付表記（2025-01-31, 1/31）
+	isoDate   = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	slashDate = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})$`)
+	// 「in 3 days」のような相対表記の数値部分
+	number = regexp.MustCompile(`^\d+$`)
+)
+
+var enWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var jaWeekdays = []struct {
+	word string
+	day  time.Weekday
+}{
+	{"日曜日", time.Sunday}, {"月曜日", time.Monday}, {"火曜日", time.Tuesday}, {"水曜日", time.Wednesday},
+	{"木曜日", time.Thursday}, {"金曜日", time.Friday}, {"土曜日", time.Saturday},
+	{"日曜", time.Sunday}, {"月曜", time.Monday}, {"火曜", time.Tuesday}, {"水曜", time.Wednesday},
+	{"木曜", time.Thursday}, {"金曜", time.Friday}, {"土曜", time.Saturday},
+}
+
+// 日本語の日付・繰り返し表現（長いものから順に照合する）
+var jaDays = []struct {
+	word   string
+	offset int
+}{
+	{"明明後日", 3}, {"しあさって", 3}, {"明後日", 2}, {"あさって", 2}, {"明日", 1}, {"あした", 1}, {"今日", 0}, {"きょう", 0}, {"今夜", 0},
+}
+
+var priorities = map[string]string{
+	"high": model.TaskPriorityHigh, "h": model.TaskPriorityHigh, "1": model.TaskPriorityHigh, "高": model.TaskPriorityHigh,
+	"medium": model.TaskPriorityMedium, "med": model.TaskPriorityMedium, "m": model.TaskPriorityMedium, "2": model.TaskPriorityMedium, "中": model.TaskPriorityMedium,
+	"low": model.TaskPriorityLow, "l": model.TaskPriorityLow, "3": model.TaskPriorityLow, "低": model.TaskPriorityLow,
+}
+
+var recurrences = map[string]string{
+	"day": model.TaskRecurrenceDaily, "week": model.TaskRecurrenceWeekly, "month": model.TaskRecurrenceMonthly, "year": model.TaskRecurrenceYearly,
+	"daily": model.TaskRecurrenceDaily, "weekly": model.TaskRecurrenceWeekly, "monthly": model.TaskRecurrenceMonthly, "yearly": model.TaskRecurrenceYearly,
+}
+
+var jaRecurrences = []struct {
+	word string
+	rule string
+}{
+	{"毎日", model.TaskRecurrenceDaily}, {"毎週", model.TaskRecurrenceWeekly}, {"毎月", model.TaskRecurrenceMonthly}, {"毎年", model.TaskRecurrenceYearly},
+}
+
+// 期限日に時刻が指定されなかった場合に使用する時刻
+const defaultDueHour, defaultDueMinute = 23, 59
+
+// 入力文字列を解析してタイトル・期限日・ラベル・優先度・繰り返しに分解する
+// now は相対日付の基準となる現在時刻（タイムゾーンも含めて使用）
+func Parse(text string, now time.Time) model.QuickAddInterpretation {
+	p := &state{now: now, res: model.QuickAddInterpretation{Labels: model.Labels{}, Tokens: []model.QuickAddToken{}}}
+	words := strings.FieldsFunc(text, unicode.IsSpace)
+	title := []string{}
+	for i := 0; i < len(words); i++ {
+		n := p.consume(words, i)
+		if n == 0 {
+			// 日本語の日付表現（例: 明日、毎週月曜、明日買い物）は単語の先頭から切り離して処理
+			if rest, ok := p.consumeJapanesePrefix(words[i]); ok {
+				if rest != "" {
+					title = append(title, rest)
+				}
+				continue
+			}
+			title = append(title, words[i])
+			continue
+		}
+		i += n - 1
+	}
+	p.res.Title = strings.Join(title, " ")
+	p.res.DueAt = p.due()
+	return p.res
+}
+
+// 解析中の状態
+type state struct {
+	now     time.Time
+	res     model.QuickAddInterpretation
+	date    *time.Time // 認識された日付（0時）
+	hour    int
+	minute  int
+	hasTime bool
+}
+
+func (p *state) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+func (p *state) setDate(d time.Time) {
+	p.date = &d
+}
+
+func (p *state) setTime(hour, minute int) {
+	p.hour, p.minute, p.hasTime = hour, minute, true
+}
+
+func (p *state) add(kind string, words ...string) {
+	p.res.Tokens = append(p.res.Tokens, model.QuickAddToken{Text: strings.Join(words, " "), Kind: kind})
+}
+
+// 日付と時刻から期限日時を組み立てる
+func (p *state) due() *time.Time {
+	if p.date == nil && !p.hasTime {
+		return nil
+	}
+	hour, minute := defaultDueHour, defaultDueMinute
+	if p.hasTime {
+		hour, minute = p.hour, p.minute
+	}
+	var d time.Time
+	if p.date != nil {
+		d = *p.date
+	} else {
+		// 時刻のみ指定された場合は、過ぎていれば翌日とする
+		d = p.today()
+		if time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, d.Location()).Before(p.now) {
+			d = d.AddDate(0, 0, 1)
+		}
+	}
+	due := time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, d.Location())
+	return &due
+}
+
+// 指定した曜日の次の日付（今日は含まない）
+func (p *state) nextWeekday(day time.Weekday) time.Time {
+	t := p.today()
+	diff := (int(day) - int(t.Weekday()) + 7) % 7
+	if diff == 0 {
+		diff = 7
+	}
+	return t.AddDate(0, 0, diff)
+}
+
+// words[i] から始まる表現を解析し、消費した単語数を返す（0なら認識できなかった）
+func (p *state) consume(words []string, i int) int {
+	w := words[i]
+	lw := strings.ToLower(w)
+	next := func(k int) string {
+		if i+k < len(words) {
+			return strings.ToLower(words[i+k])
+		}
+		return ""
+	}
+
+	// ラベル
+	if len(w) > 1 && (w[0] == '#' || strings.HasPrefix(w, "＃")) {
+		label := strings.TrimPrefix(strings.TrimPrefix(w, "#"), "＃")
+		if label != "" {
+			p.res.Labels = append(p.res.Labels, label)
+			p.add(KindLabel, w)
+			return 1
+		}
+	}
+
+	// 優先度
+	if strings.HasPrefix(w, "!") || strings.HasPrefix(w, "！") {
+		key := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(w, "!"), "！"))
+		if priority, ok := priorities[key]; ok {
+			p.res.Priority = priority
+			p.add(KindPriority, w)
+			return 1
+		}
+	}
+
+	// 繰り返し（every day / every month / every monday / daily など）
+	if lw == "every" {
+		if rule, ok := recurrences[next(1)]; ok {
+			p.res.Recurrence = rule
+			p.add(KindRecurrence, words[i:i+2]...)
+			return 2
+		}
+		if day, ok := enWeekdays[next(1)]; ok {
+			p.res.Recurrence = model.TaskRecurrenceWeekly
+			p.setDate(p.nextWeekday(day))
+			p.add(KindRecurrence, words[i:i+2]...)
+			return 2
+		}
+	}
+	if rule, ok := recurrences[lw]; ok && strings.HasSuffix(lw, "ly") {
+		p.res.Recurrence = rule
+		p.add(KindRecurrence, w)
+		return 1
+	}
+
+	// 日付
+	switch lw {
+	case "today", "tonight":
+		p.setDate(p.today())
+		if lw == "tonight" && !p.hasTime {
+			p.setTime(20, 0)
+		}
+		p.add(KindDue, w)
+		return 1
+	case "tomorrow", "tmr", "tmrw":
+		p.setDate(p.today().AddDate(0, 0, 1))
+		p.add(KindDue, w)
+		return 1
+	case "next":
+		switch next(1) {
+		case "week":
+			p.setDate(p.nextWeekday(time.Monday))
+			p.add(KindDue, words[i:i+2]...)
+			return 2
+		case "month":
+			t := p.today()
+			p.setDate(time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location()))
+			p.add(KindDue, words[i:i+2]...)
+			return 2
+		}
+		if day, ok := enWeekdays[next(1)]; ok {
+			p.setDate(p.nextWeekday(day))
+			p.add(KindDue, words[i:i+2]...)
+			return 2
+		}
+	case "in":
+		// in 3 days / in 2 weeks
+		if number.MatchString(next(1)) {
+			n, _ := strconv.Atoi(next(1))
+			switch strings.TrimSuffix(next(2), "s") {
+			case "day":
+				p.setDate(p.today().AddDate(0, 0, n))
+				p.add(KindDue, words[i:i+3]...)
+				return 3
+			case "week":
+				p.setDate(p.today().AddDate(0, 0, 7*n))
+				p.add(KindDue, words[i:i+3]...)
+				return 3
+			}
+		}
+	case "at", "on":
+		// at 9am / on friday のような前置詞は後続が日時の場合のみ消費
+		if n := p.consumeDateTime(words, i+1); n > 0 {
+			p.res.Tokens[len(p.res.Tokens)-1].Text = words[i] + " " + p.res.Tokens[len(p.res.Tokens)-1].Text
+			return n + 1
+		}
+		return 0
+	}
+	return p.consumeDateTime(words, i)
+}
+
+// 曜日・日付・時刻の単語を解析
+func (p *state) consumeDateTime(words []string, i int) int {
+	if i >= len(words) {
+		return 0
+	}
+	w := words[i]
+	lw := strings.ToLower(w)
+	if day, ok := enWeekdays[lw]; ok {
+		p.setDate(p.nextWeekday(day))
+		p.add(KindDue, w)
+		return 1
+	}
+	if m := isoDate.FindStringSubmatch(lw); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		if validDate(y, mo, d) {
+			p.setDate(time.Date(y, time.Month(mo), d, 0, 0, 0, 0, p.now.Location()))
+			p.add(KindDue, w)
+			return 1
+		}
+	}
+	if m := slashDate.FindStringSubmatch(lw); m != nil {
+		mo, _ := strconv.Atoi(m[1])
+		d, _ := strconv.Atoi(m[2])
+		t := p.today()
+		if validDate(t.Year(), mo, d) {
+			date := time.Date(t.Year(), time.Month(mo), d, 0, 0, 0, 0, t.Location())
+			// 過ぎた日付は翌年とみなす
+			if date.Before(t) {
+				date = date.AddDate(1, 0, 0)
+			}
+			p.setDate(date)
+			p.add(KindDue, w)
+			return 1
+		}
+	}
+	if m := enTime.FindStringSubmatch(lw); m != nil && (m[2] != "" || m[3] != "") {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		valid := hour < 24 && minute < 60
+		if m[3] != "" {
+			// 12時間表記は1〜12時のみ
+			valid = valid && hour >= 1 && hour <= 12
+			hour %= 12
+			if m[3] == "pm" {
+				hour += 12
+			}
+		}
+		if valid {
+			p.setTime(hour, minute)
+			p.add(KindDue, w)
+			return 1
+		}
+	}
+	return 0
+}
+
+// 単語の先頭にある日本語の日付・時刻・繰り返し表現をすべて取り除き、残りを返す
+func (p *state) consumeJapanesePrefix(w string) (string, bool) {
+	matched := false
+	for {
+		found := false
+		for _, r := range jaRecurrences {
+			if strings.HasPrefix(w, r.word) {
+				p.res.Recurrence = r.rule
+				p.add(KindRecurrence, r.word)
+				w, found = strings.TrimPrefix(w, r.word), true
+				break
+			}
+		}
+		for _, d := range jaDays {
+			if !found && strings.HasPrefix(w, d.word) {
+				p.setDate(p.today().AddDate(0, 0, d.offset))
+				if d.word == "今夜" && !p.hasTime {
+					p.setTime(20, 0)
+				}
+				p.add(KindDue, d.word)
+				w, found = strings.TrimPrefix(w, d.word), true
+			}
+		}
+		if !found && strings.HasPrefix(w, "来週") {
+			p.setDate(p.nextWeekday(time.Monday))
+			p.add(KindDue, "来週")
+			w, found = strings.TrimPrefix(w, "来週"), true
+		}
+		for _, d := range jaWeekdays {
+			if !found && strings.HasPrefix(w, d.word) {
+				p.setDate(p.nextWeekday(d.day))
+				p.add(KindDue, d.word)
+				w, found = strings.TrimPrefix(w, d.word), true
+			}
+		}
+		if m := jaTime.FindStringSubmatch(w); !found && m != nil {
+			hour, _ := strconv.Atoi(m[2])
+			minute := 0
+			if m[3] == "半" {
+				minute = 30
+			} else if m[4] != "" {
+				minute, _ = strconv.Atoi(m[4])
+			}
+			// 「3時間」「10分間」のような時間の長さは時刻として扱わない
+			valid := !strings.HasPrefix(strings.TrimPrefix(w, m[0]), "間")
+			// 午前・午後は12時まで（午前12時は0時、午後12時は12時）
+			switch m[1] {
+			case "午前":
+				valid = valid && hour <= 12
+				hour %= 12
+			case "午後":
+				valid = valid && hour <= 12
+				if hour < 12 {
+					hour += 12
+				}
+			}
+			if valid && hour < 24 && minute < 60 {
+				p.setTime(hour, minute)
+				p.add(KindDue, m[0])
+				w, found = strings.TrimPrefix(w, m[0]), true
+			}
+		}
+		if !found {
+			break
+		}
+		matched = true
+		// 「明日の」「月曜に」のような助詞を取り除く
+		for _, particle := range []string{"の", "に", "は", "、"} {
+			if strings.HasPrefix(w, particle) {
+				w = strings.TrimPrefix(w, particle)
+				break
+			}
+		}
+	}
+	return w, matched
+}
+
+// 存在する日付かどうか
+func validDate(y, m, d int) bool {
+	if m < 1 || m > 12 || d < 1 {
+		return false
+	}
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	return t.Day() == d
+}
@@ -0,0 +1,109 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+)
+
+func TestParse(t *testing.T) {
+	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
+	// 2025-01-15（水）10:00
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, jst)
+	at := func(month time.Month, day, hour, minute int) *time.Time {
+		t := time.Date(2025, month, day, hour, minute, 0, 0, jst)
+		return &t
+	}
+
+	tests := []struct {
+		input      string
+		title      string
+		due        *time.Time
+		labels     model.Labels
+		priority   string
+		recurrence string
+	}{
+		// 英語
+		{"Buy milk tomorrow 9am #home !high", "Buy milk", at(1, 16, 9, 0), model.Labels{"home"}, model.TaskPriorityHigh, ""},
+		{"Pay rent every month", "Pay rent", nil, model.Labels{}, "", model.TaskRecurrenceMonthly},
+		{"Standup every monday 9:30am", "Standup", at(1, 20, 9, 30), model.Labels{}, "", model.TaskRecurrenceWeekly},
+		{"daily standup !m", "standup", nil, model.Labels{}, model.TaskPriorityMedium, model.TaskRecurrenceDaily},
+		{"report next friday", "report", at(1, 17, 23, 59), model.Labels{}, "", ""},
+		{"plan next week", "plan", at(1, 20, 23, 59), model.Labels{}, "", ""},
+		{"budget next month", "budget", at(2, 1, 23, 59), model.Labels{}, "", ""},
+		{"review on wed", "review", at(1, 22, 23, 59), model.Labels{}, "", ""},
+		{"gym in 2 weeks", "gym", at(1, 29, 23, 59), model.Labels{}, "", ""},
+		{"dentist in 1 day", "dentist", at(1, 16, 23, 59), model.Labels{}, "", ""},
+		{"dinner tonight", "dinner", at(1, 15, 20, 0), model.Labels{}, "", ""},
+		{"lunch 12:30", "lunch", at(1, 15, 12, 30), model.Labels{}, "", ""},
+		// 過ぎた時刻のみの指定は翌日
+		{"call mom at 8am", "call mom", at(1, 16, 8, 0), model.Labels{}, "", ""},
+		{"tax 2025-03-15", "tax", at(3, 15, 23, 59), model.Labels{}, "", ""},
+		// 過ぎた月/日は翌年
+		{"trip 1/10", "trip", func() *time.Time { t := time.Date(2026, 1, 10, 23, 59, 0, 0, jst); return &t }(), model.Labels{}, "", ""},
+		{"#work #urgent fix bug", "fix bug", nil, model.Labels{"work", "urgent"}, "", ""},
+		// 認識できない表現はタイトルに残す
+		{"file 2025-02-30", "file 2025-02-30", nil, model.Labels{}, "", ""},
+		{"meet at the office", "meet at the office", nil, model.Labels{}, "", ""},
+		{"13pm sync", "13pm sync", nil, model.Labels{}, "", ""},
+		{"# !urgent every", "# !urgent every", nil, model.Labels{}, "", ""},
+		{"room 101", "room 101", nil, model.Labels{}, "", ""},
+		// 日本語
+		{"明日の15時に買い物 ＃家", "買い物", at(1, 16, 15, 0), model.Labels{"家"}, "", ""},
+		{"毎週月曜 ゴミ出し", "ゴミ出し", at(1, 20, 23, 59), model.Labels{}, "", model.TaskRecurrenceWeekly},
+		{"午後3時半 会議 ！高", "会議", at(1, 15, 15, 30), model.Labels{}, model.TaskPriorityHigh, ""},
+		{"あさって 美容院", "美容院", at(1, 17, 23, 59), model.Labels{}, "", ""},
+		{"来週 企画書", "企画書", at(1, 20, 23, 59), model.Labels{}, "", ""},
+		{"今夜 映画", "映画", at(1, 15, 20, 0), model.Labels{}, "", ""},
+		{"毎月 家賃", "家賃", nil, model.Labels{}, "", model.TaskRecurrenceMonthly},
+		{"金曜日に提出", "提出", at(1, 17, 23, 59), model.Labels{}, "", ""},
+		{"明日は9時10分", "", at(1, 16, 9, 10), model.Labels{}, "", ""},
+		{"25時 作業", "25時 作業", nil, model.Labels{}, "", ""},
+		{"午前9時 朝会", "朝会", at(1, 16, 9, 0), model.Labels{}, "", ""},
+		{"午前12時 出発", "出発", at(1, 16, 0, 0), model.Labels{}, "", ""},
+		{"午後12時 昼食", "昼食", at(1, 15, 12, 0), model.Labels{}, "", ""},
+		{"午前13時 作業", "午前13時 作業", nil, model.Labels{}, "", ""},
+		{"午後13時 会議", "午後13時 会議", nil, model.Labels{}, "", ""},
+		// 時間の長さは時刻として扱わない
+		{"3時間勉強", "3時間勉強", nil, model.Labels{}, "", ""},
+		{"明日 9時10分間 休憩", "9時10分間 休憩", at(1, 16, 23, 59), model.Labels{}, "", ""},
+		{"", "", nil, model.Labels{}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Parse(tt.input, now)
+			if got.Title != tt.title {
+				t.Errorf("Title = %q, want %q", got.Title, tt.title)
+			}
+			if (got.DueAt == nil) != (tt.due == nil) || got.DueAt != nil && !got.DueAt.Equal(*tt.due) {
+				t.Errorf("DueAt = %v, want %v", got.DueAt, tt.due)
+			}
+			if !reflect.DeepEqual(got.Labels, tt.labels) {
+				t.Errorf("Labels = %v, want %v", got.Labels, tt.labels)
+			}
+			if got.Priority != tt.priority {
+				t.Errorf("Priority = %q, want %q", got.Priority, tt.priority)
+			}
+			if got.Recurrence != tt.recurrence {
+				t.Errorf("Recurrence = %q, want %q", got.Recurrence, tt.recurrence)
+			}
+		})
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	got := Parse("Pay rent at 9am #home !high every month 明日", now)
+	want := []model.QuickAddToken{
+		{Text: "at 9am", Kind: KindDue},
+		{Text: "#home", Kind: KindLabel},
+		{Text: "!high", Kind: KindPriority},
+		{Text: "every month", Kind: KindRecurrence},
+		{Text: "明日", Kind: KindDue},
+	}
+	if !reflect.DeepEqual(got.Tokens, want) {
+		t.Errorf("Tokens = %+v, want %+v", got.Tokens, want)
+	}
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// チェックリスト項目に関するデータベース操作を定義
+// 項目を変更する操作は、タスクを更新できるユーザー（作成者・承諾済みの担当者）のみ実行でき、
+// 同じトランザクション内でタスクの進捗・更新日時・change_seq も更新する
+type IChecklistRepository interface {
+	GetChecklistItems(items *[]model.ChecklistItem, userId uint, taskId uint) error                     // タスクのチェックリストを表示順に取得
+	CreateChecklistItem(item *model.ChecklistItem, userId uint, taskId uint) error                      // 項目を末尾に追加
+	UpdateChecklistItem(item *model.ChecklistItem, userId uint, taskId uint, itemId uint) error         // 項目のテキストとチェック状態を更新
+	ToggleChecklistItem(item *model.ChecklistItem, userId uint, taskId uint, itemId uint) error         // 項目のチェック状態を切り替える
+	ReorderChecklistItems(items *[]model.ChecklistItem, userId uint, taskId uint, itemIds []uint) error // 項目を指定された順序に並べ替える
+	DeleteChecklistItem(userId uint, taskId uint, itemId uint) error                                    // 項目を削除
+	ConvertChecklistItem(subtask *model.Task, userId uint, taskId uint, itemId uint) error              // 項目をサブタスクに変換（項目は削除される）
+}
+
+type checklistRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewChecklistRepository(db *gorm.DB) IChecklistRepository {
+	return &checklistRepository{db}
+}
+
+// タスクのチェックリストを表示順に取得（タスクを閲覧できるユーザーのみ）
+func (cr *checklistRepository) GetChecklistItems(items *[]model.ChecklistItem, userId uint, taskId uint) error {
+	if err := cr.db.Joins("JOIN tasks ON tasks.id = checklist_items.task_id").Scopes(readableBy(userId)).
+		Where("checklist_items.task_id=?", taskId).Order("checklist_items.position, checklist_items.id").Find(items).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 項目を末尾に追加
+func (cr *checklistRepository) CreateChecklistItem(item *model.ChecklistItem, userId uint, taskId uint) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWritableTask(tx, userId, taskId); err != nil {
+			return err
+		}
+		var position int
+		if err := tx.Model(&model.ChecklistItem{}).Where("task_id=?", taskId).
+			Select("COALESCE(MAX(position) + 1, 0)").Scan(&position).Error; err != nil {
+			return err
+		}
+		item.TaskId = taskId
+		item.Position = position
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		return touchChecklistTask(tx, taskId)
+	})
+}
+
+// 項目のテキストとチェック状態を更新
+func (cr *checklistRepository) UpdateChecklistItem(item *model.ChecklistItem, userId uint, taskId uint, itemId uint) error {
+	return cr.updateItem(item, userId, taskId, itemId, map[string]interface{}{
+		"text":    item.Text,
+		"checked": item.Checked,
+	})
+}
+
+// 項目のチェック状態を切り替える
+func (cr *checklistRepository) ToggleChecklistItem(item *model.ChecklistItem, userId uint, taskId uint, itemId uint) error {
+	return cr.updateItem(item, userId, taskId, itemId, map[string]interface{}{
+		"checked": gorm.Expr("NOT checked"),
+	})
+}
+
+// タスクをロックしてから項目を更新し、タスクの進捗を更新する
+func (cr *checklistRepository) updateItem(item *model.ChecklistItem, userId uint, taskId uint, itemId uint, values map[string]interface{}) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWritableTask(tx, userId, taskId); err != nil {
+			return err
+		}
+		result := tx.Model(item).Clauses(clause.Returning{}).Where("id=? AND task_id=?", itemId, taskId).Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		// 更新された行数が0の場合、項目が存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return touchChecklistTask(tx, taskId)
+	})
+}
+
+// 項目を指定された順序に並べ替える
+// itemIds にはタスクのすべての項目のIDを重複なく指定する必要がある
+func (cr *checklistRepository) ReorderChecklistItems(items *[]model.ChecklistItem, userId uint, taskId uint, itemIds []uint) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWritableTask(tx, userId, taskId); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&model.ChecklistItem{}).Where("task_id=?", taskId).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(itemIds) {
+			return fmt.Errorf("item_ids must contain all checklist items of the task")
+		}
+		for position, id := range itemIds {
+			result := tx.Model(&model.ChecklistItem{}).Where("id=? AND task_id=?", id, taskId).Update("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected < 1 {
+				return ErrNotFound
+			}
+		}
+		if err := touchChecklistTask(tx, taskId); err != nil {
+			return err
+		}
+		return tx.Where("task_id=?", taskId).Order("position, id").Find(items).Error
+	})
+}
+
+// 項目を削除
+func (cr *checklistRepository) DeleteChecklistItem(userId uint, taskId uint, itemId uint) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWritableTask(tx, userId, taskId); err != nil {
+			return err
+		}
+		result := tx.Where("id=? AND task_id=?", itemId, taskId).Delete(&model.ChecklistItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 削除された行数が0の場合、項目が存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return touchChecklistTask(tx, taskId)
+	})
+}
+
+// 項目をサブタスクに変換
+// サブタスクは親タスクの作成者のものとして作成し、チェック済みの項目は完了したタスクになる
+// サブタスクのタイトルなど、ステータス以外の項目は subtask に設定された値を使う
+func (cr *checklistRepository) ConvertChecklistItem(subtask *model.Task, userId uint, taskId uint, itemId uint) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		parent := model.Task{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(writableBy(userId)).First(&parent, taskId).Error; err != nil {
+			return err
+		}
+		item := model.ChecklistItem{}
+		result := tx.Clauses(clause.Returning{}).Where("id=? AND task_id=?", itemId, taskId).Delete(&item)
+		if result.Error != nil {
+			return result.Error
+		}
+		// 削除された行数が0の場合、項目が存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+
+		subtask.ID = 0
+		subtask.UserId = parent.UserId
+		subtask.Status = model.TaskStatusOpen
+		subtask.ParentId = &parent.ID
+		if item.Checked {
+			now := time.Now()
+			subtask.Status = model.TaskStatusDone
+			subtask.CompletedAt = &now
+		}
+		if err := tx.Create(subtask).Error; err != nil {
+			return err
+		}
+		return touchChecklistTask(tx, taskId)
+	})
+}
+
+// 更新できるタスクを行ロックする（同じタスクの項目に対する同時変更を直列化する）
+func lockWritableTask(tx *gorm.DB, userId uint, taskId uint) error {
+	task := model.Task{}
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(writableBy(userId)).Select("id").First(&task, taskId).Error
+}
+
+// チェックリストの変更をタスクに反映する（進捗を数え直し、更新日時と change_seq を更新）
+func touchChecklistTask(tx *gorm.DB, taskId uint) error {
+	return tx.Model(&model.Task{}).Where("id=?", taskId).Updates(map[string]interface{}{
+		"checklist_total":   gorm.Expr("(SELECT COUNT(*) FROM checklist_items WHERE task_id = ?)", taskId),
+		"checklist_checked": gorm.Expr("(SELECT COUNT(*) FROM checklist_items WHERE task_id = ? AND checked)", taskId),
+		"updated_at":        time.Now(),
+		"change_seq":        nextChangeSeq(),
+	}).Error
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+)
+
+// コメントに関するデータベース操作を定義
+// タスクへのアクセス権の確認はユースケース層で行う
+type ICommentRepository interface {
+	GetComments(comments *[]model.Comment, taskId uint) error // タスクのコメントを古い順に取得
+	CreateComment(comment *model.Comment) error               // コメントを作成
+	DeleteComment(userId uint, commentId uint) error          // 自分のコメントを削除
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewCommentRepository(db *gorm.DB) ICommentRepository {
+	return &commentRepository{db}
+}
+
+// タスクのコメントを古い順に取得
+func (cr *commentRepository) GetComments(comments *[]model.Comment, taskId uint) error {
+	if err := cr.db.Where("task_id=?", taskId).Order("created_at").Find(comments).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// コメントを作成
+func (cr *commentRepository) CreateComment(comment *model.Comment) error {
+	if err := cr.db.Create(comment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 自分のコメントを削除
+// 差分同期のため、コメントを閲覧できていたユーザーごとに削除を記録する
+func (cr *commentRepository) DeleteComment(userId uint, commentId uint) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := createCommentTombstones(tx, userId, commentId); err != nil {
+			return err
+		}
+		result := tx.Where("id=? AND user_id=?", commentId, userId).Delete(&model.Comment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 削除された行数が0の場合、コメントが存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// メール配信設定に関するデータベース操作を定義
+type IEmailPreferenceRepository interface {
+	GetEmailPreference(pref *model.EmailPreference, userId uint) error             // 配信設定を取得（未作成の場合はデフォルト値で作成）
+	UpdateEmailPreference(pref *model.EmailPreference, userId uint) error          // 配信設定を更新
+	Unsubscribe(pref *model.EmailPreference, token string, emailType string) error // 配信停止トークンで指定された種類のメールを停止
+	GetDigestSubscribers(prefs *[]model.EmailPreference) error                     // 日次ダイジェストを受け取るユーザーの設定を取得
+	ClaimDigest(claimed *bool, userId uint, date time.Time) error                  // 指定日のダイジェスト送信権を取得（送信済みならfalse）
+}
+
+type emailPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewEmailPreferenceRepository(db *gorm.DB) IEmailPreferenceRepository {
+	return &emailPreferenceRepository{db}
+}
+
+// 配信設定を取得（未作成の場合はデフォルト値で作成）
+func (er *emailPreferenceRepository) GetEmailPreference(pref *model.EmailPreference, userId uint) error {
+	token, err := newUnsubscribeToken()
+	if err != nil {
+		return err
+	}
+	// 同時に作成された場合も1行だけになるよう、競合時は何もしない
+	if err := er.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.EmailPreference{UserId: userId, UnsubscribeToken: token}).Error; err != nil {
+		return err
+	}
+	if err := er.db.Where("user_id=?", userId).First(pref).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 配信設定を更新
+func (er *emailPreferenceRepository) UpdateEmailPreference(pref *model.EmailPreference, userId uint) error {
+	// falseを更新できるようにmapで指定する
+	result := er.db.Model(pref).Clauses(clause.Returning{}).Where("user_id=?", userId).Updates(map[string]interface{}{
+		"language":     pref.Language,
+		"due_soon":     pref.DueSoon,
+		"daily_digest": pref.DailyDigest,
+		"digest_hour":  pref.DigestHour,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、設定が存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 配信停止トークンで指定された種類のメールを停止
+func (er *emailPreferenceRepository) Unsubscribe(pref *model.EmailPreference, token string, emailType string) error {
+	var column string
+	switch emailType {
+	case model.EmailTypeDueSoon:
+		column = "due_soon"
+	case model.EmailTypeDailyDigest:
+		column = "daily_digest"
+	default:
+		return fmt.Errorf("unknown email type: %s", emailType)
+	}
+	result := er.db.Model(pref).Clauses(clause.Returning{}).Where("unsubscribe_token=?", token).Update(column, false)
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、トークンが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 日次ダイジェストを受け取るユーザーの設定を取得
+func (er *emailPreferenceRepository) GetDigestSubscribers(prefs *[]model.EmailPreference) error {
+	if err := er.db.Joins("User").Where("daily_digest=?", true).Find(prefs).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 指定日のダイジェスト送信権を取得
+// 複数のインスタンスから同時に実行されても、1日に1通だけ送信されるように条件付きで更新する
+func (er *emailPreferenceRepository) ClaimDigest(claimed *bool, userId uint, date time.Time) error {
+	day := date.Format("2006-01-02")
+	result := er.db.Model(&model.EmailPreference{}).
+		Where("user_id=? AND (last_digest_on IS NULL OR last_digest_on < CAST(? AS date))", userId, day).
+		Update("last_digest_on", gorm.Expr("CAST(? AS date)", day))
+	if result.Error != nil {
+		return result.Error
+	}
+	*claimed = result.RowsAffected > 0
+	return nil
+}
+
+// 推測されにくい配信停止トークンを生成
+func newUnsubscribeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "gorm.io/gorm"
+
+// 対象のレコードが存在しない（またはユーザーが操作できない）場合のエラー
+// First などが返す gorm.ErrRecordNotFound と同じ値なので、どちらも errors.Is で判定できる
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// 一意制約に違反した場合のエラー（gorm.Config の TranslateError でデータベースのエラーから変換される）
+var ErrDuplicate = gorm.ErrDuplicatedKey
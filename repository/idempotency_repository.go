@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Idempotency-Key に関するデータベース操作を定義
+type IIdempotencyRepository interface {
+	CreateIdempotencyKey(created *bool, key *model.IdempotencyKey) error                    // キーを処理中として保存（すでに存在する場合は created が false）
+	GetIdempotencyKey(key *model.IdempotencyKey, userId uint, k string, route string) error // キーを取得
+	SaveResponse(id uint, status int, contentType string, body []byte) error                // 最初のレスポンスを保存
+	DeleteIdempotencyKey(id uint) error                                                     // キーを削除（処理が失敗した場合や期限切れの場合）
+	DeleteExpiredIdempotencyKeys(now time.Time) error                                       // 有効期限が切れたキーをまとめて削除
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewIdempotencyRepository(db *gorm.DB) IIdempotencyRepository {
+	return &idempotencyRepository{db}
+}
+
+// キーを処理中として保存
+// 同じキーで同時にリクエストされた場合も、一意制約により1件だけが保存される
+func (ir *idempotencyRepository) CreateIdempotencyKey(created *bool, key *model.IdempotencyKey) error {
+	result := ir.db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		return result.Error
+	}
+	*created = result.RowsAffected > 0
+	return nil
+}
+
+// キーを取得
+func (ir *idempotencyRepository) GetIdempotencyKey(key *model.IdempotencyKey, userId uint, k string, route string) error {
+	if err := ir.db.Where("user_id=? AND key=? AND route=?", userId, k, route).First(key).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 最初のレスポンスを保存
+func (ir *idempotencyRepository) SaveResponse(id uint, status int, contentType string, body []byte) error {
+	if err := ir.db.Model(&model.IdempotencyKey{}).Where("id=?", id).Updates(map[string]interface{}{
+		"response_status": status,
+		"content_type":    contentType,
+		"response_body":   body,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// キーを削除
+func (ir *idempotencyRepository) DeleteIdempotencyKey(id uint) error {
+	if err := ir.db.Delete(&model.IdempotencyKey{}, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 有効期限が切れたキーをまとめて削除
+func (ir *idempotencyRepository) DeleteExpiredIdempotencyKeys(now time.Time) error {
+	if err := ir.db.Where("expires_at < ?", now).Delete(&model.IdempotencyKey{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+)
+
+// 受信箱に表示する通知の最大件数
+const notificationListLimit = 100
+
+// 通知に関するデータベース操作を定義
+type INotificationRepository interface {
+	GetNotifications(notifications *[]model.Notification, userId uint, unreadOnly bool) error // 通知を新しい順に取得
+	CountUnread(count *int64, userId uint) error                                              // 未読件数を取得
+	CreateNotifications(notifications *[]model.Notification) error                            // 通知をまとめて作成
+	MarkAsRead(userId uint, notificationId uint) error                                        // 通知を既読にする
+	MarkAllAsRead(userId uint) error                                                          // すべての通知を既読にする
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewNotificationRepository(db *gorm.DB) INotificationRepository {
+	return &notificationRepository{db}
+}
+
+// 通知を新しい順に取得
+func (nr *notificationRepository) GetNotifications(notifications *[]model.Notification, userId uint, unreadOnly bool) error {
+	tx := nr.db.Where("user_id=?", userId)
+	if unreadOnly {
+		tx = tx.Where("read_at IS NULL")
+	}
+	if err := tx.Order("created_at DESC, id DESC").Limit(notificationListLimit).Find(notifications).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 未読件数を取得
+func (nr *notificationRepository) CountUnread(count *int64, userId uint) error {
+	if err := nr.db.Model(&model.Notification{}).Where("user_id=? AND read_at IS NULL", userId).Count(count).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 通知をまとめて作成
+func (nr *notificationRepository) CreateNotifications(notifications *[]model.Notification) error {
+	if len(*notifications) == 0 {
+		return nil
+	}
+	if err := nr.db.Create(notifications).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 通知を既読にする（既読済みの場合は何もしない）
+func (nr *notificationRepository) MarkAsRead(userId uint, notificationId uint) error {
+	result := nr.db.Model(&model.Notification{}).Where("id=? AND user_id=?", notificationId, userId).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、通知が存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// すべての未読の通知を既読にする
+func (nr *notificationRepository) MarkAllAsRead(userId uint) error {
+	if err := nr.db.Model(&model.Notification{}).Where("user_id=? AND read_at IS NULL", userId).
+		Update("read_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
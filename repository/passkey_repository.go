@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// パスキーと、WebAuthn の登録・認証の途中の状態に関するデータベース操作を定義
+type IPasskeyRepository interface {
+	GetPasskeys(passkeys *[]model.Passkey, userId uint) error                                                     // ユーザーのパスキーを登録順に取得
+	CountPasskeys(count *int64, userId uint) error                                                                // ユーザーのパスキーの数を取得
+	CreatePasskey(passkey *model.Passkey) error                                                                   // パスキーを登録
+	UpdatePasskeyUsage(passkey *model.Passkey, now time.Time) error                                               // 認証後に署名カウンターと同期の状態、最終使用日時を更新
+	DeletePasskey(userId uint, passkeyId uint) error                                                              // パスキーを削除
+	CreateWebAuthnSession(session *model.WebAuthnSession) error                                                   // 登録・認証の開始時の状態を保存
+	ConsumeWebAuthnSession(session *model.WebAuthnSession, challenge string, purpose string, now time.Time) error // 有効期限内の状態を取り出して削除
+	DeleteExpiredWebAuthnSessions(now time.Time) error                                                            // 有効期限が切れた状態をまとめて削除
+}
+
+type passkeyRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewPasskeyRepository(db *gorm.DB) IPasskeyRepository {
+	return &passkeyRepository{db}
+}
+
+// ユーザーのパスキーを登録順に取得
+func (kr *passkeyRepository) GetPasskeys(passkeys *[]model.Passkey, userId uint) error {
+	if err := kr.db.Where("user_id=?", userId).Order("created_at").Find(passkeys).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ユーザーが登録しているパスキーの数を取得
+func (kr *passkeyRepository) CountPasskeys(count *int64, userId uint) error {
+	if err := kr.db.Model(&model.Passkey{}).Where("user_id=?", userId).Count(count).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// パスキーを登録（同じクレデンシャルIDが登録済みの場合は ErrDuplicate）
+func (kr *passkeyRepository) CreatePasskey(passkey *model.Passkey) error {
+	if err := kr.db.Create(passkey).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 認証後に署名カウンターと同期の状態、最終使用日時を更新
+func (kr *passkeyRepository) UpdatePasskeyUsage(passkey *model.Passkey, now time.Time) error {
+	result := kr.db.Model(&model.Passkey{}).Where("id=?", passkey.ID).Updates(map[string]interface{}{
+		"sign_count":   passkey.SignCount,
+		"backup_state": passkey.BackupState,
+		"last_used_at": now,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ユーザーのパスキーを削除
+func (kr *passkeyRepository) DeletePasskey(userId uint, passkeyId uint) error {
+	result := kr.db.Where("id=? AND user_id=?", passkeyId, userId).Delete(&model.Passkey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 登録・認証の開始時の状態を保存
+func (kr *passkeyRepository) CreateWebAuthnSession(session *model.WebAuthnSession) error {
+	if err := kr.db.Create(session).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// チャレンジと用途が一致する有効期限内の状態を取り出して削除する（同じチャレンジは一度しか使えない）
+// 存在しない、期限切れ、または使用済みの場合は ErrNotFound を返す
+func (kr *passkeyRepository) ConsumeWebAuthnSession(session *model.WebAuthnSession, challenge string, purpose string, now time.Time) error {
+	result := kr.db.Clauses(clause.Returning{}).
+		Where("challenge=? AND purpose=? AND expires_at > ?", challenge, purpose, now).
+		Delete(session)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 有効期限が切れた状態をまとめて削除（開始したまま完了しなかったもの）
+func (kr *passkeyRepository) DeleteExpiredWebAuthnSessions(now time.Time) error {
+	if err := kr.db.Where("expires_at < ?", now).Delete(&model.WebAuthnSession{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
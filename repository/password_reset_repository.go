@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// パスワードの再設定トークンに関するデータベース操作を定義
+type IPasswordResetRepository interface {
+	CreatePasswordResetToken(token *model.PasswordResetToken, sentBefore time.Time) error             // 以前のトークンを無効にして新しいトークンを作成
+	ConsumePasswordResetToken(token *model.PasswordResetToken, tokenHash string, now time.Time) error // 未使用で有効期限内のトークンを使用済みにする
+	DeleteExpiredPasswordResetTokens(now time.Time) error                                             // 有効期限が切れたトークンをまとめて削除
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewPasswordResetRepository(db *gorm.DB) IPasswordResetRepository {
+	return &passwordResetRepository{db}
+}
+
+// ユーザーの未使用のトークンを削除して、新しいトークンを作成
+// sentBefore 以降に作成したトークンがある（送信の間隔が短すぎる）場合は作成せず ErrDuplicate を返す
+func (pr *passwordResetRepository) CreatePasswordResetToken(token *model.PasswordResetToken, sentBefore time.Time) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		// 同じユーザーの同時のリクエストを直列化する
+		if err := tx.Model(&model.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id=?", token.UserId).Select("id").Take(&model.User{}).Error; err != nil {
+			return err
+		}
+		var recent int64
+		if err := tx.Model(&model.PasswordResetToken{}).Where("user_id=? AND created_at >= ?", token.UserId, sentBefore).
+			Count(&recent).Error; err != nil {
+			return err
+		}
+		if recent > 0 {
+			return ErrDuplicate
+		}
+		if err := tx.Where("user_id=? AND used_at IS NULL", token.UserId).Delete(&model.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// 未使用で有効期限内のトークンを使用済みにし、token にその内容を読み込む
+// トークンが存在しない、使用済み、または期限切れの場合は ErrNotFound
+func (pr *passwordResetRepository) ConsumePasswordResetToken(token *model.PasswordResetToken, tokenHash string, now time.Time) error {
+	result := pr.db.Model(token).Clauses(clause.Returning{}).
+		Where("token_hash=? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 有効期限が切れたトークンをまとめて削除
+func (pr *passwordResetRepository) DeleteExpiredPasswordResetTokens(now time.Time) error {
+	if err := pr.db.Where("expires_at < ?", now).Delete(&model.PasswordResetToken{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
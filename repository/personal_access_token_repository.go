@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 最終使用日時を更新する間隔（リクエストのたびに書き込まないようにする）
+const personalAccessTokenTouchInterval = time.Minute
+
+// パーソナルアクセストークンに関するデータベース操作を定義
+type IPersonalAccessTokenRepository interface {
+	GetPersonalAccessTokens(tokens *[]model.PersonalAccessToken, userId uint) error               // ユーザーのトークンをすべて取得
+	GetPersonalAccessTokenById(token *model.PersonalAccessToken, userId uint, tokenId uint) error // 特定のトークンを取得
+	GetPersonalAccessTokenByHash(token *model.PersonalAccessToken, tokenHash string) error        // ハッシュからトークンを取得（認証用）
+	CreatePersonalAccessToken(token *model.PersonalAccessToken) error                             // トークンを作成
+	UpdatePersonalAccessToken(token *model.PersonalAccessToken, userId uint, tokenId uint) error  // トークンの名前とスコープを更新
+	TouchPersonalAccessToken(tokenId uint, now time.Time) error                                   // 最終使用日時を更新
+	DeletePersonalAccessToken(userId uint, tokenId uint) error                                    // トークンを削除
+}
+
+type personalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewPersonalAccessTokenRepository(db *gorm.DB) IPersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db}
+}
+
+// ユーザーのトークンを作成日時の新しい順にすべて取得
+func (pr *personalAccessTokenRepository) GetPersonalAccessTokens(tokens *[]model.PersonalAccessToken, userId uint) error {
+	if err := pr.db.Where("user_id=?", userId).Order("created_at DESC, id DESC").Find(tokens).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 特定のトークンを取得
+func (pr *personalAccessTokenRepository) GetPersonalAccessTokenById(token *model.PersonalAccessToken, userId uint, tokenId uint) error {
+	if err := pr.db.Where("user_id=?", userId).First(token, tokenId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ハッシュからトークンを取得（認証用）
+func (pr *personalAccessTokenRepository) GetPersonalAccessTokenByHash(token *model.PersonalAccessToken, tokenHash string) error {
+	if err := pr.db.Where("token_hash=?", tokenHash).First(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// トークンを作成
+func (pr *personalAccessTokenRepository) CreatePersonalAccessToken(token *model.PersonalAccessToken) error {
+	if err := pr.db.Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// トークンの名前とスコープを更新（トークン自体と有効期限は変更できない）
+func (pr *personalAccessTokenRepository) UpdatePersonalAccessToken(token *model.PersonalAccessToken, userId uint, tokenId uint) error {
+	result := pr.db.Model(token).Clauses(clause.Returning{}).Where("id=? AND user_id=?", tokenId, userId).Updates(map[string]interface{}{
+		"name":   token.Name,
+		"scopes": token.Scopes,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、トークンが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 最終使用日時を更新
+// 前回の更新から personalAccessTokenTouchInterval 経っていない場合は何もしない
+func (pr *personalAccessTokenRepository) TouchPersonalAccessToken(tokenId uint, now time.Time) error {
+	if err := pr.db.Model(&model.PersonalAccessToken{}).
+		Where("id=? AND (last_used_at IS NULL OR last_used_at < ?)", tokenId, now.Add(-personalAccessTokenTouchInterval)).
+		Update("last_used_at", now).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// トークンを削除（以降そのトークンでのリクエストは 401 になる）
+func (pr *personalAccessTokenRepository) DeletePersonalAccessToken(userId uint, tokenId uint) error {
+	result := pr.db.Where("id=? AND user_id=?", tokenId, userId).Delete(&model.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 削除された行数が0の場合、トークンが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// プロジェクトとカスタムフィールドの定義に関するデータベース操作を定義
+type IProjectRepository interface {
+	GetAllProjects(projects *[]model.Project, userId uint) error                                  // ユーザーのプロジェクトをすべて取得
+	GetProjectById(project *model.Project, userId uint, projectId uint) error                     // 特定のプロジェクトを取得
+	CreateProject(project *model.Project) error                                                   // プロジェクトを作成
+	UpdateProject(project *model.Project, userId uint, projectId uint) error                      // プロジェクトを更新
+	DeleteProject(userId uint, projectId uint) error                                              // プロジェクトを削除（タスクはプロジェクトから外れる）
+	GetCustomFields(fields *[]model.CustomField, userId uint, projectId uint) error               // プロジェクトのカスタムフィールドを表示順に取得
+	GetCustomFieldById(field *model.CustomField, userId uint, projectId uint, fieldId uint) error // 特定のカスタムフィールドを取得
+	CreateCustomField(field *model.CustomField) error                                             // カスタムフィールドを末尾に追加
+	UpdateCustomField(field *model.CustomField, userId uint, projectId uint, fieldId uint) error  // カスタムフィールドの名前・選択肢・必須かどうかを更新
+	DeleteCustomField(userId uint, projectId uint, fieldId uint) error                            // カスタムフィールドを削除（タスクの値も削除される）
+}
+
+type projectRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewProjectRepository(db *gorm.DB) IProjectRepository {
+	return &projectRepository{db}
+}
+
+// ユーザーのプロジェクトをすべて取得
+func (pr *projectRepository) GetAllProjects(projects *[]model.Project, userId uint) error {
+	if err := pr.db.Where("user_id=?", userId).Order("name, id").Find(projects).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 特定のプロジェクトを取得
+func (pr *projectRepository) GetProjectById(project *model.Project, userId uint, projectId uint) error {
+	if err := pr.db.Where("user_id=?", userId).First(project, projectId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// プロジェクトを作成
+func (pr *projectRepository) CreateProject(project *model.Project) error {
+	if err := pr.db.Create(project).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// プロジェクトを更新
+func (pr *projectRepository) UpdateProject(project *model.Project, userId uint, projectId uint) error {
+	result := pr.db.Model(project).Clauses(clause.Returning{}).Where("id=? AND user_id=?", projectId, userId).Updates(map[string]interface{}{
+		"name": project.Name,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、プロジェクトが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// プロジェクトを削除
+// プロジェクトのタスクは削除せず、プロジェクトから外してカスタムフィールドの値を消去する
+func (pr *projectRepository) DeleteProject(userId uint, projectId uint) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Task{}).Where("project_id IN (?)",
+			tx.Model(&model.Project{}).Select("id").Where("id=? AND user_id=?", projectId, userId)).
+			Updates(map[string]interface{}{
+				"project_id":    nil,
+				"custom_fields": model.CustomFieldValues{},
+				"updated_at":    time.Now(),
+				"change_seq":    nextChangeSeq(),
+			}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id=? AND user_id=?", projectId, userId).Delete(&model.Project{})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 削除された行数が0の場合、プロジェクトが存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// プロジェクトのカスタムフィールドを表示順に取得
+func (pr *projectRepository) GetCustomFields(fields *[]model.CustomField, userId uint, projectId uint) error {
+	if err := pr.db.Scopes(ownProjectFields(userId, projectId)).Order("position, id").Find(fields).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 特定のカスタムフィールドを取得
+func (pr *projectRepository) GetCustomFieldById(field *model.CustomField, userId uint, projectId uint, fieldId uint) error {
+	if err := pr.db.Scopes(ownProjectFields(userId, projectId)).First(field, fieldId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// カスタムフィールドを末尾に追加（field.ProjectId のプロジェクトは呼び出し側で所有者を確認する）
+func (pr *projectRepository) CreateCustomField(field *model.CustomField) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		// 同じプロジェクトへの同時追加で表示順が重複しないよう、プロジェクトを行ロックする
+		project := model.Project{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&project, field.ProjectId).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.CustomField{}).Where("project_id=?", field.ProjectId).
+			Select("COALESCE(MAX(position) + 1, 0)").Scan(&field.Position).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(field).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// カスタムフィールドの名前・選択肢・必須かどうかを更新（キーと種類は変更しない）
+func (pr *projectRepository) UpdateCustomField(field *model.CustomField, userId uint, projectId uint, fieldId uint) error {
+	result := pr.db.Model(field).Clauses(clause.Returning{}).Where("id=?", fieldId).Scopes(ownProjectFields(userId, projectId)).
+		Updates(map[string]interface{}{
+			"name":     field.Name,
+			"options":  field.Options,
+			"required": field.Required,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、フィールドが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// カスタムフィールドを削除し、プロジェクトのタスクからそのフィールドの値を取り除く
+func (pr *projectRepository) DeleteCustomField(userId uint, projectId uint, fieldId uint) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		field := model.CustomField{}
+		result := tx.Clauses(clause.Returning{}).Where("id=?", fieldId).Scopes(ownProjectFields(userId, projectId)).Delete(&field)
+		if result.Error != nil {
+			return result.Error
+		}
+		// 削除された行数が0の場合、フィールドが存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return tx.Model(&model.Task{}).
+			Where("project_id=? AND custom_fields -> CAST(? AS text) IS NOT NULL", projectId, field.Key).
+			Updates(map[string]interface{}{
+				"custom_fields": gorm.Expr("custom_fields - CAST(? AS text)", field.Key),
+				"updated_at":    time.Now(),
+				"change_seq":    nextChangeSeq(),
+			}).Error
+	})
+}
+
+// 自分のプロジェクトに定義されたカスタムフィールドに絞り込む
+func ownProjectFields(userId uint, projectId uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("project_id=? AND project_id IN (?)", projectId,
+			db.Session(&gorm.Session{NewDB: true}).Model(&model.Project{}).Select("id").Where("user_id=?", userId))
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+)
+
+// 2段階認証のリカバリーコードに関するデータベース操作を定義
+type IRecoveryCodeRepository interface {
+	ReplaceRecoveryCodes(userId uint, codes []model.RecoveryCode) error // ユーザーのリカバリーコードを新しいものに置き換える
+	UseRecoveryCode(userId uint, codeHash string, now time.Time) error  // 未使用のリカバリーコードを使用済みにする
+	CountUnusedRecoveryCodes(count *int64, userId uint) error           // 未使用のリカバリーコードの数を取得
+	DeleteRecoveryCodes(userId uint) error                              // ユーザーのリカバリーコードをすべて削除
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewRecoveryCodeRepository(db *gorm.DB) IRecoveryCodeRepository {
+	return &recoveryCodeRepository{db}
+}
+
+// ユーザーのリカバリーコードを削除して、新しいコードを作成（以前のコードは使えなくなる）
+func (cr *recoveryCodeRepository) ReplaceRecoveryCodes(userId uint, codes []model.RecoveryCode) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id=?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// 未使用のリカバリーコードを使用済みにする
+// 存在しない、または使用済みの場合は ErrNotFound を返す（同時に使われても一度しか成功しない）
+func (cr *recoveryCodeRepository) UseRecoveryCode(userId uint, codeHash string, now time.Time) error {
+	result := cr.db.Model(&model.RecoveryCode{}).
+		Where("user_id=? AND code_hash=? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 未使用のリカバリーコードの数を取得
+func (cr *recoveryCodeRepository) CountUnusedRecoveryCodes(count *int64, userId uint) error {
+	if err := cr.db.Model(&model.RecoveryCode{}).Where("user_id=? AND used_at IS NULL", userId).Count(count).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ユーザーのリカバリーコードをすべて削除（2段階認証を無効にしたとき）
+func (cr *recoveryCodeRepository) DeleteRecoveryCodes(userId uint) error {
+	if err := cr.db.Where("user_id=?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
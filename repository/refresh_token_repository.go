@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// リフレッシュトークンに関するデータベース操作を定義
+type IRefreshTokenRepository interface {
+	CreateRefreshToken(token *model.RefreshToken) error                         // 新しいリフレッシュトークンを保存
+	GetRefreshTokenForUpdate(token *model.RefreshToken, tokenHash string) error // ハッシュからトークンを取得し、ローテーションが終わるまで行をロック
+	MarkRefreshTokenUsed(id uint, now time.Time) error                          // 新しいトークンに置き換えたトークンを使用済みにする
+	RevokeTokenFamily(familyId string, now time.Time) error                     // 同じファミリーのトークンをすべて失効させる
+	RevokeSessionRefreshTokens(sessionId uint, now time.Time) error             // セッションで発行したトークンをすべて失効させる
+	RevokeTokenFamilyByHash(tokenHash string, now time.Time) error              // トークンと同じファミリーのトークンをすべて失効させる（ログアウト）
+	RevokeUserRefreshTokens(userId uint, now time.Time) error                   // ユーザーのトークンをすべて失効させる
+	DeleteExpiredRefreshTokens(now time.Time) error                             // 有効期限が切れたトークンをまとめて削除
+	Transaction(fn func(rr IRefreshTokenRepository) error) error                // 1つのトランザクション内で複数の操作を実行
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewRefreshTokenRepository(db *gorm.DB) IRefreshTokenRepository {
+	return &refreshTokenRepository{db}
+}
+
+// 新しいリフレッシュトークンを保存
+func (rr *refreshTokenRepository) CreateRefreshToken(token *model.RefreshToken) error {
+	if err := rr.db.Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ハッシュからトークンを取得
+// 同じトークンで同時に再発行された場合に備えて、行をロックする（トランザクション内で使用する）
+func (rr *refreshTokenRepository) GetRefreshTokenForUpdate(token *model.RefreshToken, tokenHash string) error {
+	if err := rr.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash=?", tokenHash).First(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// トークンを使用済みにする
+func (rr *refreshTokenRepository) MarkRefreshTokenUsed(id uint, now time.Time) error {
+	result := rr.db.Model(&model.RefreshToken{}).Where("id=? AND used_at IS NULL", id).Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、すでに使用済みとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 同じファミリーのトークンをすべて失効させる
+func (rr *refreshTokenRepository) RevokeTokenFamily(familyId string, now time.Time) error {
+	if err := rr.db.Model(&model.RefreshToken{}).Where("family_id=? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// セッションで発行したトークンをすべて失効させる
+func (rr *refreshTokenRepository) RevokeSessionRefreshTokens(sessionId uint, now time.Time) error {
+	if err := rr.db.Model(&model.RefreshToken{}).Where("session_id=? AND revoked_at IS NULL", sessionId).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// トークンと同じファミリーのトークンをすべて失効させる
+// トークンが存在しない場合は何もしない
+func (rr *refreshTokenRepository) RevokeTokenFamilyByHash(tokenHash string, now time.Time) error {
+	family := rr.db.Model(&model.RefreshToken{}).Select("family_id").Where("token_hash=?", tokenHash)
+	if err := rr.db.Model(&model.RefreshToken{}).Where("family_id IN (?) AND revoked_at IS NULL", family).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ユーザーのトークンをすべて失効させる
+func (rr *refreshTokenRepository) RevokeUserRefreshTokens(userId uint, now time.Time) error {
+	if err := rr.db.Model(&model.RefreshToken{}).Where("user_id=? AND revoked_at IS NULL", userId).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 有効期限が切れたトークンをまとめて削除
+func (rr *refreshTokenRepository) DeleteExpiredRefreshTokens(now time.Time) error {
+	if err := rr.db.Where("expires_at < ?", now).Delete(&model.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 1つのトランザクション内で複数の操作を実行
+// fn に渡されるリポジトリはトランザクションに紐づいており、fn がエラーを返すとすべてロールバックされる
+func (rr *refreshTokenRepository) Transaction(fn func(rr IRefreshTokenRepository) error) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&refreshTokenRepository{tx})
+	})
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+)
+
+// リマインダーに関するデータベース操作を定義
+type IReminderRepository interface {
+	GetReminders(reminders *[]model.Reminder, userId uint, taskId uint) error                           // タスクに設定した自分のリマインダーを取得
+	CreateReminder(reminder *model.Reminder) error                                                      // リマインダーを作成
+	DeleteReminder(userId uint, taskId uint, reminderId uint) error                                     // リマインダーを削除
+	ClaimDueReminders(reminders *[]model.Reminder, now time.Time, notBefore time.Time, limit int) error // 通知時刻を迎えたリマインダーを取得し、送信済みにする
+	ReleaseReminder(reminderId uint, maxAttempts int) error                                             // 送信に失敗したリマインダーを再送できる状態に戻す
+}
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewReminderRepository(db *gorm.DB) IReminderRepository {
+	return &reminderRepository{db}
+}
+
+// タスクに設定した自分のリマインダーを取得
+func (rr *reminderRepository) GetReminders(reminders *[]model.Reminder, userId uint, taskId uint) error {
+	if err := rr.db.Where("user_id=? AND task_id=?", userId, taskId).Order("offset_minutes DESC, id").Find(reminders).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// リマインダーを作成
+func (rr *reminderRepository) CreateReminder(reminder *model.Reminder) error {
+	if err := rr.db.Create(reminder).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// リマインダーを削除
+func (rr *reminderRepository) DeleteReminder(userId uint, taskId uint, reminderId uint) error {
+	result := rr.db.Where("id=? AND user_id=? AND task_id=?", reminderId, userId, taskId).Delete(&model.Reminder{})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 削除された行数が0の場合、リマインダーが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 通知時刻（期限 - OffsetMinutes）が notBefore 〜 now の範囲にある未送信のリマインダーを取得し、送信済みにする
+// FOR UPDATE SKIP LOCKED で行をロックしてから更新するため、複数のインスタンスで同時に実行されても
+// 同じリマインダーを二重に取得することはない。送信前に送信済みとして記録するので、再起動しても重複送信されない
+func (rr *reminderRepository) ClaimDueReminders(reminders *[]model.Reminder, now time.Time, notBefore time.Time, limit int) error {
+	sql := `
+UPDATE reminders
+SET sent_for_due_at = tasks.due_at, sent_at = @now
+FROM tasks
+WHERE reminders.task_id = tasks.id
+  AND reminders.id IN (
+    SELECT r.id
+    FROM reminders r
+    JOIN tasks t ON t.id = r.task_id
+    WHERE t.status = @open
+      AND t.due_at IS NOT NULL
+      AND t.due_at - r.offset_minutes * INTERVAL '1 minute' <= @now
+      AND t.due_at - r.offset_minutes * INTERVAL '1 minute' >= @not_before
+      AND r.sent_for_due_at IS DISTINCT FROM t.due_at
+      -- 担当を外れたユーザーには通知しない
+      AND (t.user_id = r.user_id OR (t.assignee_id = r.user_id AND t.assignment_status IN @assignment_statuses))
+    ORDER BY r.id
+    LIMIT @limit
+    FOR UPDATE OF r SKIP LOCKED
+  )
+RETURNING reminders.*`
+	if err := rr.db.Raw(sql, map[string]interface{}{
+		"now":                 now,
+		"not_before":          notBefore,
+		"open":                model.TaskStatusOpen,
+		"assignment_statuses": []string{model.AssignmentPending, model.AssignmentAccepted},
+		"limit":               limit,
+	}).Scan(reminders).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 送信に失敗したリマインダーを再送できる状態に戻す
+// 失敗回数が maxAttempts に達した場合は送信済みのまま（再送しない）
+func (rr *reminderRepository) ReleaseReminder(reminderId uint, maxAttempts int) error {
+	if err := rr.db.Model(&model.Reminder{}).Where("id=?", reminderId).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"sent_for_due_at": gorm.Expr("CASE WHEN attempts + 1 < ? THEN NULL ELSE sent_for_due_at END", maxAttempts),
+		"sent_at":         gorm.Expr("CASE WHEN attempts + 1 < ? THEN NULL ELSE sent_at END", maxAttempts),
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
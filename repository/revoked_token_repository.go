@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 失効させたアクセストークンに関するデータベース操作を定義
+type IRevokedTokenRepository interface {
+	RevokeToken(token *model.RevokedToken) error                                                     // アクセストークンを失効させる
+	IsTokenRevoked(revoked *bool, jti string, sessionId uint, userId uint, issuedAt time.Time) error // アクセストークンが失効しているかどうか
+	DeleteExpiredRevokedTokens(now time.Time) error                                                  // 有効期限が切れたトークンの記録をまとめて削除
+}
+
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewRevokedTokenRepository(db *gorm.DB) IRevokedTokenRepository {
+	return &revokedTokenRepository{db}
+}
+
+// アクセストークンを失効させる（すでに失効している場合は何もしない）
+func (rr *revokedTokenRepository) RevokeToken(token *model.RevokedToken) error {
+	if err := rr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// アクセストークンが失効しているかどうか
+// jti が失効済みの場合、発行したセッションが失効（または削除）されている場合、
+// ユーザーの tokens_valid_after より前に発行された場合に revoked を true にする
+func (rr *revokedTokenRepository) IsTokenRevoked(revoked *bool, jti string, sessionId uint, userId uint, issuedAt time.Time) error {
+	if err := rr.db.Raw("SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ?) "+
+		"OR NOT EXISTS (SELECT 1 FROM sessions WHERE id = ? AND user_id = ? AND revoked_at IS NULL) "+
+		"OR EXISTS (SELECT 1 FROM users WHERE id = ? AND tokens_valid_after > ?)", jti, sessionId, userId, userId, issuedAt).
+		Scan(revoked).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 有効期限が切れたトークンの記録をまとめて削除（期限切れのトークンはJWTの検証で拒否される）
+func (rr *revokedTokenRepository) DeleteExpiredRevokedTokens(now time.Time) error {
+	if err := rr.db.Where("expires_at < ?", now).Delete(&model.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
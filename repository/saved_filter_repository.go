@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 保存済みフィルターに関するデータベース操作を定義
+type ISavedFilterRepository interface {
+	GetAllSavedFilters(filters *[]model.SavedFilter, userId uint) error             // ユーザーの保存済みフィルターをすべて取得
+	GetSavedFilterById(filter *model.SavedFilter, userId uint, filterId uint) error // 特定のフィルターを取得
+	CreateSavedFilter(filter *model.SavedFilter) error                              // フィルターを作成
+	UpdateSavedFilter(filter *model.SavedFilter, userId uint, filterId uint) error  // フィルターを更新
+	DeleteSavedFilter(userId uint, filterId uint) error                             // フィルターを削除
+}
+
+type savedFilterRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewSavedFilterRepository(db *gorm.DB) ISavedFilterRepository {
+	return &savedFilterRepository{db}
+}
+
+// ユーザーの保存済みフィルターをすべて取得（ピン留めされたものを先頭に並べる）
+func (fr *savedFilterRepository) GetAllSavedFilters(filters *[]model.SavedFilter, userId uint) error {
+	if err := fr.db.Where("user_id=?", userId).Order("pinned DESC, name").Find(filters).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 特定のフィルターを取得
+func (fr *savedFilterRepository) GetSavedFilterById(filter *model.SavedFilter, userId uint, filterId uint) error {
+	if err := fr.db.Where("user_id=?", userId).First(filter, filterId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// フィルターを作成
+func (fr *savedFilterRepository) CreateSavedFilter(filter *model.SavedFilter) error {
+	if err := fr.db.Create(filter).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// フィルターを更新
+func (fr *savedFilterRepository) UpdateSavedFilter(filter *model.SavedFilter, userId uint, filterId uint) error {
+	result := fr.db.Model(filter).Clauses(clause.Returning{}).Where("id=? AND user_id=?", filterId, userId).Updates(map[string]interface{}{
+		"name":   filter.Name,
+		"query":  filter.Query,
+		"pinned": filter.Pinned,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、フィルターが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// フィルターを削除
+func (fr *savedFilterRepository) DeleteSavedFilter(userId uint, filterId uint) error {
+	result := fr.db.Where("id=? AND user_id=?", filterId, userId).Delete(&model.SavedFilter{})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 削除された行数が0の場合、フィルターが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+)
+
+// 最終アクセス日時を更新する間隔（リクエストのたびに書き込まないようにする）
+const sessionTouchInterval = time.Minute
+
+// セッションに関するデータベース操作を定義
+type ISessionRepository interface {
+	CreateSession(session *model.Session) error                                    // セッションを作成
+	GetActiveSessions(sessions *[]model.Session, userId uint, now time.Time) error // 失効しておらず有効期限内のセッションを取得
+	GetSessionById(session *model.Session, userId uint, sessionId uint) error      // IDからセッションを取得
+	TouchSession(sessionId uint, ipAddress string, now time.Time) error            // 最終アクセス日時とIPアドレスを更新
+	ExtendSession(sessionId uint, expiresAt time.Time) error                       // トークンの再発行に合わせて有効期限を延長
+	RevokeSession(userId uint, sessionId uint, now time.Time) error                // セッションを失効させる
+	RevokeSessionByRefreshToken(tokenHash string, now time.Time) error             // リフレッシュトークンを発行したセッションを失効させる
+	RevokeUserSessions(userId uint, now time.Time) error                           // ユーザーのセッションをすべて失効させる
+	DeleteExpiredSessions(now time.Time) error                                     // 有効期限が切れたセッションをまとめて削除
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewSessionRepository(db *gorm.DB) ISessionRepository {
+	return &sessionRepository{db}
+}
+
+// セッションを作成
+func (sr *sessionRepository) CreateSession(session *model.Session) error {
+	if err := sr.db.Create(session).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 失効しておらず有効期限内のセッションを、最後にアクセスした順に取得
+func (sr *sessionRepository) GetActiveSessions(sessions *[]model.Session, userId uint, now time.Time) error {
+	if err := sr.db.Where("user_id=? AND revoked_at IS NULL AND expires_at > ?", userId, now).
+		Order("last_seen_at DESC").Find(sessions).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// IDからセッションを取得（ユーザー自身のセッションのみ）
+func (sr *sessionRepository) GetSessionById(session *model.Session, userId uint, sessionId uint) error {
+	if err := sr.db.Where("user_id=?", userId).First(session, sessionId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 最終アクセス日時とIPアドレスを更新
+// 前回の更新から sessionTouchInterval 経っていない場合は何もしない
+func (sr *sessionRepository) TouchSession(sessionId uint, ipAddress string, now time.Time) error {
+	if err := sr.db.Model(&model.Session{}).
+		Where("id=? AND last_seen_at < ?", sessionId, now.Add(-sessionTouchInterval)).
+		Updates(map[string]interface{}{"last_seen_at": now, "ip_address": ipAddress}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// トークンの再発行に合わせて有効期限を延長
+func (sr *sessionRepository) ExtendSession(sessionId uint, expiresAt time.Time) error {
+	if err := sr.db.Model(&model.Session{}).Where("id=? AND revoked_at IS NULL", sessionId).
+		Update("expires_at", expiresAt).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// セッションを失効させる（ユーザー自身のセッションのみ）
+func (sr *sessionRepository) RevokeSession(userId uint, sessionId uint, now time.Time) error {
+	result := sr.db.Model(&model.Session{}).Where("id=? AND user_id=? AND revoked_at IS NULL", sessionId, userId).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、セッションが存在しない（または失効済み）とみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// リフレッシュトークンを発行したセッションを失効させる（トークンが存在しない場合は何もしない）
+func (sr *sessionRepository) RevokeSessionByRefreshToken(tokenHash string, now time.Time) error {
+	session := sr.db.Model(&model.RefreshToken{}).Select("session_id").Where("token_hash=?", tokenHash)
+	if err := sr.db.Model(&model.Session{}).Where("id IN (?) AND revoked_at IS NULL", session).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ユーザーのセッションをすべて失効させる
+func (sr *sessionRepository) RevokeUserSessions(userId uint, now time.Time) error {
+	if err := sr.db.Model(&model.Session{}).Where("user_id=? AND revoked_at IS NULL", userId).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 有効期限が切れたセッションをまとめて削除（失効済みのものも、有効期限までは失効の確認に使うため残す）
+func (sr *sessionRepository) DeleteExpiredSessions(now time.Time) error {
+	if err := sr.db.Where("expires_at < ?", now).Delete(&model.Session{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+)
+
+// タスクの統計情報を集計するデータベース操作を定義
+// 日付の区切りはすべて tz（IANAのタイムゾーン名）の暦日で判定する
+type IStatsRepository interface {
+	CountByStatus(counts *[]model.StatusCount, userId uint) error                                              // ステータスごとのタスク数
+	GetThroughput(points *[]model.ThroughputPoint, userId uint, from, to time.Time, interval, tz string) error // 期間ごとの作成数と完了数
+	GetAverageLeadTime(hours *sql.NullFloat64, userId uint, from, to time.Time, tz string) error               // 作成から完了までの平均時間
+	GetStreaks(streaks *[]model.Streak, userId uint, tz string) error                                          // 連続して完了した期間の一覧
+	GetBurndown(points *[]model.BurndownPoint, userId uint, from, to time.Time, tz string) error               // 日ごとの未完了タスク数
+}
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewStatsRepository(db *gorm.DB) IStatsRepository {
+	return &statsRepository{db}
+}
+
+// ステータスごとのタスク数を集計
+func (sr *statsRepository) CountByStatus(counts *[]model.StatusCount, userId uint) error {
+	if err := sr.db.Model(&model.Task{}).Select("status, COUNT(*) AS count").
+		Where("user_id=?", userId).Group("status").Order("status").Scan(counts).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 期間（day または week）ごとの作成数と完了数を集計
+// from と to は期間に含める最初と最後の日（日付部分のみ使用）
+func (sr *statsRepository) GetThroughput(points *[]model.ThroughputPoint, userId uint, from, to time.Time, interval, tz string) error {
+	query := `
+WITH buckets AS (
+	SELECT generate_series(
+		date_trunc(CAST(@interval AS text), CAST(@from AS timestamp)),
+		CAST(@to AS timestamp),
+		CAST('1 ' || CAST(@interval AS text) AS interval)
+	) AS period
+), created AS (
+	SELECT date_trunc(CAST(@interval AS text), created_at AT TIME ZONE @tz) AS period, COUNT(*) AS count
+	FROM tasks WHERE user_id = @user GROUP BY 1
+), completed AS (
+	SELECT date_trunc(CAST(@interval AS text), completed_at AT TIME ZONE @tz) AS period, COUNT(*) AS count
+	FROM tasks WHERE user_id = @user AND completed_at IS NOT NULL GROUP BY 1
+)
+SELECT to_char(b.period, 'YYYY-MM-DD') AS period,
+	COALESCE(cr.count, 0) AS created,
+	COALESCE(co.count, 0) AS completed
+FROM buckets b
+LEFT JOIN created cr ON cr.period = b.period
+LEFT JOIN completed co ON co.period = b.period
+ORDER BY b.period`
+	if err := sr.db.Raw(query, map[string]interface{}{
+		"interval": interval,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"tz":       tz,
+		"user":     userId,
+	}).Scan(points).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 期間内に完了したタスクについて、作成から完了までの平均時間（時間単位）を集計
+// 対象のタスクがない場合は hours.Valid が false になる
+func (sr *statsRepository) GetAverageLeadTime(hours *sql.NullFloat64, userId uint, from, to time.Time, tz string) error {
+	var result struct {
+		Hours sql.NullFloat64
+	}
+	query := `
+SELECT AVG(EXTRACT(EPOCH FROM completed_at - created_at)) / 3600 AS hours
+FROM tasks
+WHERE user_id = @user
+	AND completed_at >= CAST(@from AS timestamp) AT TIME ZONE @tz
+	AND completed_at < (CAST(@to AS timestamp) + interval '1 day') AT TIME ZONE @tz`
+	if err := sr.db.Raw(query, map[string]interface{}{
+		"from": from.Format("2006-01-02"),
+		"to":   to.Format("2006-01-02"),
+		"tz":   tz,
+		"user": userId,
+	}).Scan(&result).Error; err != nil {
+		return err
+	}
+	*hours = result.Hours
+	return nil
+}
+
+// タスクを1件以上完了した日が連続している期間を集計（古い順）
+func (sr *statsRepository) GetStreaks(streaks *[]model.Streak, userId uint, tz string) error {
+	// 連続する日付は「日付 - 行番号」が同じ値になることを利用してグループ化する
+	query := `
+WITH days AS (
+	SELECT DISTINCT CAST(completed_at AT TIME ZONE @tz AS date) AS day
+	FROM tasks WHERE user_id = @user AND completed_at IS NOT NULL
+), grouped AS (
+	SELECT day, day - CAST(ROW_NUMBER() OVER (ORDER BY day) AS int) AS grp FROM days
+)
+SELECT to_char(MIN(day), 'YYYY-MM-DD') AS start,
+	to_char(MAX(day), 'YYYY-MM-DD') AS "end",
+	COUNT(*) AS length
+FROM grouped GROUP BY grp ORDER BY MIN(day)`
+	if err := sr.db.Raw(query, map[string]interface{}{
+		"tz":   tz,
+		"user": userId,
+	}).Scan(streaks).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 指定期間の各日の終わり時点で未完了だったタスク数を集計
+func (sr *statsRepository) GetBurndown(points *[]model.BurndownPoint, userId uint, from, to time.Time, tz string) error {
+	query := `
+SELECT to_char(d, 'YYYY-MM-DD') AS date, COUNT(t.id) AS remaining
+FROM generate_series(CAST(@from AS timestamp), CAST(@to AS timestamp), interval '1 day') AS d
+LEFT JOIN tasks t ON t.user_id = @user
+	AND t.created_at < (d + interval '1 day') AT TIME ZONE @tz
+	AND (t.completed_at IS NULL OR t.completed_at >= (d + interval '1 day') AT TIME ZONE @tz)
+GROUP BY d ORDER BY d`
+	if err := sr.db.Raw(query, map[string]interface{}{
+		"from": from.Format("2006-01-02"),
+		"to":   to.Format("2006-01-02"),
+		"tz":   tz,
+		"user": userId,
+	}).Scan(points).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+)
+
+// 差分同期に関するデータベース操作を定義
+// tasks・comments・tombstones の change_seq は同じシーケンスから採番されるため、値を比較して順序を判定できる
+type ISyncRepository interface {
+	GetChangedTasks(tasks *[]model.Task, userId uint, since int64, limit int) error          // since より後に作成・更新された閲覧できるタスクを取得
+	GetChangedComments(comments *[]model.Comment, userId uint, since int64, limit int) error // since より後に作成された閲覧できるタスクのコメントを取得
+	GetTombstones(tombstones *[]model.Tombstone, userId uint, since int64, limit int) error  // since より後の削除の記録を取得
+}
+
+type syncRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewSyncRepository(db *gorm.DB) ISyncRepository {
+	return &syncRepository{db}
+}
+
+// since より後に作成・更新された閲覧できるタスクを変更順に取得
+func (sr *syncRepository) GetChangedTasks(tasks *[]model.Task, userId uint, since int64, limit int) error {
+	if err := sr.db.Scopes(readableBy(userId)).Where("tasks.change_seq > ?", since).
+		Order("tasks.change_seq").Limit(limit).Find(tasks).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// since より後に作成された閲覧できるタスクのコメントを変更順に取得
+func (sr *syncRepository) GetChangedComments(comments *[]model.Comment, userId uint, since int64, limit int) error {
+	if err := sr.db.Joins("JOIN tasks ON tasks.id = comments.task_id").Scopes(readableBy(userId)).
+		Where("comments.change_seq > ?", since).Order("comments.change_seq").Limit(limit).Find(comments).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// since より後の削除の記録を変更順に取得
+func (sr *syncRepository) GetTombstones(tombstones *[]model.Tombstone, userId uint, since int64, limit int) error {
+	if err := sr.db.Where("user_id=? AND change_seq > ?", userId, since).
+		Order("change_seq").Limit(limit).Find(tombstones).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 変更のたびに新しい change_seq を採番する式
+func nextChangeSeq() interface{} {
+	return gorm.Expr("nextval('" + model.ChangeSeqName + "')")
+}
+
+// タスクとそのサブタスクすべてについて、作成者と担当者（承諾待ち・承諾済み）に対する削除を記録
+// 作成者がタスクを削除する直前に、同じトランザクション内で呼び出す
+func createTaskTreeTombstones(tx *gorm.DB, userId uint, taskId uint) error {
+	sql := `
+WITH RECURSIVE tree AS (
+  SELECT id, user_id, assignee_id, assignment_status FROM tasks WHERE id = @task_id AND user_id = @user_id
+  UNION ALL
+  SELECT t.id, t.user_id, t.assignee_id, t.assignment_status FROM tasks t JOIN tree ON t.parent_id = tree.id
+)
+INSERT INTO tombstones (entity_type, entity_id, user_id, created_at)
+SELECT CAST(@entity_type AS text), id, user_id, NOW() FROM tree
+UNION
+SELECT CAST(@entity_type AS text), id, assignee_id, NOW() FROM tree
+WHERE assignee_id IS NOT NULL AND assignment_status IN @assigned`
+	return tx.Exec(sql, map[string]interface{}{
+		"task_id":     taskId,
+		"user_id":     userId,
+		"entity_type": model.SyncEntityTask,
+		"assigned":    []string{model.AssignmentPending, model.AssignmentAccepted},
+	}).Error
+}
+
+// 条件に一致するタスクの担当者（承諾待ち・承諾済み）に対する削除を記録
+// 割り当ての解除や辞退でタスクを閲覧できなくなる直前に、同じトランザクション内で呼び出す
+func createAssigneeTombstone(tx *gorm.DB, where string, args ...interface{}) error {
+	sql := "INSERT INTO tombstones (entity_type, entity_id, user_id, created_at) " +
+		"SELECT CAST(? AS text), id, assignee_id, NOW() FROM tasks WHERE assignee_id IS NOT NULL AND assignment_status IN ? AND " + where
+	vars := append([]interface{}{model.SyncEntityTask, []string{model.AssignmentPending, model.AssignmentAccepted}}, args...)
+	return tx.Exec(sql, vars...).Error
+}
+
+// コメントを閲覧できるユーザー（タスクの作成者と、承諾待ち・承諾済みの担当者）に対する削除を記録
+// コメントを削除する直前に、同じトランザクション内で呼び出す
+func createCommentTombstones(tx *gorm.DB, userId uint, commentId uint) error {
+	sql := `
+INSERT INTO tombstones (entity_type, entity_id, user_id, created_at)
+SELECT CAST(@entity_type AS text), c.id, t.user_id, NOW()
+FROM comments c JOIN tasks t ON t.id = c.task_id
+WHERE c.id = @comment_id AND c.user_id = @user_id
+UNION
+SELECT CAST(@entity_type AS text), c.id, t.assignee_id, NOW()
+FROM comments c JOIN tasks t ON t.id = c.task_id
+WHERE c.id = @comment_id AND c.user_id = @user_id AND t.assignee_id IS NOT NULL AND t.assignment_status IN @assigned`
+	return tx.Exec(sql, map[string]interface{}{
+		"comment_id":  commentId,
+		"user_id":     userId,
+		"entity_type": model.SyncEntityComment,
+		"assigned":    []string{model.AssignmentPending, model.AssignmentAccepted},
+	}).Error
+}
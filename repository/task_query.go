@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/query"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 解析済みの検索クエリをGORMのスコープに変換する
+// 値はすべてプレースホルダ経由で渡し、SQLへ直接埋め込まない
+// now は相対日付や「今日」の基準となる時刻（タイムゾーンも含めて使用）
+// snoozed: の指定がない場合は、スヌーズ中のタスクを除外する
+func taskQueryScope(q query.Query, now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		snoozedSpecified := false
+		for _, term := range q.Terms {
+			if term.Field == query.FieldSnoozed {
+				snoozedSpecified = true
+				if term.Value == "any" {
+					continue
+				}
+			}
+			sql, args := taskTermCondition(term, now)
+			if term.Negate {
+				sql = "NOT COALESCE((" + sql + "), false)"
+			}
+			db = db.Where(sql, args...)
+		}
+		if !snoozedSpecified {
+			db = db.Where(notSnoozedCondition, now)
+		}
+		return db
+	}
+}
+
+// スヌーズ中でないタスクの条件（スヌーズの期限を過ぎたものを含む）
+const notSnoozedCondition = "(tasks.snoozed_until IS NULL OR tasks.snoozed_until <= ?)"
+
+// 検索条件の1項目をSQLの条件式と引数に変換
+func taskTermCondition(term query.Term, now time.Time) (string, []any) {
+	switch term.Field {
+	case query.FieldStatus:
+		return "tasks.status = ?", []any{term.Value}
+	case query.FieldLabel:
+		labels, _ := json.Marshal([]string{term.Value})
+		return "tasks.labels @> ?::jsonb", []any{string(labels)}
+	case query.FieldDue:
+		return dueCondition(*term.Due, now)
+	case query.FieldProject:
+		return "tasks.project_id = ?", []any{term.Value}
+	case query.FieldCustom:
+		return customFieldCondition(*term.Custom)
+	case query.FieldSnoozed:
+		if term.Value == "true" {
+			return "tasks.snoozed_until > ?", []any{now}
+		}
+		return notSnoozedCondition, []any{now}
+	default:
+		// title: またはフィールド指定なしはタイトルの部分一致
+		return "tasks.title ILIKE ?", []any{"%" + escapeLike(term.Value) + "%"}
+	}
+}
+
+// 期限日の条件をSQLに変換
+func dueCondition(due query.Due, now time.Time) (string, []any) {
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch due.Kind {
+	case query.DueNone:
+		return "tasks.due_at IS NULL", nil
+	case query.DueToday:
+		return "tasks.due_at >= ? AND tasks.due_at < ?", []any{startOfToday, startOfToday.AddDate(0, 0, 1)}
+	case query.DueOverdue:
+		return "tasks.due_at < ? AND tasks.status <> 'done'", []any{now}
+	case query.DueRelative:
+		return "tasks.due_at " + string(due.Op) + " ?", []any{now.AddDate(0, 0, due.Days)}
+	default:
+		// 日付指定はその日の0時から翌日0時までを1日として比較
+		start := time.Date(due.Date.Year(), due.Date.Month(), due.Date.Day(), 0, 0, 0, 0, now.Location())
+		end := start.AddDate(0, 0, 1)
+		switch due.Op {
+		case query.OpLt:
+			return "tasks.due_at < ?", []any{start}
+		case query.OpLe:
+			return "tasks.due_at < ?", []any{end}
+		case query.OpGt:
+			return "tasks.due_at >= ?", []any{end}
+		case query.OpGe:
+			return "tasks.due_at >= ?", []any{start}
+		default:
+			return "tasks.due_at >= ? AND tasks.due_at < ?", []any{start, end}
+		}
+	}
+}
+
+// カスタムフィールドの条件をSQLに変換
+// 一致は値が等しいもの（複数選択の場合はいずれかが等しいもの）、大小の比較は数値または日付の値のみを対象にする
+func customFieldCondition(custom query.Custom) (string, []any) {
+	number, isNumber := custom.Number()
+	if custom.Op == query.OpEq {
+		text, _ := json.Marshal(custom.Value)
+		if isNumber {
+			// 数値の値（number / user）と文字列の値のどちらにも一致させる
+			return "(tasks.custom_fields -> CAST(? AS text) @> ?::jsonb OR tasks.custom_fields -> CAST(? AS text) @> ?::jsonb)",
+				[]any{custom.Key, string(text), custom.Key, strconv.FormatFloat(number, 'f', -1, 64)}
+		}
+		return "tasks.custom_fields -> CAST(? AS text) @> ?::jsonb", []any{custom.Key, string(text)}
+	}
+	if isNumber {
+		return "CASE WHEN jsonb_typeof(tasks.custom_fields -> CAST(? AS text)) = 'number' THEN (tasks.custom_fields ->> CAST(? AS text))::numeric " +
+			string(custom.Op) + " ? END", []any{custom.Key, custom.Key, number}
+	}
+	// 日付（YYYY-MM-DD）は文字列として比較できる
+	return "CASE WHEN jsonb_typeof(tasks.custom_fields -> CAST(? AS text)) = 'string' THEN tasks.custom_fields ->> CAST(? AS text) " +
+		string(custom.Op) + " ? END", []any{custom.Key, custom.Key, custom.Value}
+}
+
+// 並び順をGORMのスコープに変換する（最後に作成日時順を加える）
+// カスタムフィールドで並べる場合、値のないタスクは最後になる
+func taskOrderScope(q query.Query) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, key := range q.Sort {
+			direction := "ASC"
+			if key.Desc {
+				direction = "DESC"
+			}
+			if key.Custom != "" {
+				db = db.Order(clause.OrderBy{Expression: clause.Expr{
+					SQL:  "tasks.custom_fields -> CAST(? AS text) " + direction + " NULLS LAST",
+					Vars: []any{key.Custom},
+				}})
+				continue
+			}
+			// 列名は query.Parse で許可されたもののみ
+			db = db.Order("tasks." + key.Column + " " + direction + " NULLS LAST")
+		}
+		return db.Order("tasks.created_at")
+	}
+}
+
+// LIKE検索で特殊な意味を持つ文字をエスケープ
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
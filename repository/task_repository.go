@@ -1,20 +1,34 @@
 package repository
 
 import (
-	"fmt"
+	"time"
 
 	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/query"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 // タスクに関するデータベース操作を定義
 type ITaskRepository interface {
-	GerAllTasks(tasks *[]model.Task, userId uint) error           //ユーザーIDに基づいてすべてのタスクを取得
-	GetTaskById(task *model.Task, userId uint, taskId uint) error //特定のタスクIDに基づいてタスクを取得
-	CreateTask(task *model.Task) error                            // 新しいタスクをデータベースに作成
-	UpdateTask(task *model.Task, userId uint, taskId uint) error  //既存のタスクを更新
-	DeleteTask(userId uint, taskId uint) error                    //特定のタスクを削除
+	GerAllTasks(tasks *[]model.Task, userId uint) error                                                      //ユーザーIDに基づいてすべてのタスクを取得
+	SearchTasks(tasks *[]model.Task, userId uint, q query.Query, now time.Time) error                        // 検索クエリに一致するタスクを取得
+	GetAssignedTasks(tasks *[]model.Task, userId uint, q query.Query, now time.Time) error                   // 自分が担当者になっているタスクを取得
+	GetTaskById(task *model.Task, userId uint, taskId uint) error                                            //特定のタスクIDに基づいてタスクを取得
+	CreateTask(task *model.Task) error                                                                       // 新しいタスクをデータベースに作成
+	UpdateTask(task *model.Task, fields []string, userId uint, taskId uint) error                            //既存のタスクの fields で指定された項目を更新
+	UpdateTaskIfUnmodified(task *model.Task, fields []string, userId uint, taskId uint, version int64) error // version が一致する場合のみタスクを更新
+	DeleteTask(userId uint, taskId uint) error                                                               //特定のタスクを削除
+	AssignTask(task *model.Task, userId uint, taskId uint, assigneeId uint) error                            // タスクを他のユーザーに割り当てる（作成者のみ）
+	UnassignTask(task *model.Task, userId uint, taskId uint) error                                           // 割り当てを解除する（作成者のみ）
+	RespondToAssignment(task *model.Task, userId uint, taskId uint, status string) error                     // 割り当てを承諾または辞退する（担当者のみ）
+	ClaimDueSoonTasks(tasks *[]model.Task, from time.Time, to time.Time) error                               // 期限が指定期間内の未通知タスクを取得し、通知済みにする
+	SnoozeTask(task *model.Task, userId uint, taskId uint, until *time.Time) error                           // タスクをスヌーズする（nil で解除）
+	GetMyDayTasks(tasks *[]model.Task, userId uint, date time.Time) error                                    // 指定日の「My Day」に追加したタスクを取得
+	AddToMyDay(userId uint, taskId uint, date time.Time) error                                               // 指定日の「My Day」にタスクを追加
+	RemoveFromMyDay(userId uint, taskId uint, date time.Time) error                                          // 指定日の「My Day」からタスクを外す
+	DeleteExpiredMyDayEntries(now time.Time) error                                                           // 過去の日付の「My Day」の選択を削除
+	Transaction(fn func(tr ITaskRepository) error) error                                                     // 1つのトランザクション内で複数の操作を実行
 }
 
 // データベース操作を実行するためのリポジトリ
@@ -29,8 +43,17 @@ func NewTaskRepository(db *gorm.DB) ITaskRepository {
 
 // ユーザーIDに基づいてすべてのタスクを取得
 func (tr *taskRepository) GerAllTasks(tasks *[]model.Task, userId uint) error {
-	// ユーザーIDでフィルタリングし、タスクを並べ替えて取得
-	if err := tr.db.Joins("User").Where("user_id=?", userId).Order("created_at").Find(tasks).Error; err != nil {
+	// ユーザーIDでフィルタリングし、タスクを並べ替えて取得（スヌーズ中のタスクは除く）
+	if err := tr.db.Joins("User").Where("user_id=?", userId).Where(notSnoozedCondition, time.Now()).Order("created_at").Find(tasks).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 検索クエリに一致するタスクを取得
+func (tr *taskRepository) SearchTasks(tasks *[]model.Task, userId uint, q query.Query, now time.Time) error {
+	// ユーザーIDで絞り込んだうえで、クエリの条件と並び順をスコープとして適用
+	if err := tr.db.Joins("User").Where("user_id=?", userId).Scopes(taskQueryScope(q, now), taskOrderScope(q)).Find(tasks).Error; err != nil {
 		return err
 	}
 	return nil
@@ -38,8 +61,17 @@ func (tr *taskRepository) GerAllTasks(tasks *[]model.Task, userId uint) error {
 
 // 特定のタスクIDに基づいてタスクを取得
 func (tr *taskRepository) GetTaskById(task *model.Task, userId uint, taskId uint) error {
-	// ユーザーIDとタスクIDでタスクを取得
-	if err := tr.db.Joins("User").Where("user_id=?", userId).First(task, taskId).Error; err != nil {
+	// 作成者、または承諾待ち・承諾済みの担当者であれば取得できる
+	if err := tr.db.Joins("User").Scopes(readableBy(userId)).First(task, taskId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 自分が担当者になっているタスクを取得（承諾待ちと承諾済みのもの）
+func (tr *taskRepository) GetAssignedTasks(tasks *[]model.Task, userId uint, q query.Query, now time.Time) error {
+	if err := tr.db.Joins("User").Where("assignee_id=? AND assignment_status IN ?", userId, []string{model.AssignmentPending, model.AssignmentAccepted}).
+		Scopes(taskQueryScope(q, now), taskOrderScope(q)).Find(tasks).Error; err != nil {
 		return err
 	}
 	return nil
@@ -54,32 +86,243 @@ func (tr *taskRepository) CreateTask(task *model.Task) error {
 	return nil
 }
 
-// 既存のタスクを更新
-func (tr *taskRepository) UpdateTask(task *model.Task, userId uint, taskId uint) error {
-	// タスクIDとユーザーIDで指定されたタスクを更新
-	result := tr.db.Model(task).Clauses(clause.Returning{}).Where("id=? AND user_id=?", taskId, userId).Update("title", task.Title)
+// 既存のタスクを更新（fields で指定された項目のみ）
+func (tr *taskRepository) UpdateTask(task *model.Task, fields []string, userId uint, taskId uint) error {
+	return tr.updateTask(task, fields, userId, taskId, tr.db)
+}
+
+// 取得したときから変更されていない（version が一致する）場合のみタスクを更新
+// 一致しない場合は更新されず、タスクが存在しない場合と同じエラーを返す
+func (tr *taskRepository) UpdateTaskIfUnmodified(task *model.Task, fields []string, userId uint, taskId uint, version int64) error {
+	return tr.updateTask(task, fields, userId, taskId, tr.db.Where("tasks.change_seq=?", version))
+}
+
+// タスクIDとユーザーIDで指定されたタスクの、fields で指定された項目を更新
+// 完了に変更された場合は完了日時を記録し、未完了に戻された場合はクリアする
+// 作成者、または承諾済みの担当者であれば更新できる
+func (tr *taskRepository) updateTask(task *model.Task, fields []string, userId uint, taskId uint, db *gorm.DB) error {
+	values := map[string]interface{}{
+		"title":         task.Title,
+		"status":        task.Status,
+		"due_at":        task.DueAt,
+		"labels":        task.Labels,
+		"priority":      task.Priority,
+		"recurrence":    task.Recurrence,
+		"project_id":    task.ProjectId,
+		"custom_fields": task.CustomFields,
+	}
+	updates := map[string]interface{}{"change_seq": nextChangeSeq()}
+	for _, field := range fields {
+		updates[field] = values[field]
+		switch field {
+		case "status":
+			updates["completed_at"] = gorm.Expr("CASE WHEN ? = ? THEN COALESCE(completed_at, NOW()) ELSE NULL END", task.Status, model.TaskStatusDone)
+		case "due_at":
+			// 期限が変更された場合は、新しい期限で再度リマインダーを送れるようにする
+			updates["due_soon_notified_at"] = gorm.Expr("CASE WHEN due_at IS DISTINCT FROM ? THEN NULL ELSE due_soon_notified_at END", task.DueAt)
+		}
+	}
+	result := db.Model(task).Clauses(clause.Returning{}).Where("id=?", taskId).Scopes(writableBy(userId)).Updates(updates)
 	// 更新結果のエラーチェック
 	if result.Error != nil {
 		return result.Error
 	}
 	// 更新された行数が0の場合、タスクが存在しないとみなす
 	if result.RowsAffected < 1 {
-		return fmt.Errorf("object does not exist")
+		return ErrNotFound
 	}
 	return nil
 }
 
 // 特定のタスクを削除
+// 差分同期のため、サブタスクも含めて閲覧できていたユーザーごとに削除を記録する
 func (tr *taskRepository) DeleteTask(userId uint, taskId uint) error {
-	// タスクIDとユーザーIDで指定されたタスクを削除
-	result := tr.db.Where("id=? AND user_id=?", taskId, userId).Delete(&model.Task{})
-	// 削除結果のエラーチェック
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := createTaskTreeTombstones(tx, userId, taskId); err != nil {
+			return err
+		}
+		// タスクIDとユーザーIDで指定されたタスクを削除（担当者は削除できない）
+		result := tx.Where("id=? AND user_id=?", taskId, userId).Delete(&model.Task{})
+		// 削除結果のエラーチェック
+		if result.Error != nil {
+			return result.Error
+		}
+		// 削除された行数が0の場合、タスクが存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// タスクを他のユーザーに割り当てる（作成者のみ）
+// 割り当て直した場合も承諾待ちの状態から始まる
+func (tr *taskRepository) AssignTask(task *model.Task, userId uint, taskId uint, assigneeId uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		// 別のユーザーに割り当て直す場合は、元の担当者に対して削除を記録
+		if err := createAssigneeTombstone(tx, "id=? AND user_id=? AND assignee_id<>?", taskId, userId, assigneeId); err != nil {
+			return err
+		}
+		result := tx.Model(task).Clauses(clause.Returning{}).Where("id=? AND user_id=?", taskId, userId).Updates(map[string]interface{}{
+			"assignee_id":       assigneeId,
+			"assignment_status": model.AssignmentPending,
+			"change_seq":        nextChangeSeq(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 更新された行数が0の場合、タスクが存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// 割り当てを解除する（作成者のみ）
+func (tr *taskRepository) UnassignTask(task *model.Task, userId uint, taskId uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		// 元の担当者に対して削除を記録
+		if err := createAssigneeTombstone(tx, "id=? AND user_id=?", taskId, userId); err != nil {
+			return err
+		}
+		result := tx.Model(task).Clauses(clause.Returning{}).Where("id=? AND user_id=?", taskId, userId).Updates(map[string]interface{}{
+			"assignee_id":       nil,
+			"assignment_status": "",
+			"change_seq":        nextChangeSeq(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 更新された行数が0の場合、タスクが存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// 割り当てを承諾または辞退する（担当者のみ）
+// 承諾済みのタスクを後から辞退することもできる
+func (tr *taskRepository) RespondToAssignment(task *model.Task, userId uint, taskId uint, status string) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		assigned := []string{model.AssignmentPending, model.AssignmentAccepted}
+		// 辞退した場合はタスクを閲覧できなくなるため、担当者に対して削除を記録
+		if status == model.AssignmentDeclined {
+			if err := createAssigneeTombstone(tx, "id=? AND assignee_id=?", taskId, userId); err != nil {
+				return err
+			}
+		}
+		result := tx.Model(task).Clauses(clause.Returning{}).
+			Where("id=? AND assignee_id=? AND assignment_status IN ?", taskId, userId, assigned).
+			Updates(map[string]interface{}{
+				"assignment_status": status,
+				"change_seq":        nextChangeSeq(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 更新された行数が0の場合、割り当てられたタスクが存在しないとみなす
+		if result.RowsAffected < 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// 期限が指定期間内の未完了・未通知のタスクを取得し、通知済みにする
+// 条件付きの更新で取得するため、複数のインスタンスから同時に実行されても同じタスクが二重に取得されることはない
+func (tr *taskRepository) ClaimDueSoonTasks(tasks *[]model.Task, from time.Time, to time.Time) error {
+	if err := tr.db.Model(tasks).Clauses(clause.Returning{}).
+		Where("status=? AND due_soon_notified_at IS NULL AND due_at >= ? AND due_at < ?", model.TaskStatusOpen, from, to).
+		UpdateColumn("due_soon_notified_at", time.Now()).Error; err != nil { // タスクの更新日時は変更しない
+		return err
+	}
+	return nil
+}
+
+// タスクをスヌーズする（nil で解除）
+// 作成者、または承諾済みの担当者であれば変更できる
+func (tr *taskRepository) SnoozeTask(task *model.Task, userId uint, taskId uint, until *time.Time) error {
+	result := tr.db.Model(task).Clauses(clause.Returning{}).Where("id=?", taskId).Scopes(writableBy(userId)).Updates(map[string]interface{}{
+		"snoozed_until": until,
+		"change_seq":    nextChangeSeq(),
+	})
 	if result.Error != nil {
 		return result.Error
 	}
-	// 削除された行数が0の場合、タスクが存在しないとみなす
+	// 更新された行数が0の場合、タスクが存在しないとみなす
 	if result.RowsAffected < 1 {
-		return fmt.Errorf("object does not exist")
+		return ErrNotFound
 	}
 	return nil
 }
+
+// 指定日の「My Day」に追加したタスクを、追加した順に取得（閲覧できなくなったタスクは除く）
+func (tr *taskRepository) GetMyDayTasks(tasks *[]model.Task, userId uint, date time.Time) error {
+	if err := tr.db.Joins("JOIN my_day_entries ON my_day_entries.task_id = tasks.id").
+		Where("my_day_entries.user_id=? AND my_day_entries.date=CAST(? AS date)", userId, date.Format("2006-01-02")).
+		Scopes(readableBy(userId)).Order("my_day_entries.created_at").Find(tasks).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 指定日の「My Day」にタスクを追加（追加済みの場合は何もしない）
+// タスクを閲覧できるかどうかはユースケース層で確認する
+func (tr *taskRepository) AddToMyDay(userId uint, taskId uint, date time.Time) error {
+	// 日付はタイムゾーンの変換を受けないよう文字列で渡す
+	if err := tr.db.Exec("INSERT INTO my_day_entries (user_id, task_id, date, created_at) VALUES (?, ?, CAST(? AS date), NOW()) ON CONFLICT DO NOTHING",
+		userId, taskId, date.Format("2006-01-02")).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 指定日の「My Day」からタスクを外す
+func (tr *taskRepository) RemoveFromMyDay(userId uint, taskId uint, date time.Time) error {
+	result := tr.db.Where("user_id=? AND task_id=? AND date=CAST(? AS date)", userId, taskId, date.Format("2006-01-02")).
+		Delete(&model.MyDayEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 削除された行数が0の場合、追加されていないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 過去の日付の「My Day」の選択を削除
+// タイムゾーンによって日付が異なるため、2日以上前のものだけを削除する
+func (tr *taskRepository) DeleteExpiredMyDayEntries(now time.Time) error {
+	if err := tr.db.Where("date < CAST(? AS date)", now.AddDate(0, 0, -2).Format("2006-01-02")).
+		Delete(&model.MyDayEntry{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 作成者、または承諾待ち・承諾済みの担当者が閲覧できるタスクに絞り込む
+func readableBy(userId uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(tasks.user_id=? OR (tasks.assignee_id=? AND tasks.assignment_status IN ?))",
+			userId, userId, []string{model.AssignmentPending, model.AssignmentAccepted})
+	}
+}
+
+// 作成者、または承諾済みの担当者が更新できるタスクに絞り込む
+func writableBy(userId uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(tasks.user_id=? OR (tasks.assignee_id=? AND tasks.assignment_status=?))",
+			userId, userId, model.AssignmentAccepted)
+	}
+}
+
+// 1つのトランザクション内で複数の操作を実行
+// fn に渡されるリポジトリはトランザクションに紐づいており、fn がエラーを返すとすべてロールバックされる
+func (tr *taskRepository) Transaction(fn func(tr ITaskRepository) error) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&taskRepository{tx})
+	})
+}
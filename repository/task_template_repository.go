@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// タスクテンプレートに関するデータベース操作を定義
+type ITaskTemplateRepository interface {
+	GetAllTaskTemplates(templates *[]model.TaskTemplate, userId uint) error               // ユーザーのテンプレートをすべて取得
+	GetTaskTemplateById(template *model.TaskTemplate, userId uint, templateId uint) error // 特定のテンプレートを取得
+	CreateTaskTemplate(template *model.TaskTemplate) error                                // テンプレートを作成
+	UpdateTaskTemplate(template *model.TaskTemplate, userId uint, templateId uint) error  // テンプレートを更新
+	DeleteTaskTemplate(userId uint, templateId uint) error                                // テンプレートを削除
+}
+
+type taskTemplateRepository struct {
+	db *gorm.DB
+}
+
+// コンストラクタ関数
+func NewTaskTemplateRepository(db *gorm.DB) ITaskTemplateRepository {
+	return &taskTemplateRepository{db}
+}
+
+// ユーザーのテンプレートをすべて取得
+func (ttr *taskTemplateRepository) GetAllTaskTemplates(templates *[]model.TaskTemplate, userId uint) error {
+	if err := ttr.db.Where("user_id=?", userId).Order("name").Find(templates).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// 特定のテンプレートを取得
+func (ttr *taskTemplateRepository) GetTaskTemplateById(template *model.TaskTemplate, userId uint, templateId uint) error {
+	if err := ttr.db.Where("user_id=?", userId).First(template, templateId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// テンプレートを作成
+func (ttr *taskTemplateRepository) CreateTaskTemplate(template *model.TaskTemplate) error {
+	if err := ttr.db.Create(template).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// テンプレートを更新
+func (ttr *taskTemplateRepository) UpdateTaskTemplate(template *model.TaskTemplate, userId uint, templateId uint) error {
+	result := ttr.db.Model(template).Clauses(clause.Returning{}).Where("id=? AND user_id=?", templateId, userId).Updates(map[string]interface{}{
+		"name":        template.Name,
+		"description": template.Description,
+		"items":       template.Items,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、テンプレートが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// テンプレートを削除
+func (ttr *taskTemplateRepository) DeleteTaskTemplate(userId uint, templateId uint) error {
+	result := ttr.db.Where("id=? AND user_id=?", templateId, userId).Delete(&model.TaskTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 削除された行数が0の場合、テンプレートが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -1,14 +1,28 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
 	"gorm.io/gorm"
 )
 
 // ユーザー関連のDB操作のインターフェース
 type IUserRepository interface {
-	GetUserByEmail(user *model.User, email string) error // メールアドレスからユーザーを取得
-	CreateUser(user *model.User) error                   // ユーザーの新規作成
+	GetUserByEmail(user *model.User, email string) error                                            // メールアドレスからユーザーを取得
+	GetUserById(user *model.User, userId uint) error                                                // ユーザーIDからユーザーを取得
+	CreateUser(user *model.User) error                                                              // ユーザーの新規作成
+	UpdatePassword(userId uint, passwordHash string, validAfter time.Time) error                    // パスワードを変更し、それより前に発行したアクセストークンを無効にする
+	RevokeUserTokens(userId uint, validAfter time.Time) error                                       // validAfter より前に発行したアクセストークンを無効にする
+	SetEmailVerificationNonce(userId uint, nonce string, now time.Time, sentBefore time.Time) error // 確認リンクを再発行（前回の送信が sentBefore より前の場合のみ）
+	VerifyEmail(userId uint, nonce string, now time.Time) error                                     // 確認リンクの値が一致する場合にメールアドレスを確認済みにする
+	UpdateEmail(userId uint, email string, nonce string, now time.Time) error                       // メールアドレスを変更し、未確認の状態に戻す
+	DeleteUser(userId uint) error                                                                   // ユーザーを削除（関連するデータは外部キーの制約で削除される）
+	SetTotpSecret(userId uint, secret string) error                                                 // 2段階認証のシークレットを保存（有効にする前のみ）
+	EnableTotp(userId uint, secret string, step int64, now time.Time) error                         // 保存したシークレットで2段階認証を有効にする
+	DisableTotp(userId uint) error                                                                  // 2段階認証を無効にしてシークレットを削除
+	UseTotpStep(userId uint, step int64) error                                                      // 確認コードのステップを使用済みとして記録する
+	SetWebhookSecret(userId uint, secret string, current string) error                              // webhook の署名のシークレットを変更する（現在の値が一致する場合のみ）
 }
 
 // リポジトリの構造体（GORMのDB接続を保持）
@@ -30,6 +44,14 @@ func (ur *userRepository) GetUserByEmail(user *model.User, email string) error {
 	return nil
 }
 
+// ユーザーIDからユーザーを取得
+func (ur *userRepository) GetUserById(user *model.User, userId uint) error {
+	if err := ur.db.First(user, userId).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
 // ユーザーをDBに新規作成
 func (ur *userRepository) CreateUser(user *model.User) error {
 	//user作成
@@ -38,3 +60,174 @@ func (ur *userRepository) CreateUser(user *model.User) error {
 	}
 	return nil
 }
+
+// パスワードを変更し、validAfter より前に発行したアクセストークンを無効にする
+func (ur *userRepository) UpdatePassword(userId uint, passwordHash string, validAfter time.Time) error {
+	result := ur.db.Model(&model.User{}).Where("id=?", userId).Updates(map[string]interface{}{
+		"password":           passwordHash,
+		"tokens_valid_after": validAfter,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	// 更新された行数が0の場合、ユーザーが存在しないとみなす
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// validAfter より前に発行したアクセストークンを無効にする（すべての端末からのログアウト）
+func (ur *userRepository) RevokeUserTokens(userId uint, validAfter time.Time) error {
+	result := ur.db.Model(&model.User{}).Where("id=?", userId).Update("tokens_valid_after", validAfter)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 確認リンクの値を新しいものに置き換え、送信日時を記録する
+// 確認済みのユーザーや、前回の送信が sentBefore 以降（再送の間隔が短すぎる）の場合は更新せず ErrNotFound を返す
+func (ur *userRepository) SetEmailVerificationNonce(userId uint, nonce string, now time.Time, sentBefore time.Time) error {
+	result := ur.db.Model(&model.User{}).
+		Where("id=? AND email_verified_at IS NULL AND (email_verification_sent_at IS NULL OR email_verification_sent_at < ?)", userId, sentBefore).
+		Updates(map[string]interface{}{
+			"email_verification_nonce":   nonce,
+			"email_verification_sent_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 確認リンクの値が一致する場合にメールアドレスを確認済みにする
+// 値は空にするため、同じリンクは一度しか使えない（一致しない場合は ErrNotFound）
+func (ur *userRepository) VerifyEmail(userId uint, nonce string, now time.Time) error {
+	result := ur.db.Model(&model.User{}).
+		Where("id=? AND email_verification_nonce=? AND email_verification_nonce <> ''", userId, nonce).
+		Updates(map[string]interface{}{
+			"email_verified_at":        now,
+			"email_verification_nonce": "",
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// メールアドレスを変更し、新しいアドレスの確認リンクの値と送信日時を記録する
+// 新しいアドレスを確認するまでは未確認として扱う（登録済みのアドレスの場合は ErrDuplicate）
+func (ur *userRepository) UpdateEmail(userId uint, email string, nonce string, now time.Time) error {
+	result := ur.db.Model(&model.User{}).Where("id=?", userId).Updates(map[string]interface{}{
+		"email":                      email,
+		"email_verified_at":          nil,
+		"email_verification_nonce":   nonce,
+		"email_verification_sent_at": now,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ユーザーを削除する
+// タスクやセッションなどのユーザーに紐づくデータは、外部キーの OnDelete:CASCADE により削除される
+func (ur *userRepository) DeleteUser(userId uint) error {
+	result := ur.db.Delete(&model.User{}, userId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 2段階認証のシークレットを保存する（設定の確定は EnableTotp）
+// すでに有効な場合は変更せず ErrNotFound を返す
+func (ur *userRepository) SetTotpSecret(userId uint, secret string) error {
+	result := ur.db.Model(&model.User{}).Where("id=? AND totp_enabled_at IS NULL", userId).Update("totp_secret", secret)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 保存したシークレットが secret と一致する場合に2段階認証を有効にする
+// step は設定の確認に使ったコードのステップで、同じコードをログインに使えないよう記録する
+// すでに有効な場合やシークレットが変わっている場合は ErrNotFound を返す
+func (ur *userRepository) EnableTotp(userId uint, secret string, step int64, now time.Time) error {
+	result := ur.db.Model(&model.User{}).
+		Where("id=? AND totp_secret=? AND totp_enabled_at IS NULL", userId, secret).
+		Updates(map[string]interface{}{
+			"totp_enabled_at": now,
+			"totp_last_step":  step,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 2段階認証を無効にしてシークレットを削除する
+func (ur *userRepository) DisableTotp(userId uint) error {
+	result := ur.db.Model(&model.User{}).Where("id=?", userId).Updates(map[string]interface{}{
+		"totp_secret":     "",
+		"totp_enabled_at": nil,
+		"totp_last_step":  0,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// 確認コードのステップを使用済みとして記録する
+// 記録済みのステップ以前のコード（使用済みのコードや、それより古いコード）の場合は ErrNotFound を返す
+func (ur *userRepository) UseTotpStep(userId uint, step int64) error {
+	result := ur.db.Model(&model.User{}).
+		Where("id=? AND totp_enabled_at IS NOT NULL AND totp_last_step < ?", userId, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// webhook の署名のシークレットを secret に変更する
+// 同時に生成・再発行されて現在の値が current と異なる場合は変更せず ErrNotFound を返す
+func (ur *userRepository) SetWebhookSecret(userId uint, secret string, current string) error {
+	result := ur.db.Model(&model.User{}).Where("id=? AND webhook_secret=?", userId, current).Update("webhook_secret", secret)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -3,23 +3,43 @@ package router
 import (
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/DaigoSugiyama0317/Echo-REST-API/controller"
-	echojwt "github.com/labstack/echo-jwt/v4"
+	appmiddleware "github.com/DaigoSugiyama0317/Echo-REST-API/middleware"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 // Echoのインスタンスを作成、ルートやミドルウェアを設定
-// 引数として受け取るucとtcは、ユーザーとタスクのコントローラインターフェース
-func NewRouter(uc controller.IUserController, tc controller.ITaskController) *echo.Echo {
+// 引数として受け取るuc、tc、fc、sc、ttc、nc、cc、clc、ec、rc、syc、tdc、pc、sec、ptc、tfc、pkc は、ユーザー、タスク、保存済みフィルター、統計情報、
+// タスクテンプレート、通知、コメント、チェックリスト、メール配信設定、リマインダー、差分同期、今日の計画、
+// プロジェクト、セッション、パーソナルアクセストークン、2段階認証、パスキーのコントローラインターフェース
+// idempotency は Idempotency-Key ヘッダーによる再送の重複防止ミドルウェア
+// revocation はログアウトなどで失効したアクセストークンを拒否するミドルウェア
+// pat はパーソナルアクセストークンで認証するミドルウェア
+// verifiedEmail はメールアドレスを確認していないユーザーのタスクの作成を拒否するミドルウェア（方針により何もしない）
+func NewRouter(uc controller.IUserController, tc controller.ITaskController, fc controller.ISavedFilterController,
+	sc controller.IStatsController, ttc controller.ITaskTemplateController, nc controller.INotificationController,
+	cc controller.ICommentController, clc controller.IChecklistController, ec controller.IEmailPreferenceController, rc controller.IReminderController,
+	syc controller.ISyncController, tdc controller.ITodayController, pc controller.IProjectController, sec controller.ISessionController,
+	ptc controller.IPersonalAccessTokenController, tfc controller.ITwoFactorController, pkc controller.IPasskeyController,
+	idempotency echo.MiddlewareFunc, revocation echo.MiddlewareFunc, pat echo.MiddlewareFunc, verifiedEmail echo.MiddlewareFunc) *echo.Echo {
 	e := echo.New()
+	// エラーは RFC 7807 の application/problem+json 形式で返す
+	e.HTTPErrorHandler = controller.HTTPErrorHandler
+
+	// リクエストごとにIDを発行し、X-Request-Id ヘッダーとエラーレスポンスに含める（クライアントが指定した場合はその値を使う）
+	e.Use(middleware.RequestID())
 
 	// CORSミドルウェアを設定し、特定のオリジンからのリクエストを許可
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"http://localhost:3000", os.Getenv("FE_URL")}, // フロントエンドのURLを許可
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept,
-			echo.HeaderAccessControlAllowHeaders, echo.HeaderXCSRFToken},
+			echo.HeaderAccessControlAllowHeaders, echo.HeaderXCSRFToken, appmiddleware.HeaderIdempotencyKey, echo.HeaderXRequestID},
+		// 再送に保存済みのレスポンスを返したかどうか、リクエストID
+		ExposeHeaders:    []string{appmiddleware.HeaderIdempotentReplayed, echo.HeaderXRequestID},
 		AllowMethods:     []string{"GET", "PUT", "POST", "DELETE"}, // 許可するHTTPメソッド
 		AllowCredentials: true,                                     // クッキーの送信を許可
 	})) // 許可するヘッダ
@@ -31,26 +51,250 @@ func NewRouter(uc controller.IUserController, tc controller.ITaskController) *ec
 		CookieHTTPOnly: true,                    // クッキーのJavaScriptアクセスを無効化
 		CookieSameSite: http.SameSiteNoneMode,   // クロスサイトリクエスト時にクッキーを送信する
 		CookieMaxAge:   60,                      // クッキーの有効期限（秒）
+		// 配信停止リンクはメールクライアントから直接送信されるため、CSRFトークンを要求しない
+		// Cookieを使わないエンドポイント（/auth/token）と、Authorization: Bearer で認証するリクエストも対象外
+		// （Bearer のリクエストはヘッダーのトークンのみで認証し、Cookieは使わない）
+		// パスキーの認証オプションの取得は状態を変更せず、モバイルアプリからも呼び出すため対象外
+		Skipper: func(c echo.Context) bool {
+			if _, ok := appmiddleware.BearerToken(c); ok {
+				return true
+			}
+			switch c.Path() {
+			case "/unsubscribe", "/auth/token", "/auth/token/mfa", "/auth/token/refresh",
+				"/auth/token/passkey", "/auth/token/mfa/passkey", "/login/passkey/options", "/login/mfa/passkey/options":
+				return true
+			}
+			return false
+		},
 	}))
 
 	// ユーザー関連のエンドポイントを設定
-	e.POST("/signup", uc.SignUp) // サインアップ
-	e.POST("/login", uc.LogIn)   // ログイン
-	e.POST("/logout", uc.LogOut) // ログアウト
-	e.GET("/csrf", uc.CsrfToken) // CSRFトークン取得
+	e.POST("/signup", uc.SignUp, idempotency) // サインアップ（Idempotency-Key で再送時の重複登録を防止）
+	e.POST("/login", uc.LogIn)                // ログイン
+	e.POST("/logout", uc.LogOut)              // ログアウト
+	e.POST("/token/refresh", uc.RefreshToken) // リフレッシュトークンによるトークンの再発行
+	e.GET("/csrf", uc.CsrfToken)              // CSRFトークン取得
+
+	// メールを送信するエンドポイントは、大量送信を防ぐため、ユーザーごとの間隔に加えてIPアドレスごとに1時間あたり5回までに制限する
+	// （エンドポイントごとに別々に数える）
+	mailLimit := func() echo.MiddlewareFunc {
+		return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+			Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+				Rate:      5.0 / 3600, // 1秒あたりの回数
+				Burst:     5,
+				ExpiresIn: time.Hour,
+			}),
+		})
+	}
+
+	// 2段階認証の確認コードは総当たりを防ぐため、IPアドレスごとに1分あたり10回までに制限する
+	codeLimit := func() echo.MiddlewareFunc {
+		return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+			Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+				Rate:      10.0 / 60, // 1秒あたりの回数
+				Burst:     10,
+				ExpiresIn: time.Minute,
+			}),
+		})
+	}
+
+	// 2段階認証が有効なユーザーのログインの2段階目（POST /login、/auth/token が返した mfa_token と確認コードを送信）
+	// パスキーを登録しているユーザーは、下の /login/mfa/passkey でパスキーを使う
+	e.POST("/login/mfa", uc.LogInMfa, codeLimit())           // トークンをCookieでセット
+	e.POST("/auth/token/mfa", uc.IssueTokenMfa, codeLimit()) // トークンをレスポンスボディで返す
+
+	// パスキー（WebAuthn）によるパスワードなしのログイン（options で取得したオプションで navigator.credentials.get() を呼び出し、結果を送信）
+	e.POST("/login/passkey/options", pkc.BeginLogin, codeLimit()) // 認証オプションを取得
+	e.POST("/login/passkey", pkc.LogIn)                           // トークンをCookieでセット
+	e.POST("/auth/token/passkey", pkc.IssueToken)                 // トークンをレスポンスボディで返す
+
+	// ログインの2段階目を確認コードの代わりにパスキーで行う（options に mfa_token を送信）
+	e.POST("/login/mfa/passkey/options", pkc.BeginMfaLogin, codeLimit()) // 認証オプションを取得
+	e.POST("/login/mfa/passkey", pkc.LogInMfa)                           // トークンをCookieでセット
+	e.POST("/auth/token/mfa/passkey", pkc.IssueTokenMfa)                 // トークンをレスポンスボディで返す
+
+	// メールアドレスの確認（認証不要）
+	e.POST("/verify-email", uc.VerifyEmail)                                 // 確認リンクのトークンでメールアドレスを確認
+	e.POST("/verify-email/resend", uc.ResendVerificationEmail, mailLimit()) // 確認リンクを再送
+
+	// パスワードを忘れた場合の再設定（認証不要）
+	e.POST("/password/forgot", uc.ForgotPassword, mailLimit()) // 再設定リンクをメールで送信（登録の有無に関わらず 202）
+	e.POST("/password/reset", uc.ResetPassword)                // 再設定リンクのトークンと新しいパスワードで再設定
+
+	// CLIやモバイルアプリ向けのトークン発行（Cookieではなくレスポンスボディでトークンを返す）
+	e.POST("/auth/token", uc.IssueToken)                 // メールアドレスとパスワードでトークンを発行
+	e.POST("/auth/token/refresh", uc.RefreshTokenInBody) // リクエストボディのリフレッシュトークンでトークンを再発行
+
+	// メールの配信停止リンク（認証不要、トークンで本人を識別）
+	e.GET("/unsubscribe", ec.Unsubscribe)
+	e.POST("/unsubscribe", ec.Unsubscribe)
 
 	// タスク関連のエンドポイント
 	// このグループ内のエンドポイントはJWT認証を使用
+	// 認証済みのグループでは、JWT認証の後に Idempotency-Key をユーザーごとに管理する
+	// トークンは Authorization: Bearer ヘッダー、ない場合はクッキーから取得
+	jwtAuth := appmiddleware.JWTAuth([]byte(os.Getenv("SECRET"))) // JWT署名に使用する秘密鍵
+	// 署名と有効期限を検証した後、ログアウトなどで失効したトークンでないことを確認する
+	jwtMiddleware := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return jwtAuth(revocation(next))
+	}
+	// パーソナルアクセストークンでも利用できるエンドポイントの認証
+	// パーソナルアクセストークンの場合は、GET では readScope、それ以外では writeScope のスコープが必要（ない場合は 403）
+	// アカウントの管理（セッション、パーソナルアクセストークン、パスワードなど）は jwtMiddleware のみとし、トークンからは操作できない
+	scoped := func(readScope string, writeScope string) echo.MiddlewareFunc {
+		requireScope := appmiddleware.RequireScope(readScope, writeScope)
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			withJWT, withPAT := jwtMiddleware(next), pat(requireScope(next))
+			return func(c echo.Context) error {
+				if appmiddleware.IsPersonalAccessToken(c) {
+					return withPAT(c)
+				}
+				return withJWT(c)
+			}
+		}
+	}
+
+	// ログイン中のユーザーのアカウントに関するエンドポイント（JWT認証を使用）
+	e.POST("/logout/all", uc.LogOutAll, jwtMiddleware)   // すべての端末からログアウト
+	e.PUT("/password", uc.ChangePassword, jwtMiddleware) // パスワードを変更（ほかの端末のトークンはすべて失効）
+
+	// ログイン中のユーザーのプロフィールの管理のエンドポイント（JWT認証を使用）
+	// 2段階認証のシークレットやリカバリーコード、パスキーの登録の応答が idempotency_keys に保存されないよう、Idempotency-Key は使わない
+	me := e.Group("/me")
+	me.Use(jwtMiddleware)
+	me.GET("", uc.GetMe)                   // ユーザーの情報を取得
+	me.PUT("/password", uc.ChangePassword) // パスワードを変更（PUT /password と同じ）
+	me.PUT("/email", uc.ChangeEmail)       // メールアドレスを変更（新しいアドレスの確認が必要）
+	me.DELETE("", uc.DeleteAccount)        // パスワードを確認してアカウントを削除（タスクなどのデータもすべて削除）
+
+	// リマインダーの webhook の署名のシークレット
+	me.GET("/webhook-secret", rc.GetWebhookSecret)            // シークレットを取得（まだない場合は生成）
+	me.POST("/webhook-secret/rotate", rc.RotateWebhookSecret) // シークレットを再発行
+
+	// 2段階認証（TOTP）の設定のエンドポイント（確認コードを送信するものは総当たりを防ぐため回数を制限）
+	tf := me.Group("/2fa")
+	tf.GET("", tfc.GetStatus)                                            // 設定状況と未使用のリカバリーコードの数を取得
+	tf.POST("/setup", tfc.Setup)                                         // シークレットと provisioning_uri（QRコード用）を発行して設定を開始
+	tf.POST("/confirm", tfc.Confirm, codeLimit())                        // 認証アプリのコードで設定を確定し、リカバリーコードを発行
+	tf.POST("/recovery-codes", tfc.RegenerateRecoveryCodes, codeLimit()) // リカバリーコードを再発行（以前のコードは使えなくなる）
+	tf.DELETE("", tfc.Disable, codeLimit())                              // 確認コードかリカバリーコードで2段階認証を無効にする
+
+	// パスキー（WebAuthn）の管理
+	pk := me.Group("/passkeys")
+	pk.GET("", pkc.GetPasskeys)                 // 登録済みのパスキーの一覧を取得
+	pk.POST("/options", pkc.BeginRegistration)  // 名前を送信し、navigator.credentials.create() に渡すオプションを取得
+	pk.POST("", pkc.FinishRegistration)         // navigator.credentials.create() の結果を送信してパスキーを登録
+	pk.DELETE("/:passkeyId", pkc.DeletePasskey) // パスキーを削除
+
+	// ログインした端末（セッション）の管理のエンドポイント（JWT認証を使用）
+	se := e.Group("/sessions")
+	se.Use(jwtMiddleware, idempotency)
+	se.GET("", sec.GetSessions)                 // 有効なセッションの一覧を取得（端末の User-Agent、IPアドレス、ログイン日時、最終アクセス日時）
+	se.DELETE("/:sessionId", sec.RevokeSession) // セッションを失効させ、その端末をログアウトさせる
+
+	// パーソナルアクセストークンの管理のエンドポイント（JWT認証を使用）
+	// 作成のレスポンスにはトークンの平文が含まれるため、idempotency_keys に保存されないよう Idempotency-Key は使わない
+	pt := e.Group("/tokens")
+	pt.Use(jwtMiddleware)
+	pt.GET("", ptc.GetPersonalAccessTokens)               // トークン一覧を取得
+	pt.GET("/:tokenId", ptc.GetPersonalAccessTokenById)   // ID指定でトークンを取得
+	pt.POST("", ptc.CreatePersonalAccessToken)            // 新しいトークンを作成（トークンはこのレスポンスでのみ返す）
+	pt.PUT("/:tokenId", ptc.UpdatePersonalAccessToken)    // トークンの名前とスコープを更新
+	pt.DELETE("/:tokenId", ptc.DeletePersonalAccessToken) // トークンを削除
+
 	t := e.Group("/tasks")
-	t.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(os.Getenv("SECRET")), // JWT署名に使用する秘密鍵
-		TokenLookup: "cookie:token",              // トークンはクッキーから取得
-	}))
+	t.Use(scoped(model.ScopeTasksRead, model.ScopeTasksWrite), idempotency)
 	// タスク関連のエンドポイントを設定
-	t.GET("", tc.GetAllTasks)           // すべてのタスクを取得
-	t.GET("/:taskId", tc.GetTaskById)   // ID指定でタスクを取得
-	t.POST("", tc.CreateTask)           // 新しいタスクを作成
-	t.PUT("/:taskId", tc.UpdateTask)    // タスクを更新
-	t.DELETE("/:taskId", tc.DeleteTask) // タスクを削除
+	t.GET("", tc.GetAllTasks)                                                             // すべてのタスクを取得
+	t.GET("/:taskId", tc.GetTaskById)                                                     // ID指定でタスクを取得
+	t.POST("", tc.CreateTask, verifiedEmail)                                              // 新しいタスクを作成
+	t.POST("/quick", tc.QuickAddTask, verifiedEmail)                                      // 1行のテキストからタスクを作成（dry_run の確認も同じ方針を適用する）
+	t.PUT("/:taskId", tc.UpdateTask)                                                      // タスクを更新
+	t.DELETE("/:taskId", tc.DeleteTask)                                                   // タスクを削除
+	t.POST("/:taskId/assign", tc.AssignTask)                                              // タスクを他のユーザーに割り当て（作成者のみ）
+	t.DELETE("/:taskId/assign", tc.UnassignTask)                                          // 割り当てを解除（作成者のみ）
+	t.POST("/:taskId/accept", tc.AcceptAssignment)                                        // 割り当てを承諾（担当者のみ）
+	t.POST("/:taskId/decline", tc.DeclineAssignment)                                      // 割り当てを辞退（担当者のみ）
+	t.POST("/:taskId/snooze", tc.SnoozeTask)                                              // 指定した日時までタスクをスヌーズ（一覧に表示しない）
+	t.DELETE("/:taskId/snooze", tc.UnsnoozeTask)                                          // スヌーズを解除
+	t.GET("/:taskId/comments", cc.GetComments)                                            // タスクのコメント一覧を取得
+	t.POST("/:taskId/comments", cc.CreateComment)                                         // コメントを作成（@メールアドレス でメンション）
+	t.DELETE("/:taskId/comments/:commentId", cc.DeleteComment)                            // 自分のコメントを削除
+	t.GET("/:taskId/checklist", clc.GetChecklistItems)                                    // タスクのチェックリストを取得
+	t.POST("/:taskId/checklist", clc.CreateChecklistItem)                                 // チェックリストの末尾に項目を追加
+	t.PUT("/:taskId/checklist/order", clc.ReorderChecklistItems)                          // 項目を並べ替え
+	t.PUT("/:taskId/checklist/:itemId", clc.UpdateChecklistItem)                          // 項目のテキストとチェック状態を更新
+	t.POST("/:taskId/checklist/:itemId/toggle", clc.ToggleChecklistItem)                  // 項目のチェック状態を切り替え
+	t.POST("/:taskId/checklist/:itemId/convert", clc.ConvertChecklistItem, verifiedEmail) // 項目をサブタスクに変換
+	t.DELETE("/:taskId/checklist/:itemId", clc.DeleteChecklistItem)                       // 項目を削除
+	t.GET("/:taskId/reminders", rc.GetReminders)                                          // タスクに設定した自分のリマインダーを取得
+	t.POST("/:taskId/reminders", rc.CreateReminder)                                       // リマインダーを作成（期限の何分前に、どの方法で通知するか）
+	t.DELETE("/:taskId/reminders/:reminderId", rc.DeleteReminder)                         // リマインダーを削除
+
+	// 保存済みフィルター関連のエンドポイント（JWT認証を使用）
+	f := e.Group("/filters")
+	f.Use(scoped(model.ScopeTasksRead, model.ScopeTasksWrite), idempotency)
+	f.GET("", fc.GetAllSavedFilters)               // フィルター一覧を取得
+	f.GET("/:filterId", fc.GetSavedFilterById)     // ID指定でフィルターを取得
+	f.GET("/:filterId/tasks", fc.GetFilteredTasks) // フィルターを適用してタスクを取得
+	f.POST("", fc.CreateSavedFilter)               // 新しいフィルターを作成
+	f.PUT("/:filterId", fc.UpdateSavedFilter)      // フィルターを更新
+	f.DELETE("/:filterId", fc.DeleteSavedFilter)   // フィルターを削除
+
+	// プロジェクトとカスタムフィールドの定義のエンドポイント（JWT認証を使用）
+	p := e.Group("/projects")
+	p.Use(scoped(model.ScopeProjectsRead, model.ScopeProjectsWrite), idempotency)
+	p.GET("", pc.GetAllProjects)                                  // プロジェクト一覧を取得
+	p.GET("/:projectId", pc.GetProjectById)                       // ID指定でプロジェクトを取得
+	p.POST("", pc.CreateProject)                                  // 新しいプロジェクトを作成
+	p.PUT("/:projectId", pc.UpdateProject)                        // プロジェクトを更新
+	p.DELETE("/:projectId", pc.DeleteProject)                     // プロジェクトを削除（タスクはプロジェクトから外れる）
+	p.GET("/:projectId/fields", pc.GetCustomFields)               // カスタムフィールドの一覧を取得
+	p.POST("/:projectId/fields", pc.CreateCustomField)            // カスタムフィールドを追加
+	p.PUT("/:projectId/fields/:fieldId", pc.UpdateCustomField)    // カスタムフィールドを更新
+	p.DELETE("/:projectId/fields/:fieldId", pc.DeleteCustomField) // カスタムフィールドを削除（タスクの値も削除される）
+
+	// 統計情報のエンドポイント（JWT認証を使用）
+	s := e.Group("/stats")
+	s.Use(scoped(model.ScopeStatsRead, model.ScopeStatsRead))
+	s.GET("", sc.GetStats) // 作成・完了数やバーンダウンなどの統計情報を取得
+
+	// タスクテンプレート関連のエンドポイント（JWT認証を使用）
+	tt := e.Group("/templates")
+	tt.Use(scoped(model.ScopeTasksRead, model.ScopeTasksWrite), idempotency)
+	tt.GET("", ttc.GetAllTaskTemplates)                                 // テンプレート一覧を取得
+	tt.GET("/:templateId", ttc.GetTaskTemplateById)                     // ID指定でテンプレートを取得
+	tt.POST("", ttc.CreateTaskTemplate)                                 // 新しいテンプレートを作成
+	tt.PUT("/:templateId", ttc.UpdateTaskTemplate)                      // テンプレートを更新
+	tt.DELETE("/:templateId", ttc.DeleteTaskTemplate)                   // テンプレートを削除
+	tt.POST("/:templateId/instantiate", ttc.Instantiate, verifiedEmail) // テンプレートからタスクを一括作成
+
+	// 通知関連のエンドポイント（JWT認証を使用）
+	n := e.Group("/notifications")
+	n.Use(scoped(model.ScopeNotificationsRead, model.ScopeNotificationsWrite), idempotency)
+	n.GET("", nc.GetNotifications)                 // 通知一覧を取得（?unread=true で未読のみ）
+	n.GET("/unread-count", nc.GetUnreadCount)      // 未読件数を取得
+	n.GET("/stream", nc.Stream)                    // 通知をリアルタイムに受信（Server-Sent Events）
+	n.POST("/:notificationId/read", nc.MarkAsRead) // 通知を既読にする
+	n.POST("/read-all", nc.MarkAllAsRead)          // すべての通知を既読にする
+
+	// メール配信設定のエンドポイント（JWT認証を使用）
+	ep := e.Group("/email-preferences")
+	ep.Use(scoped(model.ScopeNotificationsRead, model.ScopeNotificationsWrite), idempotency)
+	ep.GET("", ec.GetEmailPreference)    // 配信設定を取得
+	ep.PUT("", ec.UpdateEmailPreference) // 配信設定（種類ごとの配信停止、言語、ダイジェストの送信時刻）を更新
+
+	// 今日の計画（My Day）のエンドポイント（JWT認証を使用）
+	td := e.Group("/today")
+	td.Use(scoped(model.ScopeTasksRead, model.ScopeTasksWrite), idempotency)
+	td.GET("", tdc.GetToday)                         // My Day と今日が期限・期限切れのタスクを取得
+	td.POST("/tasks/:taskId", tdc.AddToMyDay)        // 今日の My Day にタスクを追加
+	td.DELETE("/tasks/:taskId", tdc.RemoveFromMyDay) // 今日の My Day からタスクを外す
+
+	// オフライン対応クライアント向けの差分同期のエンドポイント（JWT認証を使用）
+	sy := e.Group("/sync")
+	sy.Use(scoped(model.ScopeTasksRead, model.ScopeTasksWrite), idempotency)
+	sy.GET("", syc.GetChanges)                   // since トークン以降の変更を取得
+	sy.POST("", syc.ApplyChanges, verifiedEmail) // オフラインでの変更をまとめて適用
 	return e
 }
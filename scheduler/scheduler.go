@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// 現在時刻を返すインターフェース
+// テストでは固定の時刻を返す実装に差し替えることで、ジョブを任意の時刻で実行できる
+type Clock interface {
+	Now() time.Time
+}
+
+// システムの時計を使う実装
+type systemClock struct{}
+
+// コンストラクタ関数
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+// 現在時刻を返す
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// 定期的に実行するジョブ
+// now には Clock から取得した時刻が渡される
+type JobFunc func(now time.Time) error
+
+// バックグラウンドで定期的にジョブを実行するスケジューラー
+// 複数のインスタンスで同時に動かすことを前提とし、重複実行の防止は各ジョブ側（SKIP LOCKED など）で行う
+type IScheduler interface {
+	Register(name string, interval time.Duration, job JobFunc) // ジョブを登録
+	Start(ctx context.Context)                                 // 登録されたジョブの定期実行を開始（ctx がキャンセルされると停止）
+	RunOnce()                                                  // 登録されたすべてのジョブを現在時刻で1回ずつ実行
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+type scheduler struct {
+	clock Clock
+	mu    sync.Mutex
+	jobs  []job
+}
+
+// コンストラクタ関数
+func NewScheduler(clock Clock) IScheduler {
+	return &scheduler{clock: clock}
+}
+
+// ジョブを登録
+func (s *scheduler) Register(name string, interval time.Duration, run JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job{name, interval, run})
+}
+
+// 登録されたジョブの定期実行を開始
+// ジョブごとに goroutine を起動し、前回の実行が終わってから次の実行を行う
+func (s *scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		go func(j job) {
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					s.run(j)
+				}
+			}
+		}(j)
+	}
+}
+
+// 登録されたすべてのジョブを現在時刻で1回ずつ実行
+func (s *scheduler) RunOnce() {
+	s.mu.Lock()
+	jobs := append([]job{}, s.jobs...)
+	s.mu.Unlock()
+	for _, j := range jobs {
+		s.run(j)
+	}
+}
+
+// ジョブを実行し、エラーやパニックはログに出力する（ほかのジョブや次回の実行は継続する）
+func (s *scheduler) run(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("scheduler: job %s panicked: %v", j.name, r)
+		}
+	}()
+	if err := j.run(s.clock.Now()); err != nil {
+		log.Printf("scheduler: job %s failed: %v", j.name, err)
+	}
+}
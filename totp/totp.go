@@ -0,0 +1,90 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RFC 6238 の時間ベースのワンタイムパスワード（Google Authenticator などの認証アプリと互換）
+// HMAC-SHA1、30秒ごと、6桁の既定の設定のみに対応する
+
+const (
+	Period = 30 // コードが切り替わる間隔（秒）
+	Digits = 6  // コードの桁数
+	Skew   = 1  // 時計のずれを許容する前後のステップ数
+
+	secretSize = 20      // シークレットのバイト数（RFC 4226 の推奨は160ビット）
+	modulus    = 1000000 // 10 の Digits 乗
+)
+
+// 認証アプリのシークレットの表記（パディングなしの Base32）
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// 新しいシークレットを生成（Base32 で表した文字列）
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// 認証アプリに登録するための otpauth:// のURI（クライアントでQRコードにして表示する）
+func ProvisioningURI(issuer string, account string, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(Period))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// 日時に対応するステップ（Unix時間を Period で割った値）
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// ステップに対応するコード
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	// 動的切り捨て（RFC 4226 5.3）
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%modulus), nil
+}
+
+// コードを検証し、一致したステップを返す
+// 時計のずれを考慮して前後 Skew ステップまで受け付ける（同じコードの再利用は呼び出し元でステップを記録して防ぐ）
+func Validate(secret string, code string, now time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+	current := Step(now)
+	for step := current - Skew; step <= current+Skew; step++ {
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
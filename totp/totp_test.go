@@ -0,0 +1,128 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B の SHA1 のシークレット（"12345678901234567890"）を Base32 で表したもの
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 Appendix B のテストベクター（SHA1、8桁のコードの下6桁）
+var rfcVectors = []struct {
+	unix int64
+	step int64
+	code string
+}{
+	{59, 0x1, "287082"},                 // 94287082
+	{1111111109, 0x23523EC, "081804"},   // 07081804
+	{1111111111, 0x23523ED, "050471"},   // 14050471
+	{1234567890, 0x273EF07, "005924"},   // 89005924
+	{2000000000, 0x3F940AA, "279037"},   // 69279037
+	{20000000000, 0x27BC86AA, "353130"}, // 65353130
+}
+
+func TestCode(t *testing.T) {
+	for _, v := range rfcVectors {
+		at := time.Unix(v.unix, 0)
+		if step := Step(at); step != v.step {
+			t.Errorf("Step(%d) = %#x, want %#x", v.unix, step, v.step)
+		}
+		code, err := Code(rfcSecret, Step(at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Errorf("Code at %d = %s, want %s", v.unix, code, v.code)
+		}
+		// 小文字のシークレットも受け付ける
+		if lower, _ := Code(strings.ToLower(rfcSecret), Step(at)); lower != v.code {
+			t.Errorf("Code with lowercase secret at %d = %s, want %s", v.unix, lower, v.code)
+		}
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("expected error for invalid secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0) // ステップ 0x23523ED
+	current := Step(now)
+	code := func(step int64) string {
+		c, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name string
+		code string
+		step int64
+		ok   bool
+	}{
+		{"現在のステップ", "050471", current, true},
+		{"1つ前のステップ", code(current - 1), current - 1, true},
+		{"1つ後のステップ", code(current + 1), current + 1, true},
+		{"2つ前のステップ", code(current - 2), 0, false},
+		{"2つ後のステップ", code(current + 2), 0, false},
+		{"桁数が違う", "50471", 0, false},
+		{"8桁のコード", "14050471", 0, false},
+		{"空", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.ok || step != tt.step {
+				t.Errorf("Validate(%q) = %#x, %v, want %#x, %v", tt.code, step, ok, tt.step, tt.ok)
+			}
+		})
+	}
+
+	if _, ok := Validate("not base32!", "050471", now); ok {
+		t.Error("Validate accepted a code for an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not unpadded base32: %v", secret, err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("secret is %d bytes, want %d", len(key), secretSize)
+	}
+	if other, _ := GenerateSecret(); other == secret {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Task Manager", "user@example.com", rfcSecret)
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("URI = %s", uri)
+	}
+	if label := strings.TrimPrefix(u.Path, "/"); label != "Task Manager:user@example.com" {
+		t.Errorf("label = %q", label)
+	}
+	want := map[string]string{"secret": rfcSecret, "issuer": "Task Manager", "algorithm": "SHA1", "digits": "6", "period": "30"}
+	for k, v := range want {
+		if got := u.Query().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// 並べ替えの指定がタスクのチェックリストと一致しない場合のエラー
+var ErrInvalidChecklistOrder = newError(KindInvalid, "invalid_checklist_order", "item_ids must list every checklist item of the task exactly once")
+
+// タスク内のチェックリストに関するユースケースを定義
+type IChecklistUsecase interface {
+	GetChecklistItems(userId uint, taskId uint) ([]model.ChecklistItemResponse, error)                                        // チェックリストを取得
+	CreateChecklistItem(item model.ChecklistItem, userId uint, taskId uint) (model.ChecklistItemResponse, error)              // 項目を追加
+	UpdateChecklistItem(item model.ChecklistItem, userId uint, taskId uint, itemId uint) (model.ChecklistItemResponse, error) // 項目を更新
+	ToggleChecklistItem(userId uint, taskId uint, itemId uint) (model.ChecklistItemResponse, error)                           // チェック状態を切り替える
+	ReorderChecklistItems(userId uint, taskId uint, itemIds []uint) ([]model.ChecklistItemResponse, error)                    // 項目を並べ替える
+	DeleteChecklistItem(userId uint, taskId uint, itemId uint) error                                                          // 項目を削除
+	ConvertChecklistItem(userId uint, taskId uint, itemId uint) (model.TaskResponse, error)                                   // 項目をサブタスクに変換
+}
+
+type checklistUsecase struct {
+	clr repository.IChecklistRepository // チェックリストのリポジトリ
+	clv validator.IChecklistValidator   // チェックリスト項目のバリデーション
+	tv  validator.ITaskValidator        // 変換後のサブタスクのバリデーション
+}
+
+// コンストラクタ関数
+func NewChecklistUsecase(clr repository.IChecklistRepository, clv validator.IChecklistValidator,
+	tv validator.ITaskValidator) IChecklistUsecase {
+	return &checklistUsecase{clr, clv, tv}
+}
+
+// チェックリストを表示順に取得（タスクを閲覧できるユーザーのみ）
+func (clu *checklistUsecase) GetChecklistItems(userId uint, taskId uint) ([]model.ChecklistItemResponse, error) {
+	items := []model.ChecklistItem{}
+	if err := clu.clr.GetChecklistItems(&items, userId, taskId); err != nil {
+		return nil, err
+	}
+	return toChecklistItemResponses(items), nil
+}
+
+// 項目をチェックリストの末尾に追加（タスクを更新できるユーザーのみ）
+func (clu *checklistUsecase) CreateChecklistItem(item model.ChecklistItem, userId uint, taskId uint) (model.ChecklistItemResponse, error) {
+	if err := clu.clv.ChecklistItemValidate(item); err != nil {
+		return model.ChecklistItemResponse{}, err
+	}
+	if err := clu.clr.CreateChecklistItem(&item, userId, taskId); err != nil {
+		return model.ChecklistItemResponse{}, err
+	}
+	return toChecklistItemResponse(item), nil
+}
+
+// 項目のテキストとチェック状態を更新
+func (clu *checklistUsecase) UpdateChecklistItem(item model.ChecklistItem, userId uint, taskId uint, itemId uint) (model.ChecklistItemResponse, error) {
+	if err := clu.clv.ChecklistItemValidate(item); err != nil {
+		return model.ChecklistItemResponse{}, err
+	}
+	if err := clu.clr.UpdateChecklistItem(&item, userId, taskId, itemId); err != nil {
+		return model.ChecklistItemResponse{}, err
+	}
+	return toChecklistItemResponse(item), nil
+}
+
+// 項目のチェック状態を切り替える
+func (clu *checklistUsecase) ToggleChecklistItem(userId uint, taskId uint, itemId uint) (model.ChecklistItemResponse, error) {
+	item := model.ChecklistItem{}
+	if err := clu.clr.ToggleChecklistItem(&item, userId, taskId, itemId); err != nil {
+		return model.ChecklistItemResponse{}, err
+	}
+	return toChecklistItemResponse(item), nil
+}
+
+// 項目を指定された順序に並べ替える
+// itemIds にはタスクのすべての項目を重複なく指定する
+func (clu *checklistUsecase) ReorderChecklistItems(userId uint, taskId uint, itemIds []uint) ([]model.ChecklistItemResponse, error) {
+	current := []model.ChecklistItem{}
+	if err := clu.clr.GetChecklistItems(&current, userId, taskId); err != nil {
+		return nil, err
+	}
+	if len(itemIds) != len(current) {
+		return nil, ErrInvalidChecklistOrder
+	}
+	remaining := map[uint]bool{}
+	for _, v := range current {
+		remaining[v.ID] = true
+	}
+	for _, id := range itemIds {
+		if !remaining[id] {
+			return nil, ErrInvalidChecklistOrder
+		}
+		delete(remaining, id)
+	}
+
+	items := []model.ChecklistItem{}
+	if err := clu.clr.ReorderChecklistItems(&items, userId, taskId, itemIds); err != nil {
+		return nil, err
+	}
+	return toChecklistItemResponses(items), nil
+}
+
+// 項目を削除
+func (clu *checklistUsecase) DeleteChecklistItem(userId uint, taskId uint, itemId uint) error {
+	if err := clu.clr.DeleteChecklistItem(userId, taskId, itemId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// 項目をサブタスクに変換（変換した項目はチェックリストから削除される）
+func (clu *checklistUsecase) ConvertChecklistItem(userId uint, taskId uint, itemId uint) (model.TaskResponse, error) {
+	items := []model.ChecklistItem{}
+	if err := clu.clr.GetChecklistItems(&items, userId, taskId); err != nil {
+		return model.TaskResponse{}, err
+	}
+	var item *model.ChecklistItem
+	for i := range items {
+		if items[i].ID == itemId {
+			item = &items[i]
+		}
+	}
+	if item == nil {
+		return model.TaskResponse{}, ErrNotFound
+	}
+
+	// 項目のテキストをタイトルにしたサブタスクとして検証
+	subtask := model.Task{Title: item.Text}
+	setTaskDefaults(&subtask)
+	if err := clu.tv.TaskValidate(subtask); err != nil {
+		return model.TaskResponse{}, err
+	}
+	if err := clu.clr.ConvertChecklistItem(&subtask, userId, taskId, itemId); err != nil {
+		return model.TaskResponse{}, err
+	}
+	return toTaskResponse(subtask), nil
+}
+
+// 項目をレスポンス形式に変換
+func toChecklistItemResponse(item model.ChecklistItem) model.ChecklistItemResponse {
+	return model.ChecklistItemResponse{
+		ID:        item.ID,
+		Text:      item.Text,
+		Checked:   item.Checked,
+		Position:  item.Position,
+		TaskId:    item.TaskId,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func toChecklistItemResponses(items []model.ChecklistItem) []model.ChecklistItemResponse {
+	resItems := []model.ChecklistItemResponse{}
+	for _, v := range items {
+		resItems = append(resItems, toChecklistItemResponse(v))
+	}
+	return resItems
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// タスクへのコメントに関するユースケースを定義
+type ICommentUsecase interface {
+	GetComments(userId uint, taskId uint) ([]model.CommentResponse, error) // タスクのコメント一覧を取得
+	CreateComment(comment model.Comment) (model.CommentResponse, error)    // コメントを作成
+	DeleteComment(userId uint, commentId uint) error                       // 自分のコメントを削除
+}
+
+type commentUsecase struct {
+	cr repository.ICommentRepository // コメントのリポジトリ
+	cv validator.ICommentValidator   // コメントのバリデーション
+	tr repository.ITaskRepository    // タスクの閲覧権限の確認
+	nu INotificationUsecase          // メンションとコメントの通知
+}
+
+// コンストラクタ関数
+func NewCommentUsecase(cr repository.ICommentRepository, cv validator.ICommentValidator, tr repository.ITaskRepository,
+	nu INotificationUsecase) ICommentUsecase {
+	return &commentUsecase{cr, cv, tr, nu}
+}
+
+// タスクのコメント一覧を取得（タスクを閲覧できるユーザーのみ）
+func (cu *commentUsecase) GetComments(userId uint, taskId uint) ([]model.CommentResponse, error) {
+	task := model.Task{}
+	if err := cu.tr.GetTaskById(&task, userId, taskId); err != nil {
+		return nil, err
+	}
+	comments := []model.Comment{}
+	if err := cu.cr.GetComments(&comments, taskId); err != nil {
+		return nil, err
+	}
+	resComments := []model.CommentResponse{}
+	for _, v := range comments {
+		resComments = append(resComments, toCommentResponse(v))
+	}
+	return resComments, nil
+}
+
+// コメントを作成（タスクを閲覧できるユーザーのみ）
+func (cu *commentUsecase) CreateComment(comment model.Comment) (model.CommentResponse, error) {
+	if err := cu.cv.CommentValidate(comment); err != nil {
+		return model.CommentResponse{}, err
+	}
+	task := model.Task{}
+	if err := cu.tr.GetTaskById(&task, comment.UserId, comment.TaskId); err != nil {
+		return model.CommentResponse{}, err
+	}
+	// リクエストで指定できる項目のみを保存する（関連するタスクやユーザーが作成されないようにする）
+	newComment := model.Comment{
+		Body:   comment.Body,
+		TaskId: comment.TaskId,
+		UserId: comment.UserId,
+	}
+	if err := cu.cr.CreateComment(&newComment); err != nil {
+		return model.CommentResponse{}, err
+	}
+	// メンションされたユーザーと、タスクの作成者・担当者に通知
+	cu.nu.NotifyMentions(newComment.UserId, task, newComment.Body, "")
+	cu.nu.NotifyFollowers(newComment.UserId, task, model.NotificationCommented)
+	return toCommentResponse(newComment), nil
+}
+
+// 自分のコメントを削除
+func (cu *commentUsecase) DeleteComment(userId uint, commentId uint) error {
+	if err := cu.cr.DeleteComment(userId, commentId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// コメントをレスポンス形式に変換
+func toCommentResponse(comment model.Comment) model.CommentResponse {
+	return model.CommentResponse{
+		ID:        comment.ID,
+		Body:      comment.Body,
+		TaskId:    comment.TaskId,
+		UserId:    comment.UserId,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/push"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// コメント
+type fakeCommentRepository struct {
+	repository.ICommentRepository
+	comments []model.Comment
+}
+
+func (cr *fakeCommentRepository) CreateComment(comment *model.Comment) error {
+	comment.ID = uint(len(cr.comments) + 1)
+	cr.comments = append(cr.comments, *comment)
+	return nil
+}
+
+func TestCreateCommentIgnoresNestedAssociations(t *testing.T) {
+	owner := model.User{ID: 1, Email: "owner@example.com"}
+	ur := newFakeUserRepository(owner)
+	tr := &fakeTaskRepository{tasks: map[uint]model.Task{1: {ID: 1, UserId: owner.ID, Title: "task"}}}
+	cr := &fakeCommentRepository{}
+	nu := NewNotificationUsecase(&fakeNotificationRepository{}, ur, push.NewHub())
+	cu := NewCommentUsecase(cr, validator.NewCommentValidator(validator.LoadConfig()), tr, nu)
+
+	// コントローラーと同じく、ボディをバインドした後にパスのタスクIDとトークンのユーザーIDを設定する
+	body := `{"id":5,"body":"hello","task_id":9,"user_id":2,
+		"task":{"title":"injected","user_id":2},
+		"user":{"email":"new@example.com","password":"secret"}}`
+	comment := model.Comment{}
+	if err := json.Unmarshal([]byte(body), &comment); err != nil {
+		t.Fatal(err)
+	}
+	comment.TaskId = 1
+	comment.UserId = owner.ID
+
+	if _, err := cu.CreateComment(comment); err != nil {
+		t.Fatal(err)
+	}
+	want := []model.Comment{{ID: 1, Body: "hello", TaskId: 1, UserId: owner.ID}}
+	if !reflect.DeepEqual(cr.comments, want) {
+		t.Errorf("saved comments = %+v, want %+v", cr.comments, want)
+	}
+}
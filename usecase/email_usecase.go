@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/mailer"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// 配信停止リンクに未知のメールの種類が指定された場合のエラー
+var ErrInvalidEmailType = newError(KindInvalid, "invalid_email_type", "invalid email type")
+
+// 期限間近とみなす時間のデフォルト値（DUE_SOON_HOURS で変更可能）
+const defaultDueSoonHours = 24
+
+// メール表示用の日時の書式
+const mailTimeLayout = "2006-01-02 15:04"
+
+// メール通知に関するユースケースを定義
+type IEmailUsecase interface {
+	GetEmailPreference(userId uint) (model.EmailPreferenceResponse, error)                                // メールの配信設定を取得
+	UpdateEmailPreference(pref model.EmailPreference, userId uint) (model.EmailPreferenceResponse, error) // メールの配信設定を更新
+	Unsubscribe(token string, emailType string) error                                                     // 配信停止リンクから指定された種類のメールを停止
+
+	// 以下はほかのユースケースや定期実行から呼び出す送信処理
+	SendSignupConfirmation(user model.User, verifyURL string) // サインアップ確認メールを送信（失敗はログに出力するのみ）
+	SendVerificationEmail(user model.User, verifyURL string)  // メールアドレスの確認リンクを再送（失敗はログに出力するのみ）
+	SendPasswordReset(user model.User, resetURL string)       // パスワードの再設定リンクを送信（失敗はログに出力するのみ）
+	SendDueSoonReminders(now time.Time) error                 // 期限が近づいたタスクのリマインダーを送信
+	SendDailyDigests(now time.Time) error                     // 送信時刻を迎えたユーザーに日次ダイジェストを送信
+}
+
+type emailUsecase struct {
+	er repository.IEmailPreferenceRepository // 配信設定のリポジトリ
+	ev validator.IEmailPreferenceValidator   // 配信設定のバリデーション
+	tr repository.ITaskRepository            // リマインダーやダイジェストに載せるタスクの取得
+	ur repository.IUserRepository            // 送信先ユーザーの取得
+	m  mailer.IMailer                        // メールの送信
+}
+
+// コンストラクタ関数
+func NewEmailUsecase(er repository.IEmailPreferenceRepository, ev validator.IEmailPreferenceValidator,
+	tr repository.ITaskRepository, ur repository.IUserRepository, m mailer.IMailer) IEmailUsecase {
+	return &emailUsecase{er, ev, tr, ur, m}
+}
+
+// メールの配信設定を取得
+func (eu *emailUsecase) GetEmailPreference(userId uint) (model.EmailPreferenceResponse, error) {
+	pref := model.EmailPreference{}
+	if err := eu.er.GetEmailPreference(&pref, userId); err != nil {
+		return model.EmailPreferenceResponse{}, err
+	}
+	return toEmailPreferenceResponse(pref), nil
+}
+
+// メールの配信設定を更新
+func (eu *emailUsecase) UpdateEmailPreference(pref model.EmailPreference, userId uint) (model.EmailPreferenceResponse, error) {
+	if err := eu.ev.EmailPreferenceValidate(pref); err != nil {
+		return model.EmailPreferenceResponse{}, err
+	}
+	// 設定が未作成の場合に備えて先に取得（作成）しておく
+	if err := eu.er.GetEmailPreference(&model.EmailPreference{}, userId); err != nil {
+		return model.EmailPreferenceResponse{}, err
+	}
+	if err := eu.er.UpdateEmailPreference(&pref, userId); err != nil {
+		return model.EmailPreferenceResponse{}, err
+	}
+	return toEmailPreferenceResponse(pref), nil
+}
+
+// 配信停止リンクから指定された種類のメールを停止
+func (eu *emailUsecase) Unsubscribe(token string, emailType string) error {
+	if emailType != model.EmailTypeDueSoon && emailType != model.EmailTypeDailyDigest {
+		return ErrInvalidEmailType
+	}
+	return eu.er.Unsubscribe(&model.EmailPreference{}, token, emailType)
+}
+
+// サインアップ確認メール（メールアドレスの確認リンクを含む）を送信
+// 登録自体は完了しているため、送信に失敗してもログに出力するのみ（確認リンクは再送できる）
+func (eu *emailUsecase) SendSignupConfirmation(user model.User, verifyURL string) {
+	pref := model.EmailPreference{}
+	if err := eu.er.GetEmailPreference(&pref, user.ID); err != nil {
+		log.Printf("email: failed to get preference for user %d: %v", user.ID, err)
+		return
+	}
+	msg, err := mailer.Render(mailer.TemplateSignup, pref.Language, user.Email, mailer.SignupData{
+		Email:     user.Email,
+		AppURL:    os.Getenv("FE_URL"),
+		VerifyURL: verifyURL,
+	})
+	if err != nil {
+		log.Printf("email: failed to render signup mail: %v", err)
+		return
+	}
+	if err := eu.m.Send(msg); err != nil {
+		log.Printf("email: failed to send signup mail to user %d: %v", user.ID, err)
+	}
+}
+
+// メールアドレスの確認リンクを再送
+func (eu *emailUsecase) SendVerificationEmail(user model.User, verifyURL string) {
+	pref := model.EmailPreference{}
+	if err := eu.er.GetEmailPreference(&pref, user.ID); err != nil {
+		log.Printf("email: failed to get preference for user %d: %v", user.ID, err)
+		return
+	}
+	msg, err := mailer.Render(mailer.TemplateVerify, pref.Language, user.Email, mailer.VerifyData{
+		Email:     user.Email,
+		VerifyURL: verifyURL,
+	})
+	if err != nil {
+		log.Printf("email: failed to render verification mail: %v", err)
+		return
+	}
+	if err := eu.m.Send(msg); err != nil {
+		log.Printf("email: failed to send verification mail to user %d: %v", user.ID, err)
+	}
+}
+
+// パスワードの再設定リンクを送信
+func (eu *emailUsecase) SendPasswordReset(user model.User, resetURL string) {
+	pref := model.EmailPreference{}
+	if err := eu.er.GetEmailPreference(&pref, user.ID); err != nil {
+		log.Printf("email: failed to get preference for user %d: %v", user.ID, err)
+		return
+	}
+	msg, err := mailer.Render(mailer.TemplateReset, pref.Language, user.Email, mailer.ResetData{
+		Email:    user.Email,
+		ResetURL: resetURL,
+	})
+	if err != nil {
+		log.Printf("email: failed to render password reset mail: %v", err)
+		return
+	}
+	if err := eu.m.Send(msg); err != nil {
+		log.Printf("email: failed to send password reset mail to user %d: %v", user.ID, err)
+	}
+}
+
+// 期限が近づいたタスクのリマインダーを送信
+// タスクは送信前に通知済みにするため、送信に失敗しても同じタスクで再送はしない
+func (eu *emailUsecase) SendDueSoonReminders(now time.Time) error {
+	tasks := []model.Task{}
+	if err := eu.tr.ClaimDueSoonTasks(&tasks, now, now.Add(dueSoonWindow())); err != nil {
+		return err
+	}
+
+	// 作成者と承諾済みの担当者ごとにまとめて1通にする
+	byUser := map[uint][]model.Task{}
+	for _, t := range tasks {
+		byUser[t.UserId] = append(byUser[t.UserId], t)
+		if t.AssigneeId != nil && *t.AssigneeId != t.UserId && t.AssignmentStatus == model.AssignmentAccepted {
+			byUser[*t.AssigneeId] = append(byUser[*t.AssigneeId], t)
+		}
+	}
+	for userId, userTasks := range byUser {
+		if err := eu.sendDueSoon(userId, userTasks); err != nil {
+			log.Printf("email: failed to send due-soon reminder to user %d: %v", userId, err)
+		}
+	}
+	return nil
+}
+
+// 1人のユーザーに期限間近のリマインダーを送信
+func (eu *emailUsecase) sendDueSoon(userId uint, tasks []model.Task) error {
+	pref := model.EmailPreference{}
+	if err := eu.er.GetEmailPreference(&pref, userId); err != nil {
+		return err
+	}
+	// 配信停止している場合は送信しない
+	if !pref.DueSoon {
+		return nil
+	}
+	user := model.User{}
+	if err := eu.ur.GetUserById(&user, userId); err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return err
+	}
+	msg, err := mailer.Render(mailer.TemplateDueSoon, pref.Language, user.Email, mailer.DueSoonData{
+		Tasks:          toMailTaskItems(tasks, loc),
+		UnsubscribeURL: unsubscribeURL(pref.UnsubscribeToken, model.EmailTypeDueSoon),
+	})
+	if err != nil {
+		return err
+	}
+	setUnsubscribeHeaders(&msg, unsubscribeURL(pref.UnsubscribeToken, model.EmailTypeDueSoon))
+	return eu.m.Send(msg)
+}
+
+// 送信時刻を迎えたユーザーに日次ダイジェストを送信
+// 送信時刻を過ぎていれば、その日のうちはあとからでも1回だけ送信する
+func (eu *emailUsecase) SendDailyDigests(now time.Time) error {
+	prefs := []model.EmailPreference{}
+	if err := eu.er.GetDigestSubscribers(&prefs); err != nil {
+		return err
+	}
+	for _, pref := range prefs {
+		if err := eu.sendDigest(pref, now); err != nil {
+			log.Printf("email: failed to send daily digest to user %d: %v", pref.UserId, err)
+		}
+	}
+	return nil
+}
+
+// 1人のユーザーに日次ダイジェストを送信
+func (eu *emailUsecase) sendDigest(pref model.EmailPreference, now time.Time) error {
+	// 送信時刻や「今日」はユーザーのタイムゾーンで判定
+	loc, err := time.LoadLocation(pref.User.Timezone)
+	if err != nil {
+		return err
+	}
+	local := now.In(loc)
+	if local.Hour() < pref.DigestHour {
+		return nil
+	}
+	// ほかのインスタンスや前回の実行で送信済みの場合は何もしない
+	claimed := false
+	if err := eu.er.ClaimDigest(&claimed, pref.UserId, local); err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	today, err := ownAndAcceptedTasks(eu.tr, pref.UserId, "status:open due:today", local)
+	if err != nil {
+		return err
+	}
+	overdue, err := ownAndAcceptedTasks(eu.tr, pref.UserId, "status:open due:overdue", local)
+	if err != nil {
+		return err
+	}
+	// 載せるタスクがない日は送信しない
+	if len(today) == 0 && len(overdue) == 0 {
+		return nil
+	}
+
+	msg, err := mailer.Render(mailer.TemplateDigest, pref.Language, pref.User.Email, mailer.DigestData{
+		Date:           local.Format("2006-01-02"),
+		Today:          toMailTaskItems(today, loc),
+		Overdue:        toMailTaskItems(overdue, loc),
+		UnsubscribeURL: unsubscribeURL(pref.UnsubscribeToken, model.EmailTypeDailyDigest),
+	})
+	if err != nil {
+		return err
+	}
+	setUnsubscribeHeaders(&msg, unsubscribeURL(pref.UnsubscribeToken, model.EmailTypeDailyDigest))
+	return eu.m.Send(msg)
+}
+
+// 期限間近とみなす時間を取得
+func dueSoonWindow() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("DUE_SOON_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = defaultDueSoonHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// ワンクリックで配信停止できるURLを生成
+func unsubscribeURL(token string, emailType string) string {
+	v := url.Values{}
+	v.Set("token", token)
+	v.Set("type", emailType)
+	return os.Getenv("API_URL") + "/unsubscribe?" + v.Encode()
+}
+
+// メールクライアントの配信停止ボタンに対応するヘッダーを設定（RFC 8058）
+func setUnsubscribeHeaders(msg *mailer.Message, link string) {
+	msg.Headers = map[string]string{
+		"List-Unsubscribe":      "<" + link + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
+// タスクをメールテンプレート用の形式に変換
+func toMailTaskItems(tasks []model.Task, loc *time.Location) []mailer.TaskItem {
+	items := []mailer.TaskItem{}
+	for _, t := range tasks {
+		item := mailer.TaskItem{Title: t.Title}
+		if t.DueAt != nil {
+			item.Due = t.DueAt.In(loc).Format(mailTimeLayout)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// 配信設定をレスポンス形式に変換
+func toEmailPreferenceResponse(pref model.EmailPreference) model.EmailPreferenceResponse {
+	return model.EmailPreferenceResponse{
+		Language:    pref.Language,
+		DueSoon:     pref.DueSoon,
+		DailyDigest: pref.DailyDigest,
+		DigestHour:  pref.DigestHour,
+		UpdatedAt:   pref.UpdatedAt,
+	}
+}
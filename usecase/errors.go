@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+)
+
+// ドメインエラーの種類
+// コントローラー層でHTTPのステータスコードに対応付ける
+type ErrorKind int
+
+const (
+	KindInvalid       ErrorKind = iota + 1 // リクエストの内容が正しくない（400）
+	KindUnauthorized                       // 認証に失敗した（401）
+	KindForbidden                          // 操作が許可されていない（403）
+	KindNotFound                           // 対象が存在しない、または閲覧できない（404）
+	KindConflict                           // 現在の状態と競合する（409）
+	KindUnprocessable                      // 内容は読み取れるが処理できない（422）
+)
+
+// ユースケース層が返すドメインエラー
+// Code はエラーを識別する文字列で、クライアントはメッセージではなくこちらで判定する
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ドメインエラーを作成
+func newError(kind ErrorKind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// 各ユースケースで共通のドメインエラー
+var (
+	ErrNotFound           = newError(KindNotFound, "not_found", "resource does not exist")                          // 対象が存在しない
+	ErrForbidden          = newError(KindForbidden, "forbidden", "operation is not allowed")                        // 操作が許可されていない
+	ErrConflict           = newError(KindConflict, "conflict", "resource already exists")                           // 一意であるべき値が重複している
+	ErrEmailTaken         = newError(KindConflict, "email_taken", "email is already registered")                    // メールアドレスが登録済み
+	ErrInvalidCredentials = newError(KindUnauthorized, "invalid_credentials", "email or password is incorrect")     // メールアドレスかパスワードが違う
+	ErrUnknownAssignee    = newError(KindUnprocessable, "unknown_assignee", "no user is registered with the email") // 割り当て先のユーザーが存在しない
+)
+
+// err をドメインエラーとして取り出す
+// リポジトリ層のエラー（存在しない、一意制約の違反）は対応するドメインエラーに変換する
+func AsError(err error) (*Error, bool) {
+	var derr *Error
+	switch {
+	case errors.As(err, &derr):
+		return derr, true
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrNotFound, true
+	case errors.Is(err, repository.ErrDuplicate):
+		return ErrConflict, true
+	}
+	return nil, false
+}
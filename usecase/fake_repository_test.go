@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+)
+
+// テスト用のメモリ上のリポジトリ
+// インターフェースを埋め込み、テストで使うメソッドのみを実装する（それ以外を呼び出すと panic する）
+
+// ユーザー
+type fakeUserRepository struct {
+	repository.IUserRepository
+	users map[uint]model.User
+}
+
+func newFakeUserRepository(users ...model.User) *fakeUserRepository {
+	ur := &fakeUserRepository{users: map[uint]model.User{}}
+	for _, u := range users {
+		ur.users[u.ID] = u
+	}
+	return ur
+}
+
+func (ur *fakeUserRepository) GetUserById(user *model.User, userId uint) error {
+	u, ok := ur.users[userId]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	*user = u
+	return nil
+}
+
+func (ur *fakeUserRepository) GetUserByEmail(user *model.User, email string) error {
+	for _, u := range ur.users {
+		if u.Email == email {
+			*user = u
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// ログインした端末のセッション
+type fakeSessionRepository struct {
+	repository.ISessionRepository
+	sessions []model.Session
+}
+
+func (sr *fakeSessionRepository) CreateSession(session *model.Session) error {
+	session.ID = uint(len(sr.sessions) + 1)
+	sr.sessions = append(sr.sessions, *session)
+	return nil
+}
+
+// リフレッシュトークン
+type fakeRefreshTokenRepository struct {
+	repository.IRefreshTokenRepository
+	tokens []model.RefreshToken
+}
+
+func (rr *fakeRefreshTokenRepository) CreateRefreshToken(token *model.RefreshToken) error {
+	token.ID = uint(len(rr.tokens) + 1)
+	rr.tokens = append(rr.tokens, *token)
+	return nil
+}
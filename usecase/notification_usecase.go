@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/push"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+)
+
+// タスクのタイトルやコメント内のメンション（@user@example.com）
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9._%+-])@([A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+// 通知に関するユースケースを定義
+type INotificationUsecase interface {
+	GetNotifications(userId uint, unreadOnly bool) ([]model.NotificationResponse, error) // 受信箱の通知を取得
+	GetUnreadCount(userId uint) (int64, error)                                           // 未読件数を取得
+	MarkAsRead(userId uint, notificationId uint) error                                   // 通知を既読にする
+	MarkAllAsRead(userId uint) error                                                     // すべての通知を既読にする
+	Subscribe(userId uint) (<-chan model.NotificationResponse, func())                   // リアルタイム配信を購読
+
+	// 以下はほかのユースケースから呼び出す通知の発行処理
+	// 通知の失敗で元の操作が失敗しないよう、エラーはログに出力するのみ
+	NotifyAssigned(actorId uint, task model.Task)                           // 担当者にタスクの割り当てを通知
+	NotifyMentions(actorId uint, task model.Task, text, before string)      // text 内のメンションを通知（before に含まれていたものは除く）
+	NotifyFollowers(actorId uint, task model.Task, notificationType string) // タスクの作成者と担当者に変更を通知
+	NotifyReminder(userId uint, task model.Task)                            // リマインダーの時刻になったことを通知
+}
+
+type notificationUsecase struct {
+	nr  repository.INotificationRepository // 通知のリポジトリ
+	ur  repository.IUserRepository         // 通知先・通知元ユーザーの取得
+	hub push.IHub                          // 接続中のユーザーへのリアルタイム配信
+}
+
+// コンストラクタ関数
+func NewNotificationUsecase(nr repository.INotificationRepository, ur repository.IUserRepository, hub push.IHub) INotificationUsecase {
+	return &notificationUsecase{nr, ur, hub}
+}
+
+// 受信箱の通知を取得
+func (nu *notificationUsecase) GetNotifications(userId uint, unreadOnly bool) ([]model.NotificationResponse, error) {
+	notifications := []model.Notification{}
+	if err := nu.nr.GetNotifications(&notifications, userId, unreadOnly); err != nil {
+		return nil, err
+	}
+	resNotifications := []model.NotificationResponse{}
+	for _, v := range notifications {
+		resNotifications = append(resNotifications, toNotificationResponse(v))
+	}
+	return resNotifications, nil
+}
+
+// 未読件数を取得
+func (nu *notificationUsecase) GetUnreadCount(userId uint) (int64, error) {
+	var count int64
+	if err := nu.nr.CountUnread(&count, userId); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// 通知を既読にする
+func (nu *notificationUsecase) MarkAsRead(userId uint, notificationId uint) error {
+	return nu.nr.MarkAsRead(userId, notificationId)
+}
+
+// すべての通知を既読にする
+func (nu *notificationUsecase) MarkAllAsRead(userId uint) error {
+	return nu.nr.MarkAllAsRead(userId)
+}
+
+// リアルタイム配信を購読
+func (nu *notificationUsecase) Subscribe(userId uint) (<-chan model.NotificationResponse, func()) {
+	return nu.hub.Subscribe(userId)
+}
+
+// 担当者にタスクの割り当てを通知
+func (nu *notificationUsecase) NotifyAssigned(actorId uint, task model.Task) {
+	if task.AssigneeId == nil || *task.AssigneeId == actorId {
+		return
+	}
+	message := fmt.Sprintf("%s assigned you a task: %s", nu.actorName(actorId), task.Title)
+	nu.fanOut(actorId, task, model.NotificationAssigned, message, []uint{*task.AssigneeId})
+}
+
+// text 内の @メールアドレス で指定されたユーザーに通知
+// before（変更前の文字列）ですでにメンションされていたユーザーには再通知しない
+// タスクの内容が漏れないよう、タスクを閲覧できないユーザーへのメンションは無視する
+func (nu *notificationUsecase) NotifyMentions(actorId uint, task model.Task, text, before string) {
+	already := map[string]bool{}
+	for _, email := range mentionedEmails(before) {
+		already[strings.ToLower(email)] = true
+	}
+	recipients := []uint{}
+	for _, email := range mentionedEmails(text) {
+		if already[strings.ToLower(email)] {
+			continue
+		}
+		user := model.User{}
+		// 存在しないメールアドレスへのメンションは無視する
+		if err := nu.ur.GetUserByEmail(&user, email); err != nil {
+			continue
+		}
+		if user.ID != actorId && canReadTask(task, user.ID) {
+			recipients = append(recipients, user.ID)
+		}
+	}
+	message := fmt.Sprintf("%s mentioned you in: %s", nu.actorName(actorId), task.Title)
+	nu.fanOut(actorId, task, model.NotificationMentioned, message, recipients)
+}
+
+// タスクの作成者と担当者（承諾待ち・承諾済み）に変更を通知（操作したユーザー自身は除く）
+func (nu *notificationUsecase) NotifyFollowers(actorId uint, task model.Task, notificationType string) {
+	recipients := []uint{}
+	if task.UserId != actorId {
+		recipients = append(recipients, task.UserId)
+	}
+	if task.AssigneeId != nil && *task.AssigneeId != actorId && canReadTask(task, *task.AssigneeId) {
+		recipients = append(recipients, *task.AssigneeId)
+	}
+	var message string
+	switch notificationType {
+	case model.NotificationCommented:
+		message = fmt.Sprintf("%s commented on: %s", nu.actorName(actorId), task.Title)
+	default:
+		message = fmt.Sprintf("%s updated: %s", nu.actorName(actorId), task.Title)
+	}
+	nu.fanOut(actorId, task, notificationType, message, recipients)
+}
+
+// タスクを閲覧できるユーザーか（作成者、または承諾待ち・承諾済みの担当者）
+func canReadTask(task model.Task, userId uint) bool {
+	if task.UserId == userId {
+		return true
+	}
+	return task.AssigneeId != nil && *task.AssigneeId == userId &&
+		(task.AssignmentStatus == model.AssignmentPending || task.AssignmentStatus == model.AssignmentAccepted)
+}
+
+// リマインダーの時刻になったことを通知（操作したユーザーはいない）
+func (nu *notificationUsecase) NotifyReminder(userId uint, task model.Task) {
+	message := fmt.Sprintf("Reminder: %s is due soon", task.Title)
+	nu.fanOut(0, task, model.NotificationReminder, message, []uint{userId})
+}
+
+// 受信者ごとに通知を保存し、接続中であればリアルタイムに配信する
+// actorId が0の場合は操作したユーザーなしとして保存する
+func (nu *notificationUsecase) fanOut(actorId uint, task model.Task, notificationType, message string, recipients []uint) {
+	notifications := []model.Notification{}
+	seen := map[uint]bool{}
+	for _, userId := range recipients {
+		if seen[userId] {
+			continue
+		}
+		seen[userId] = true
+		taskId := task.ID
+		var actor *uint
+		if actorId != 0 {
+			id := actorId
+			actor = &id
+		}
+		notifications = append(notifications, model.Notification{
+			Type:    notificationType,
+			Message: message,
+			TaskId:  &taskId,
+			ActorId: actor,
+			UserId:  userId,
+		})
+	}
+	if err := nu.nr.CreateNotifications(&notifications); err != nil {
+		log.Printf("failed to create notifications: %v", err)
+		return
+	}
+	for _, n := range notifications {
+		nu.hub.Publish(n.UserId, toNotificationResponse(n))
+	}
+}
+
+// 通知メッセージに表示する操作ユーザー名（メールアドレス）
+func (nu *notificationUsecase) actorName(actorId uint) string {
+	user := model.User{}
+	if err := nu.ur.GetUserById(&user, actorId); err != nil {
+		return "someone"
+	}
+	return user.Email
+}
+
+// 文字列からメンションされたメールアドレスを重複なく取り出す（大文字小文字は区別しない）
+func mentionedEmails(text string) []string {
+	emails := []string{}
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		email := strings.TrimRight(m[1], ".")
+		if !seen[strings.ToLower(email)] {
+			seen[strings.ToLower(email)] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// 通知をレスポンス形式に変換
+func toNotificationResponse(notification model.Notification) model.NotificationResponse {
+	return model.NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Message:   notification.Message,
+		TaskId:    notification.TaskId,
+		ActorId:   notification.ActorId,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
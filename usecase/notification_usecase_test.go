@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/push"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+)
+
+// 通知
+type fakeNotificationRepository struct {
+	repository.INotificationRepository
+	notifications []model.Notification
+}
+
+func (nr *fakeNotificationRepository) CreateNotifications(notifications *[]model.Notification) error {
+	nr.notifications = append(nr.notifications, *notifications...)
+	return nil
+}
+
+func TestNotifyMentionsOnlyReachesUsersWhoCanReadTheTask(t *testing.T) {
+	owner := model.User{ID: 1, Email: "owner@example.com"}
+	assignee := model.User{ID: 2, Email: "assignee@example.com"}
+	outsider := model.User{ID: 3, Email: "outsider@example.com"}
+	ur := newFakeUserRepository(owner, assignee, outsider)
+	text := "@owner@example.com @assignee@example.com @outsider@example.com"
+
+	tests := []struct {
+		name       string
+		actorId    uint
+		status     string
+		recipients []uint
+	}{
+		{"承諾待ちの担当者", owner.ID, model.AssignmentPending, []uint{assignee.ID}},
+		{"承諾済みの担当者", owner.ID, model.AssignmentAccepted, []uint{assignee.ID}},
+		{"辞退した担当者", owner.ID, model.AssignmentDeclined, []uint{}},
+		{"担当者からのメンション", assignee.ID, model.AssignmentAccepted, []uint{owner.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nr := &fakeNotificationRepository{}
+			nu := NewNotificationUsecase(nr, ur, push.NewHub())
+			task := model.Task{ID: 10, Title: "task", UserId: owner.ID, AssigneeId: &assignee.ID, AssignmentStatus: tt.status}
+
+			nu.NotifyMentions(tt.actorId, task, text, "")
+
+			got := []uint{}
+			for _, n := range nr.notifications {
+				got = append(got, n.UserId)
+			}
+			if len(got) != len(tt.recipients) {
+				t.Fatalf("recipients = %v, want %v", got, tt.recipients)
+			}
+			for i := range got {
+				if got[i] != tt.recipients[i] {
+					t.Fatalf("recipients = %v, want %v", got, tt.recipients)
+				}
+			}
+		})
+	}
+}
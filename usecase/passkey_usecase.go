@@ -0,0 +1,419 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	webAuthnTimeout       = 5 * time.Minute // 登録・認証を開始してから完了するまでの有効期間
+	defaultWebAuthnRPName = "Echo-REST-API" // 認証器に表示するサービス名（WEBAUTHN_RP_NAME で変更できる）
+
+	purposePasskeyRegistration = "registration" // パスキーの登録
+	purposePasskeyLogin        = "login"        // パスワードなしのログイン
+	purposePasskeyMfa          = "mfa"          // 2段階認証の確認コードの代わり
+)
+
+var (
+	// パスキーによる認証に失敗した場合のエラー（未登録のパスキー、署名が正しくない、チャレンジが期限切れ・使用済みなど）
+	ErrInvalidPasskey = newError(KindUnauthorized, "invalid_passkey", "passkey could not be verified")
+	// パスキーの登録の応答を検証できなかった場合のエラー
+	ErrInvalidPasskeyRegistration = newError(KindInvalid, "invalid_passkey_registration", "passkey registration could not be verified")
+	// 2段階認証にパスキーを使おうとしたが、登録されていない場合のエラー
+	ErrNoPasskeys = newError(KindConflict, "no_passkeys", "no passkeys are registered")
+)
+
+// 環境変数から WebAuthn の Relying Party の設定を読み込む
+// WEBAUTHN_RP_ID（既定は FE_URL のホスト名）、WEBAUTHN_RP_ORIGINS（カンマ区切り、既定は FE_URL）、WEBAUTHN_RP_NAME
+func LoadWebAuthnConfig() *webauthn.Config {
+	feURL := os.Getenv("FE_URL")
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		if u, err := url.Parse(feURL); err == nil {
+			rpID = u.Hostname()
+		}
+	}
+	origins := []string{}
+	for _, origin := range strings.Split(os.Getenv("WEBAUTHN_RP_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 && feURL != "" {
+		origins = append(origins, feURL)
+	}
+	name := os.Getenv("WEBAUTHN_RP_NAME")
+	if name == "" {
+		name = defaultWebAuthnRPName
+	}
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: webAuthnTimeout, TimeoutUVD: webAuthnTimeout}
+	return &webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: name,
+		RPOrigins:     origins,
+		Timeouts:      webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	}
+}
+
+// パスキー（WebAuthn）に関するユースケースのインターフェース定義
+// 登録・認証はそれぞれ、オプションを返す開始と、ブラウザの応答（PublicKeyCredential の JSON）を検証する完了の2回のリクエストで行う
+type IPasskeyUsecase interface {
+	GetPasskeys(userId uint) ([]model.PasskeyResponse, error)                                                  // 登録済みのパスキーの一覧を取得
+	BeginRegistration(userId uint, req model.PasskeyRegistrationRequest) (*protocol.CredentialCreation, error) // パスキーの登録を開始する
+	FinishRegistration(userId uint, body []byte) (model.PasskeyResponse, error)                                // 認証器の応答を検証してパスキーを登録する
+	DeletePasskey(userId uint, passkeyId uint) error                                                           // パスキーを削除する
+	BeginLogin() (*protocol.CredentialAssertion, error)                                                        // パスワードなしのログインを開始する
+	FinishLogin(body []byte, meta model.SessionMeta) (model.AuthTokens, error)                                 // 認証器の応答を検証してログインする
+	BeginMfaLogin(req model.MfaPasskeyRequest) (*protocol.CredentialAssertion, error)                          // ログインの2段階目をパスキーで行う
+	FinishMfaLogin(body []byte, meta model.SessionMeta) (model.AuthTokens, error)                              // 認証器の応答を検証してログインの2段階目を完了する
+}
+
+type passkeyUsecase struct {
+	ur repository.IUserRepository
+	kv validator.IPasskeyValidator
+	kr repository.IPasskeyRepository
+	sr repository.ISessionRepository
+	rr repository.IRefreshTokenRepository
+	vp EmailVerificationPolicy
+	wa *webauthn.WebAuthn // Relying Party の設定（テストではソフトウェアの認証器に合わせた設定を渡す）
+}
+
+// コンストラクタ関数
+func NewPasskeyUsecase(ur repository.IUserRepository, kv validator.IPasskeyValidator, kr repository.IPasskeyRepository,
+	sr repository.ISessionRepository, rr repository.IRefreshTokenRepository, vp EmailVerificationPolicy, wa *webauthn.WebAuthn) IPasskeyUsecase {
+	return &passkeyUsecase{ur, kv, kr, sr, rr, vp, wa}
+}
+
+// 登録済みのパスキーの一覧を、登録順に取得
+func (ku *passkeyUsecase) GetPasskeys(userId uint) ([]model.PasskeyResponse, error) {
+	passkeys := []model.Passkey{}
+	if err := ku.kr.GetPasskeys(&passkeys, userId); err != nil {
+		return nil, err
+	}
+	resPasskeys := []model.PasskeyResponse{}
+	for _, v := range passkeys {
+		resPasskeys = append(resPasskeys, toPasskeyResponse(v))
+	}
+	return resPasskeys, nil
+}
+
+// パスキーの登録を開始し、navigator.credentials.create() に渡すオプションを返す
+// 登録済みのパスキーは除外し、パスワードなしのログインに使えるよう認証器にユーザーの情報を保存させる
+func (ku *passkeyUsecase) BeginRegistration(userId uint, req model.PasskeyRegistrationRequest) (*protocol.CredentialCreation, error) {
+	if err := ku.kv.PasskeyRegistrationValidate(req); err != nil {
+		return nil, err
+	}
+	user, err := ku.loadUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	exclusions := webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()
+	creation, session, err := ku.wa.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := ku.saveSession(session, purposePasskeyRegistration, userId, req.Name); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+// 認証器の応答を検証して、パスキーを登録する
+func (ku *passkeyUsecase) FinishRegistration(userId uint, body []byte) (model.PasskeyResponse, error) {
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(body)
+	if err != nil {
+		return model.PasskeyResponse{}, passkeyError(ErrInvalidPasskeyRegistration, err)
+	}
+	stored, session, err := ku.consumeSession(parsed.Response.CollectedClientData.Challenge, purposePasskeyRegistration)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.PasskeyResponse{}, ErrInvalidPasskeyRegistration
+		}
+		return model.PasskeyResponse{}, err
+	}
+	// ほかのユーザーが開始した登録は完了できない
+	if stored.UserId != userId {
+		return model.PasskeyResponse{}, ErrInvalidPasskeyRegistration
+	}
+	user, err := ku.loadUser(userId)
+	if err != nil {
+		return model.PasskeyResponse{}, err
+	}
+	credential, err := ku.wa.CreateCredential(user, session, parsed)
+	if err != nil {
+		return model.PasskeyResponse{}, passkeyError(ErrInvalidPasskeyRegistration, err)
+	}
+	passkey := model.Passkey{
+		Name:            stored.Name,
+		CredentialId:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      model.Labels{},
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		UserId:          userId,
+	}
+	for _, transport := range credential.Transport {
+		passkey.Transports = append(passkey.Transports, string(transport))
+	}
+	// 同じパスキーが登録済みの場合は ErrDuplicate（409）
+	if err := ku.kr.CreatePasskey(&passkey); err != nil {
+		return model.PasskeyResponse{}, err
+	}
+	return toPasskeyResponse(passkey), nil
+}
+
+// パスキーを削除する（ほかのユーザーのパスキーは ErrNotFound）
+func (ku *passkeyUsecase) DeletePasskey(userId uint, passkeyId uint) error {
+	return ku.kr.DeletePasskey(userId, passkeyId)
+}
+
+// パスワードなしのログインを開始し、navigator.credentials.get() に渡すオプションを返す
+// ユーザーはパスキーから特定するため、メールアドレスは不要（認証器での本人確認を必須にする）
+func (ku *passkeyUsecase) BeginLogin() (*protocol.CredentialAssertion, error) {
+	assertion, session, err := ku.wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, err
+	}
+	if err := ku.saveSession(session, purposePasskeyLogin, 0, ""); err != nil {
+		return nil, err
+	}
+	return assertion, nil
+}
+
+// 認証器の応答を検証して、パスワードなしでログインする
+// パスキーは所持と本人確認（生体認証やPIN）を兼ねるため、2段階認証が有効なユーザーも確認コードは不要
+func (ku *passkeyUsecase) FinishLogin(body []byte, meta model.SessionMeta) (model.AuthTokens, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(body)
+	if err != nil {
+		return model.AuthTokens{}, passkeyError(ErrInvalidPasskey, err)
+	}
+	_, session, err := ku.consumeSession(parsed.Response.CollectedClientData.Challenge, purposePasskeyLogin)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.AuthTokens{}, ErrInvalidPasskey
+		}
+		return model.AuthTokens{}, err
+	}
+	// 認証器が返したユーザーハンドル（ユーザーID）からユーザーとパスキーを取得する
+	var user webAuthnUser
+	handler := func(rawID []byte, userHandle []byte) (webauthn.User, error) {
+		userId, err := strconv.ParseUint(string(userHandle), 10, 64)
+		if err != nil {
+			return nil, ErrInvalidPasskey
+		}
+		if user, err = ku.loadUser(uint(userId)); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	_, credential, err := ku.wa.ValidatePasskeyLogin(handler, session, parsed)
+	if err != nil {
+		return model.AuthTokens{}, passkeyError(ErrInvalidPasskey, err)
+	}
+	if ku.vp == VerificationForLogin && user.user.EmailVerifiedAt == nil {
+		return model.AuthTokens{}, ErrEmailNotVerified
+	}
+	now := time.Now()
+	if err := ku.recordUsage(user, credential, now); err != nil {
+		return model.AuthTokens{}, err
+	}
+	return startSession(ku.sr, ku.rr, user.user.ID, meta, now)
+}
+
+// ログインの2段階目を、確認コードの代わりにパスキーで行う
+// POST /login（または /auth/token）が返した mfa_token で、そのユーザーのパスキーのみを受け付けるオプションを返す
+func (ku *passkeyUsecase) BeginMfaLogin(req model.MfaPasskeyRequest) (*protocol.CredentialAssertion, error) {
+	mfaUser, err := mfaTokenUser(ku.ur, ku.kr, req.MfaToken, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	user, err := ku.loadUser(mfaUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.passkeys) == 0 {
+		return nil, ErrNoPasskeys
+	}
+	assertion, session, err := ku.wa.BeginLogin(user, webauthn.WithUserVerification(protocol.VerificationPreferred))
+	if err != nil {
+		return nil, err
+	}
+	if err := ku.saveSession(session, purposePasskeyMfa, user.user.ID, ""); err != nil {
+		return nil, err
+	}
+	return assertion, nil
+}
+
+// 認証器の応答を検証して、ログインの2段階目を完了する
+func (ku *passkeyUsecase) FinishMfaLogin(body []byte, meta model.SessionMeta) (model.AuthTokens, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(body)
+	if err != nil {
+		return model.AuthTokens{}, passkeyError(ErrInvalidPasskey, err)
+	}
+	stored, session, err := ku.consumeSession(parsed.Response.CollectedClientData.Challenge, purposePasskeyMfa)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.AuthTokens{}, ErrInvalidPasskey
+		}
+		return model.AuthTokens{}, err
+	}
+	user, err := ku.loadUser(stored.UserId)
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	credential, err := ku.wa.ValidateLogin(user, session, parsed)
+	if err != nil {
+		return model.AuthTokens{}, passkeyError(ErrInvalidPasskey, err)
+	}
+	now := time.Now()
+	if err := ku.recordUsage(user, credential, now); err != nil {
+		return model.AuthTokens{}, err
+	}
+	return startSession(ku.sr, ku.rr, user.user.ID, meta, now)
+}
+
+// ユーザーと登録済みのパスキーを取得
+func (ku *passkeyUsecase) loadUser(userId uint) (webAuthnUser, error) {
+	user := webAuthnUser{}
+	if err := ku.ur.GetUserById(&user.user, userId); err != nil {
+		return webAuthnUser{}, err
+	}
+	if err := ku.kr.GetPasskeys(&user.passkeys, userId); err != nil {
+		return webAuthnUser{}, err
+	}
+	return user, nil
+}
+
+// 登録・認証の開始時の状態を、チャレンジごとに保存
+func (ku *passkeyUsecase) saveSession(session *webauthn.SessionData, purpose string, userId uint, name string) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	stored := model.WebAuthnSession{
+		Challenge: session.Challenge,
+		Purpose:   purpose,
+		UserId:    userId,
+		Name:      name,
+		Data:      string(data),
+		ExpiresAt: time.Now().Add(webAuthnTimeout),
+	}
+	return ku.kr.CreateWebAuthnSession(&stored)
+}
+
+// 応答に含まれるチャレンジから、開始時の状態を取り出す（一度しか使えない）
+func (ku *passkeyUsecase) consumeSession(challenge string, purpose string) (model.WebAuthnSession, webauthn.SessionData, error) {
+	stored := model.WebAuthnSession{}
+	if err := ku.kr.ConsumeWebAuthnSession(&stored, challenge, purpose, time.Now()); err != nil {
+		return model.WebAuthnSession{}, webauthn.SessionData{}, err
+	}
+	session := webauthn.SessionData{}
+	if err := json.Unmarshal([]byte(stored.Data), &session); err != nil {
+		return model.WebAuthnSession{}, webauthn.SessionData{}, err
+	}
+	return stored, session, nil
+}
+
+// 認証に使ったパスキーの署名カウンターと同期の状態、最終使用日時を記録する
+// 署名カウンターが戻っている（複製された認証器の可能性がある）場合は認証を拒否する
+func (ku *passkeyUsecase) recordUsage(user webAuthnUser, credential *webauthn.Credential, now time.Time) error {
+	if credential.Authenticator.CloneWarning {
+		return fmt.Errorf("%w: signature counter did not increase", ErrInvalidPasskey)
+	}
+	for _, passkey := range user.passkeys {
+		if bytes.Equal(passkey.CredentialId, credential.ID) {
+			passkey.SignCount = credential.Authenticator.SignCount
+			passkey.BackupState = credential.Flags.BackupState
+			return ku.kr.UpdatePasskeyUsage(&passkey, now)
+		}
+	}
+	return ErrInvalidPasskey
+}
+
+// WebAuthn のライブラリのエラーを、詳細を付けたドメインエラーに変換する
+func passkeyError(derr *Error, err error) error {
+	var perr *protocol.Error
+	if errors.As(err, &perr) && perr.Details != "" {
+		return fmt.Errorf("%w: %s", derr, perr.Details)
+	}
+	// 検証中にユースケースやリポジトリで発生したエラー（ユーザーが存在しないなど）はそのまま返す
+	if _, ok := AsError(err); ok {
+		return err
+	}
+	return derr
+}
+
+// レスポンス用にパスキーの情報のうち必要なものだけを取り出す
+func toPasskeyResponse(passkey model.Passkey) model.PasskeyResponse {
+	return model.PasskeyResponse{
+		ID:         passkey.ID,
+		Name:       passkey.Name,
+		Synced:     passkey.BackupState,
+		LastUsedAt: passkey.LastUsedAt,
+		CreatedAt:  passkey.CreatedAt,
+	}
+}
+
+// WebAuthn のライブラリが扱うユーザー（ユーザーと登録済みのパスキー）
+type webAuthnUser struct {
+	user     model.User
+	passkeys []model.Passkey
+}
+
+// ユーザーハンドル（認証器に保存される、ユーザーを識別する値）
+func (u webAuthnUser) WebAuthnID() []byte {
+	return []byte(strconv.FormatUint(uint64(u.user.ID), 10))
+}
+
+func (u webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Email
+}
+
+// 登録済みのパスキーをライブラリのクレデンシャルに変換
+func (u webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.passkeys))
+	for i, passkey := range u.passkeys {
+		transports := make([]protocol.AuthenticatorTransport, len(passkey.Transports))
+		for j, transport := range passkey.Transports {
+			transports[j] = protocol.AuthenticatorTransport(transport)
+		}
+		credentials[i] = webauthn.Credential{
+			ID:              passkey.CredentialId,
+			PublicKey:       passkey.PublicKey,
+			AttestationType: passkey.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: passkey.BackupEligible,
+				BackupState:    passkey.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    passkey.AAGUID,
+				SignCount: passkey.SignCount,
+			},
+		}
+	}
+	return credentials
+}
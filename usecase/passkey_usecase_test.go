@@ -0,0 +1,522 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+// 認証器のデータのフラグ（WebAuthn Level 2 6.1）
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// パスキーとWebAuthnの状態をメモリ上に保存するリポジトリ
+type fakePasskeyRepository struct {
+	passkeys []model.Passkey
+	sessions []model.WebAuthnSession
+}
+
+func (kr *fakePasskeyRepository) GetPasskeys(passkeys *[]model.Passkey, userId uint) error {
+	*passkeys = []model.Passkey{}
+	for _, p := range kr.passkeys {
+		if p.UserId == userId {
+			*passkeys = append(*passkeys, p)
+		}
+	}
+	return nil
+}
+
+func (kr *fakePasskeyRepository) CountPasskeys(count *int64, userId uint) error {
+	*count = 0
+	for _, p := range kr.passkeys {
+		if p.UserId == userId {
+			*count++
+		}
+	}
+	return nil
+}
+
+func (kr *fakePasskeyRepository) CreatePasskey(passkey *model.Passkey) error {
+	for _, p := range kr.passkeys {
+		if bytes.Equal(p.CredentialId, passkey.CredentialId) {
+			return repository.ErrDuplicate
+		}
+	}
+	passkey.ID = uint(len(kr.passkeys) + 1)
+	passkey.CreatedAt = time.Now()
+	kr.passkeys = append(kr.passkeys, *passkey)
+	return nil
+}
+
+func (kr *fakePasskeyRepository) UpdatePasskeyUsage(passkey *model.Passkey, now time.Time) error {
+	for i := range kr.passkeys {
+		if kr.passkeys[i].ID == passkey.ID {
+			kr.passkeys[i].SignCount = passkey.SignCount
+			kr.passkeys[i].BackupState = passkey.BackupState
+			kr.passkeys[i].LastUsedAt = &now
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (kr *fakePasskeyRepository) DeletePasskey(userId uint, passkeyId uint) error {
+	for i, p := range kr.passkeys {
+		if p.ID == passkeyId && p.UserId == userId {
+			kr.passkeys = append(kr.passkeys[:i], kr.passkeys[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (kr *fakePasskeyRepository) CreateWebAuthnSession(session *model.WebAuthnSession) error {
+	kr.sessions = append(kr.sessions, *session)
+	return nil
+}
+
+func (kr *fakePasskeyRepository) ConsumeWebAuthnSession(session *model.WebAuthnSession, challenge string, purpose string, now time.Time) error {
+	for i, s := range kr.sessions {
+		if s.Challenge == challenge && s.Purpose == purpose && s.ExpiresAt.After(now) {
+			*session = s
+			kr.sessions = append(kr.sessions[:i], kr.sessions[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (kr *fakePasskeyRepository) DeleteExpiredWebAuthnSessions(now time.Time) error {
+	return nil
+}
+
+// ソフトウェアの認証器（ES256 の鍵を持ち、attestation は none）
+// ブラウザが navigator.credentials.create() / get() の結果として送信する JSON を作る
+type softwareAuthenticator struct {
+	origin       string // clientDataJSON に含めるオリジン
+	key          *ecdsa.PrivateKey
+	credentialId []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftwareAuthenticator(t *testing.T) *softwareAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialId := make([]byte, 16)
+	if _, err := rand.Read(credentialId); err != nil {
+		t.Fatal(err)
+	}
+	return &softwareAuthenticator{origin: testOrigin, key: key, credentialId: credentialId}
+}
+
+var b64url = base64.RawURLEncoding
+
+// 登録の応答（PublicKeyCredential の JSON）
+func (a *softwareAuthenticator) create(t *testing.T, creation *protocol.CredentialCreation, challenge string) []byte {
+	t.Helper()
+	a.userHandle = creation.Response.User.ID.(protocol.URLEncodedBase64)
+	publicKey, err := webauthncbor.Marshal(map[int]interface{}{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authData := a.authData(flagUserPresent | flagUserVerified | flagAttested)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialId)))
+	authData = append(authData, a.credentialId...)
+	authData = append(authData, publicKey...)
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.credential(t, map[string]interface{}{
+		"clientDataJSON":    b64url.EncodeToString(a.clientData(t, "webauthn.create", challenge)),
+		"attestationObject": b64url.EncodeToString(attestation),
+		"transports":        []string{"internal"},
+	})
+}
+
+// 認証の応答（PublicKeyCredential の JSON）
+func (a *softwareAuthenticator) get(t *testing.T, challenge string) []byte {
+	t.Helper()
+	a.signCount++
+	authData := a.authData(flagUserPresent | flagUserVerified)
+	clientData := a.clientData(t, "webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.credential(t, map[string]interface{}{
+		"clientDataJSON":    b64url.EncodeToString(clientData),
+		"authenticatorData": b64url.EncodeToString(authData),
+		"signature":         b64url.EncodeToString(signature),
+		"userHandle":        b64url.EncodeToString(a.userHandle),
+	})
+}
+
+func (a *softwareAuthenticator) authData(flags byte) []byte {
+	rpIdHash := sha256.Sum256([]byte(testRPID))
+	authData := append(rpIdHash[:], flags)
+	return binary.BigEndian.AppendUint32(authData, a.signCount)
+}
+
+func (a *softwareAuthenticator) clientData(t *testing.T, typ string, challenge string) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": a.origin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func (a *softwareAuthenticator) credential(t *testing.T, response map[string]interface{}) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string]interface{}{
+		"id":       b64url.EncodeToString(a.credentialId),
+		"rawId":    b64url.EncodeToString(a.credentialId),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// テスト用のユースケースと、そのリポジトリ
+type passkeyTestEnv struct {
+	ku IPasskeyUsecase
+	ur *fakeUserRepository
+	kr *fakePasskeyRepository
+	sr *fakeSessionRepository
+	rr *fakeRefreshTokenRepository
+}
+
+func newPasskeyTestEnv(t *testing.T, users ...model.User) passkeyTestEnv {
+	t.Helper()
+	t.Setenv("SECRET", "test-secret")
+	wa, err := webauthn.New(&webauthn.Config{RPID: testRPID, RPDisplayName: "test", RPOrigins: []string{testOrigin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := passkeyTestEnv{
+		ur: newFakeUserRepository(users...),
+		kr: &fakePasskeyRepository{},
+		sr: &fakeSessionRepository{},
+		rr: &fakeRefreshTokenRepository{},
+	}
+	env.ku = NewPasskeyUsecase(env.ur, validator.NewPasskeyValidator(), env.kr, env.sr, env.rr, VerificationOptional, wa)
+	return env
+}
+
+// パスキーを登録した認証器を返す
+func (env passkeyTestEnv) register(t *testing.T, userId uint) *softwareAuthenticator {
+	t.Helper()
+	creation, err := env.ku.BeginRegistration(userId, model.PasskeyRegistrationRequest{Name: "laptop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newSoftwareAuthenticator(t)
+	if _, err := env.ku.FinishRegistration(userId, a.create(t, creation, creation.Response.Challenge.String())); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestPasskeyRegistration(t *testing.T) {
+	env := newPasskeyTestEnv(t, model.User{ID: 7, Email: "user@example.com"})
+
+	if _, err := env.ku.BeginRegistration(7, model.PasskeyRegistrationRequest{}); err == nil {
+		t.Fatal("BeginRegistration without a name: expected a validation error")
+	}
+	creation, err := env.ku.BeginRegistration(7, model.PasskeyRegistrationRequest{Name: "laptop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creation.Response.RelyingParty.ID != testRPID {
+		t.Errorf("rp.id = %q, want %q", creation.Response.RelyingParty.ID, testRPID)
+	}
+	a := newSoftwareAuthenticator(t)
+	body := a.create(t, creation, creation.Response.Challenge.String())
+
+	res, err := env.ku.FinishRegistration(7, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Name != "laptop" || len(env.kr.passkeys) != 1 {
+		t.Fatalf("FinishRegistration = %+v, stored %d passkeys", res, len(env.kr.passkeys))
+	}
+	// ユーザーハンドルはユーザーID
+	if string(a.userHandle) != "7" {
+		t.Errorf("user handle = %q, want %q", a.userHandle, "7")
+	}
+	// 同じ応答は再び使えない
+	if _, err := env.ku.FinishRegistration(7, body); !errors.Is(err, ErrInvalidPasskeyRegistration) {
+		t.Errorf("replayed registration: err = %v, want ErrInvalidPasskeyRegistration", err)
+	}
+
+	// 登録済みのパスキーは除外される
+	creation, err = env.ku.BeginRegistration(7, model.PasskeyRegistrationRequest{Name: "phone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(creation.Response.CredentialExcludeList) != 1 {
+		t.Errorf("excludeCredentials has %d entries, want 1", len(creation.Response.CredentialExcludeList))
+	}
+}
+
+func TestPasskeyRegistrationRejectsInvalidResponses(t *testing.T) {
+	env := newPasskeyTestEnv(t, model.User{ID: 7, Email: "user@example.com"}, model.User{ID: 8, Email: "other@example.com"})
+
+	tests := []struct {
+		name   string
+		userId uint   // 登録を完了するユーザー
+		origin string // 認証器が応答に含めるオリジン
+		wrong  bool   // 発行されていないチャレンジに応答する
+	}{
+		{name: "wrong challenge", userId: 7, origin: testOrigin, wrong: true},
+		{name: "wrong origin", userId: 7, origin: "https://attacker.example"},
+		{name: "another user's ceremony", userId: 8, origin: testOrigin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creation, err := env.ku.BeginRegistration(7, model.PasskeyRegistrationRequest{Name: "laptop"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			challenge := creation.Response.Challenge.String()
+			if tt.wrong {
+				challenge = b64url.EncodeToString([]byte("a challenge that was never issued"))
+			}
+			a := newSoftwareAuthenticator(t)
+			a.origin = tt.origin
+			if _, err := env.ku.FinishRegistration(tt.userId, a.create(t, creation, challenge)); !errors.Is(err, ErrInvalidPasskeyRegistration) {
+				t.Errorf("err = %v, want ErrInvalidPasskeyRegistration", err)
+			}
+		})
+	}
+	if len(env.kr.passkeys) != 0 {
+		t.Errorf("stored %d passkeys, want 0", len(env.kr.passkeys))
+	}
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	env := newPasskeyTestEnv(t, model.User{ID: 7, Email: "user@example.com"})
+	a := env.register(t, 7)
+
+	assertion, err := env.ku.BeginLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// パスワードなしのログインでは、認証器での本人確認を必須にする
+	if assertion.Response.UserVerification != protocol.VerificationRequired {
+		t.Errorf("userVerification = %q, want %q", assertion.Response.UserVerification, protocol.VerificationRequired)
+	}
+	body := a.get(t, assertion.Response.Challenge.String())
+	tokens, err := env.ku.FinishLogin(body, model.SessionMeta{UserAgent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("FinishLogin = %+v, want tokens", tokens)
+	}
+	if len(env.sr.sessions) != 1 || env.sr.sessions[0].UserId != 7 {
+		t.Fatalf("sessions = %+v, want one session for user 7", env.sr.sessions)
+	}
+	if p := env.kr.passkeys[0]; p.SignCount != 1 || p.LastUsedAt == nil {
+		t.Errorf("passkey usage not recorded: sign_count = %d, last_used_at = %v", p.SignCount, p.LastUsedAt)
+	}
+	// 同じ応答は再び使えない
+	if _, err := env.ku.FinishLogin(body, model.SessionMeta{}); !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("replayed login: err = %v, want ErrInvalidPasskey", err)
+	}
+}
+
+func TestPasskeyLoginRejectsInvalidResponses(t *testing.T) {
+	env := newPasskeyTestEnv(t, model.User{ID: 7, Email: "user@example.com"})
+	a := env.register(t, 7)
+	// 署名カウンターを記録するため、一度ログインしておく
+	assertion, err := env.ku.BeginLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.ku.FinishLogin(a.get(t, assertion.Response.Challenge.String()), model.SessionMeta{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(a *softwareAuthenticator, challenge string) []byte
+	}{
+		{
+			name: "wrong challenge",
+			modify: func(a *softwareAuthenticator, challenge string) []byte {
+				return a.get(t, b64url.EncodeToString([]byte("a challenge that was never issued")))
+			},
+		},
+		{
+			name: "wrong origin",
+			modify: func(a *softwareAuthenticator, challenge string) []byte {
+				a.origin = "https://attacker.example"
+				defer func() { a.origin = testOrigin }()
+				return a.get(t, challenge)
+			},
+		},
+		{
+			name: "bad signature",
+			modify: func(a *softwareAuthenticator, challenge string) []byte {
+				body := map[string]interface{}{}
+				if err := json.Unmarshal(a.get(t, challenge), &body); err != nil {
+					t.Fatal(err)
+				}
+				body["response"].(map[string]interface{})["signature"] = b64url.EncodeToString([]byte("not a signature"))
+				b, _ := json.Marshal(body)
+				return b
+			},
+		},
+		{
+			name: "unknown user handle",
+			modify: func(a *softwareAuthenticator, challenge string) []byte {
+				handle := a.userHandle
+				a.userHandle = []byte("99")
+				defer func() { a.userHandle = handle }()
+				return a.get(t, challenge)
+			},
+		},
+		{
+			// 署名カウンターが戻っている（認証器が複製された可能性がある）
+			name: "cloned authenticator",
+			modify: func(a *softwareAuthenticator, challenge string) []byte {
+				a.signCount = 0
+				return a.get(t, challenge)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 直前のテストで使った署名カウンターより大きくする
+			a.signCount = env.kr.passkeys[0].SignCount + 1
+			assertion, err := env.ku.BeginLogin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := tt.modify(a, assertion.Response.Challenge.String())
+			if _, err := env.ku.FinishLogin(body, model.SessionMeta{}); !errors.Is(err, ErrInvalidPasskey) {
+				t.Errorf("err = %v, want ErrInvalidPasskey", err)
+			}
+		})
+	}
+	if len(env.sr.sessions) != 1 {
+		t.Errorf("created %d sessions, want only the first login", len(env.sr.sessions))
+	}
+}
+
+func TestPasskeyAsSecondFactor(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := model.User{ID: 7, Email: "user@example.com", Password: string(hash)}
+	env := newPasskeyTestEnv(t, user, model.User{ID: 8, Email: "other@example.com", Password: string(hash)})
+	uu := NewUserUsecase(env.ur, validator.NewUserValidator(validator.LoadConfig()), nil, env.rr, nil, env.sr, nil, nil, env.kr, VerificationOptional)
+
+	// パスキーを登録していないユーザーは、パスワードのみでログインできる
+	result, err := uu.LogIn(model.User{Email: "user@example.com", Password: "password"}, model.SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.MfaToken != "" || result.Tokens.AccessToken == "" {
+		t.Fatalf("LogIn without passkeys = %+v, want tokens", result)
+	}
+
+	// パスキーを登録すると、2段階目にパスキーが必要になる（2段階認証（TOTP）は有効でない）
+	a := env.register(t, 7)
+	result, err = uu.LogIn(model.User{Email: "user@example.com", Password: "password"}, model.SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.MfaToken == "" || result.Tokens.AccessToken != "" {
+		t.Fatalf("LogIn with a passkey = %+v, want an mfa token", result)
+	}
+	if len(result.MfaMethods) != 1 || result.MfaMethods[0] != model.MfaMethodPasskey {
+		t.Errorf("mfa methods = %v, want [passkey]", result.MfaMethods)
+	}
+	// 2段階認証が有効でないため、確認コードは使えない
+	if _, err := uu.LogInMfa(model.MfaLoginRequest{MfaToken: result.MfaToken, Code: "123456"}, model.SessionMeta{}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("LogInMfa: err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	// パスワードなしのログインの応答は、2段階目には使えない
+	login, err := env.ku.BeginLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.ku.FinishMfaLogin(a.get(t, login.Response.Challenge.String()), model.SessionMeta{}); !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("FinishMfaLogin with a login ceremony: err = %v, want ErrInvalidPasskey", err)
+	}
+
+	assertion, err := env.ku.BeginMfaLogin(model.MfaPasskeyRequest{MfaToken: result.MfaToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2段階目では、ログイン中のユーザーのパスキーのみを受け付ける
+	if len(assertion.Response.AllowedCredentials) != 1 || !bytes.Equal(assertion.Response.AllowedCredentials[0].CredentialID, a.credentialId) {
+		t.Errorf("allowCredentials = %+v, want the registered passkey", assertion.Response.AllowedCredentials)
+	}
+	tokens, err := env.ku.FinishMfaLogin(a.get(t, assertion.Response.Challenge.String()), model.SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.AccessToken == "" {
+		t.Fatalf("FinishMfaLogin = %+v, want tokens", tokens)
+	}
+
+	// 2段階目が不要なユーザー（パスキーも2段階認証もない）のトークンや、正しくないトークンは拒否される
+	forged := signToken(purposeMfaLogin, strconv.Itoa(8), time.Now().Add(time.Minute))
+	if _, err := env.ku.BeginMfaLogin(model.MfaPasskeyRequest{MfaToken: forged}); !errors.Is(err, ErrInvalidMfaToken) {
+		t.Errorf("BeginMfaLogin for a user without a second factor: err = %v, want ErrInvalidMfaToken", err)
+	}
+	if _, err := env.ku.BeginMfaLogin(model.MfaPasskeyRequest{MfaToken: "invalid"}); !errors.Is(err, ErrInvalidMfaToken) {
+		t.Errorf("BeginMfaLogin with an invalid token: err = %v, want ErrInvalidMfaToken", err)
+	}
+}
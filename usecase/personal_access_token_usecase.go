@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+var (
+	// パーソナルアクセストークンが存在しない（削除された）、または有効期限が切れている場合のエラー
+	ErrInvalidPersonalAccessToken = newError(KindUnauthorized, "invalid_personal_access_token", "personal access token is invalid or expired")
+	// 有効期限に過去の日時が指定された場合のエラー
+	ErrInvalidTokenExpiry = newError(KindInvalid, "invalid_token_expiry", "expires_at must be in the future")
+)
+
+// パーソナルアクセストークンに関するユースケースを定義
+type IPersonalAccessTokenUsecase interface {
+	GetPersonalAccessTokens(userId uint) ([]model.PersonalAccessTokenResponse, error)                                                // トークン一覧を取得
+	GetPersonalAccessTokenById(userId uint, tokenId uint) (model.PersonalAccessTokenResponse, error)                                 // 特定のトークンを取得
+	CreatePersonalAccessToken(token model.PersonalAccessToken) (model.PersonalAccessTokenCreatedResponse, error)                     // トークンを作成（平文のトークンを返す）
+	UpdatePersonalAccessToken(token model.PersonalAccessToken, userId uint, tokenId uint) (model.PersonalAccessTokenResponse, error) // トークンの名前とスコープを更新
+	DeletePersonalAccessToken(userId uint, tokenId uint) error                                                                       // トークンを削除
+	Authenticate(secret string) (model.PersonalAccessToken, error)                                                                   // リクエストのトークンを検証
+}
+
+type personalAccessTokenUsecase struct {
+	pr repository.IPersonalAccessTokenRepository // トークンのリポジトリ
+	pv validator.IPersonalAccessTokenValidator   // トークンのバリデーション
+}
+
+// コンストラクタ関数
+func NewPersonalAccessTokenUsecase(pr repository.IPersonalAccessTokenRepository, pv validator.IPersonalAccessTokenValidator) IPersonalAccessTokenUsecase {
+	return &personalAccessTokenUsecase{pr, pv}
+}
+
+// トークン一覧を作成日時の新しい順に取得
+func (pu *personalAccessTokenUsecase) GetPersonalAccessTokens(userId uint) ([]model.PersonalAccessTokenResponse, error) {
+	tokens := []model.PersonalAccessToken{}
+	if err := pu.pr.GetPersonalAccessTokens(&tokens, userId); err != nil {
+		return nil, err
+	}
+	resTokens := []model.PersonalAccessTokenResponse{}
+	for _, v := range tokens {
+		resTokens = append(resTokens, toPersonalAccessTokenResponse(v))
+	}
+	return resTokens, nil
+}
+
+// 特定のトークンを取得
+func (pu *personalAccessTokenUsecase) GetPersonalAccessTokenById(userId uint, tokenId uint) (model.PersonalAccessTokenResponse, error) {
+	token := model.PersonalAccessToken{}
+	if err := pu.pr.GetPersonalAccessTokenById(&token, userId, tokenId); err != nil {
+		return model.PersonalAccessTokenResponse{}, err
+	}
+	return toPersonalAccessTokenResponse(token), nil
+}
+
+// トークンを作成
+// 平文のトークンはこのレスポンスでのみ返し、データベースにはハッシュのみを保存する
+func (pu *personalAccessTokenUsecase) CreatePersonalAccessToken(token model.PersonalAccessToken) (model.PersonalAccessTokenCreatedResponse, error) {
+	if err := pu.pv.PersonalAccessTokenValidate(token); err != nil {
+		return model.PersonalAccessTokenCreatedResponse{}, err
+	}
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return model.PersonalAccessTokenCreatedResponse{}, ErrInvalidTokenExpiry
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return model.PersonalAccessTokenCreatedResponse{}, err
+	}
+	secret = model.PersonalAccessTokenPrefix + secret
+	// リクエストで指定できる項目のみを保存する
+	newToken := model.PersonalAccessToken{
+		Name:      token.Name,
+		TokenHash: hashToken(secret),
+		Scopes:    token.Scopes,
+		ExpiresAt: token.ExpiresAt,
+		UserId:    token.UserId,
+	}
+	if err := pu.pr.CreatePersonalAccessToken(&newToken); err != nil {
+		return model.PersonalAccessTokenCreatedResponse{}, err
+	}
+	return model.PersonalAccessTokenCreatedResponse{
+		PersonalAccessTokenResponse: toPersonalAccessTokenResponse(newToken),
+		Token:                       secret,
+	}, nil
+}
+
+// トークンの名前とスコープを更新
+func (pu *personalAccessTokenUsecase) UpdatePersonalAccessToken(token model.PersonalAccessToken, userId uint, tokenId uint) (model.PersonalAccessTokenResponse, error) {
+	if err := pu.pv.PersonalAccessTokenValidate(token); err != nil {
+		return model.PersonalAccessTokenResponse{}, err
+	}
+	if err := pu.pr.UpdatePersonalAccessToken(&token, userId, tokenId); err != nil {
+		return model.PersonalAccessTokenResponse{}, err
+	}
+	return toPersonalAccessTokenResponse(token), nil
+}
+
+// トークンを削除
+func (pu *personalAccessTokenUsecase) DeletePersonalAccessToken(userId uint, tokenId uint) error {
+	if err := pu.pr.DeletePersonalAccessToken(userId, tokenId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// リクエストのトークンを検証し、有効な場合は最終使用日時を記録する
+func (pu *personalAccessTokenUsecase) Authenticate(secret string) (model.PersonalAccessToken, error) {
+	now := time.Now()
+	token := model.PersonalAccessToken{}
+	if err := pu.pr.GetPersonalAccessTokenByHash(&token, hashToken(secret)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.PersonalAccessToken{}, ErrInvalidPersonalAccessToken
+		}
+		return model.PersonalAccessToken{}, err
+	}
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(now) {
+		return model.PersonalAccessToken{}, ErrInvalidPersonalAccessToken
+	}
+	// 最終使用日時の更新に失敗してもリクエストは処理する
+	if err := pu.pr.TouchPersonalAccessToken(token.ID, now); err != nil {
+		log.Printf("failed to update personal access token %d: %v", token.ID, err)
+	}
+	return token, nil
+}
+
+// トークンをレスポンス形式に変換
+func toPersonalAccessTokenResponse(token model.PersonalAccessToken) model.PersonalAccessTokenResponse {
+	return model.PersonalAccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scopes:     token.Scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+		UpdatedAt:  token.UpdatedAt,
+	}
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// カスタムフィールドの定義を追加・変更できない場合のエラー
+var ErrInvalidCustomField = newError(KindInvalid, "invalid_custom_field", "invalid custom field")
+
+// プロジェクトとカスタムフィールドの定義に関するユースケースを定義
+type IProjectUsecase interface {
+	GetAllProjects(userId uint) ([]model.ProjectResponse, error)                                                             // プロジェクト一覧を取得
+	GetProjectById(userId uint, projectId uint) (model.ProjectResponse, error)                                               // 特定のプロジェクトを取得
+	CreateProject(project model.Project) (model.ProjectResponse, error)                                                      // プロジェクトを作成
+	UpdateProject(project model.Project, userId uint, projectId uint) (model.ProjectResponse, error)                         // プロジェクトを更新
+	DeleteProject(userId uint, projectId uint) error                                                                         // プロジェクトを削除
+	GetCustomFields(userId uint, projectId uint) ([]model.CustomFieldResponse, error)                                        // カスタムフィールドの一覧を取得
+	CreateCustomField(field model.CustomField, userId uint, projectId uint) (model.CustomFieldResponse, error)               // カスタムフィールドを追加
+	UpdateCustomField(field model.CustomField, userId uint, projectId uint, fieldId uint) (model.CustomFieldResponse, error) // カスタムフィールドを更新
+	DeleteCustomField(userId uint, projectId uint, fieldId uint) error                                                       // カスタムフィールドを削除
+}
+
+type projectUsecase struct {
+	pr repository.IProjectRepository // プロジェクトのリポジトリ
+	pv validator.IProjectValidator   // プロジェクトとカスタムフィールドのバリデーション
+}
+
+// コンストラクタ関数
+func NewProjectUsecase(pr repository.IProjectRepository, pv validator.IProjectValidator) IProjectUsecase {
+	return &projectUsecase{pr, pv}
+}
+
+// プロジェクト一覧を取得
+func (pu *projectUsecase) GetAllProjects(userId uint) ([]model.ProjectResponse, error) {
+	projects := []model.Project{}
+	if err := pu.pr.GetAllProjects(&projects, userId); err != nil {
+		return nil, err
+	}
+	resProjects := []model.ProjectResponse{}
+	for _, v := range projects {
+		resProjects = append(resProjects, toProjectResponse(v))
+	}
+	return resProjects, nil
+}
+
+// 特定のプロジェクトを取得
+func (pu *projectUsecase) GetProjectById(userId uint, projectId uint) (model.ProjectResponse, error) {
+	project := model.Project{}
+	if err := pu.pr.GetProjectById(&project, userId, projectId); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+// プロジェクトを作成
+func (pu *projectUsecase) CreateProject(project model.Project) (model.ProjectResponse, error) {
+	if err := pu.pv.ProjectValidate(project); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	// リクエストで指定できる項目のみを保存する（関連するユーザーが作成されないようにする）
+	newProject := model.Project{
+		Name:   project.Name,
+		UserId: project.UserId,
+	}
+	if err := pu.pr.CreateProject(&newProject); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return toProjectResponse(newProject), nil
+}
+
+// プロジェクトを更新
+func (pu *projectUsecase) UpdateProject(project model.Project, userId uint, projectId uint) (model.ProjectResponse, error) {
+	if err := pu.pv.ProjectValidate(project); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	if err := pu.pr.UpdateProject(&project, userId, projectId); err != nil {
+		return model.ProjectResponse{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+// プロジェクトを削除（タスクはプロジェクトから外れ、カスタムフィールドの値は消去される）
+func (pu *projectUsecase) DeleteProject(userId uint, projectId uint) error {
+	if err := pu.pr.DeleteProject(userId, projectId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// プロジェクトのカスタムフィールドを表示順に取得
+func (pu *projectUsecase) GetCustomFields(userId uint, projectId uint) ([]model.CustomFieldResponse, error) {
+	project := model.Project{}
+	if err := pu.pr.GetProjectById(&project, userId, projectId); err != nil {
+		return nil, err
+	}
+	fields := []model.CustomField{}
+	if err := pu.pr.GetCustomFields(&fields, userId, projectId); err != nil {
+		return nil, err
+	}
+	resFields := []model.CustomFieldResponse{}
+	for _, v := range fields {
+		resFields = append(resFields, toCustomFieldResponse(v))
+	}
+	return resFields, nil
+}
+
+// カスタムフィールドを追加（キーはプロジェクト内で一意）
+func (pu *projectUsecase) CreateCustomField(field model.CustomField, userId uint, projectId uint) (model.CustomFieldResponse, error) {
+	if field.Options == nil {
+		field.Options = model.Labels{}
+	}
+	if err := pu.pv.CustomFieldValidate(field); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	project := model.Project{}
+	if err := pu.pr.GetProjectById(&project, userId, projectId); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	fields := []model.CustomField{}
+	if err := pu.pr.GetCustomFields(&fields, userId, projectId); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	for _, v := range fields {
+		if v.Key == field.Key {
+			return model.CustomFieldResponse{}, fmt.Errorf("%w: key %q is already used in the project", ErrInvalidCustomField, field.Key)
+		}
+	}
+
+	// リクエストで指定できる項目のみを保存する（表示順はリポジトリで末尾に設定する）
+	newField := model.CustomField{
+		Key:       field.Key,
+		Name:      field.Name,
+		Type:      field.Type,
+		Options:   field.Options,
+		Required:  field.Required,
+		ProjectId: projectId,
+	}
+	if err := pu.pr.CreateCustomField(&newField); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	return toCustomFieldResponse(newField), nil
+}
+
+// カスタムフィールドの名前・選択肢・必須かどうかを更新
+// 既存の値が無効にならないよう、キーと種類は変更できず、選択肢は追加のみできる
+func (pu *projectUsecase) UpdateCustomField(field model.CustomField, userId uint, projectId uint, fieldId uint) (model.CustomFieldResponse, error) {
+	current := model.CustomField{}
+	if err := pu.pr.GetCustomFieldById(&current, userId, projectId, fieldId); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	if field.Key == "" {
+		field.Key = current.Key
+	}
+	if field.Type == "" {
+		field.Type = current.Type
+	}
+	if field.Key != current.Key || field.Type != current.Type {
+		return model.CustomFieldResponse{}, fmt.Errorf("%w: key and type cannot be changed", ErrInvalidCustomField)
+	}
+	if field.Options == nil {
+		field.Options = model.Labels{}
+	}
+	if err := pu.pv.CustomFieldValidate(field); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	kept := map[string]bool{}
+	for _, option := range field.Options {
+		kept[option] = true
+	}
+	for _, option := range current.Options {
+		if !kept[option] {
+			return model.CustomFieldResponse{}, fmt.Errorf("%w: option %q cannot be removed", ErrInvalidCustomField, option)
+		}
+	}
+
+	if err := pu.pr.UpdateCustomField(&field, userId, projectId, fieldId); err != nil {
+		return model.CustomFieldResponse{}, err
+	}
+	return toCustomFieldResponse(field), nil
+}
+
+// カスタムフィールドを削除（プロジェクトのタスクからも値が削除される）
+func (pu *projectUsecase) DeleteCustomField(userId uint, projectId uint, fieldId uint) error {
+	if err := pu.pr.DeleteCustomField(userId, projectId, fieldId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// プロジェクトをレスポンス形式に変換
+func toProjectResponse(project model.Project) model.ProjectResponse {
+	return model.ProjectResponse{
+		ID:        project.ID,
+		Name:      project.Name,
+		CreatedAt: project.CreatedAt,
+		UpdatedAt: project.UpdatedAt,
+	}
+}
+
+// カスタムフィールドをレスポンス形式に変換
+func toCustomFieldResponse(field model.CustomField) model.CustomFieldResponse {
+	return model.CustomFieldResponse{
+		ID:        field.ID,
+		Key:       field.Key,
+		Name:      field.Name,
+		Type:      field.Type,
+		Options:   field.Options,
+		Required:  field.Required,
+		Position:  field.Position,
+		ProjectId: field.ProjectId,
+		CreatedAt: field.CreatedAt,
+		UpdatedAt: field.UpdatedAt,
+	}
+}
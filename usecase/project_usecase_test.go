@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// プロジェクトとカスタムフィールド
+type fakeProjectRepository struct {
+	repository.IProjectRepository
+	projects []model.Project
+	fields   []model.CustomField
+}
+
+func (pr *fakeProjectRepository) GetProjectById(project *model.Project, userId uint, projectId uint) error {
+	for _, p := range pr.projects {
+		if p.ID == projectId && p.UserId == userId {
+			*project = p
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (pr *fakeProjectRepository) CreateProject(project *model.Project) error {
+	project.ID = uint(len(pr.projects) + 1)
+	pr.projects = append(pr.projects, *project)
+	return nil
+}
+
+func (pr *fakeProjectRepository) GetCustomFields(fields *[]model.CustomField, userId uint, projectId uint) error {
+	*fields = []model.CustomField{}
+	for _, f := range pr.fields {
+		if f.ProjectId == projectId {
+			*fields = append(*fields, f)
+		}
+	}
+	return nil
+}
+
+func (pr *fakeProjectRepository) CreateCustomField(field *model.CustomField) error {
+	field.ID = uint(len(pr.fields) + 1)
+	pr.fields = append(pr.fields, *field)
+	return nil
+}
+
+func TestCreateProjectIgnoresNestedAssociations(t *testing.T) {
+	pr := &fakeProjectRepository{}
+	pu := NewProjectUsecase(pr, validator.NewProjectValidator())
+
+	body := `{"id":5,"name":"Acme","user_id":2,
+		"user":{"email":"new@example.com","password":"secret","email_verified_at":"2026-01-01T00:00:00Z"}}`
+	project := model.Project{}
+	if err := json.Unmarshal([]byte(body), &project); err != nil {
+		t.Fatal(err)
+	}
+	project.UserId = 1
+	if _, err := pu.CreateProject(project); err != nil {
+		t.Fatal(err)
+	}
+	wantProjects := []model.Project{{ID: 1, Name: "Acme", UserId: 1}}
+	if !reflect.DeepEqual(pr.projects, wantProjects) {
+		t.Errorf("saved projects = %+v, want %+v", pr.projects, wantProjects)
+	}
+
+	body = `{"id":9,"key":"points","name":"Points","type":"number","position":7,"project_id":2,
+		"project":{"name":"other","user_id":2}}`
+	field := model.CustomField{}
+	if err := json.Unmarshal([]byte(body), &field); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pu.CreateCustomField(field, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	wantFields := []model.CustomField{{ID: 1, Key: "points", Name: "Points", Type: model.CustomFieldNumber, Options: model.Labels{}, ProjectId: 1}}
+	if !reflect.DeepEqual(pr.fields, wantFields) {
+		t.Errorf("saved fields = %+v, want %+v", pr.fields, wantFields)
+	}
+}
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/mailer"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// webhook の送信のタイムアウト（接続から応答の受信まで）
+const webhookTimeout = 10 * time.Second
+
+// webhook の送信先が内部のアドレスに解決された場合のエラー
+var errWebhookAddressNotAllowed = errors.New("webhook address is not allowed")
+
+// リマインダーの通知方法ごとの送信処理
+// model.Reminder.Channel の値をキーにして reminderUsecase に登録する
+type IReminderChannel interface {
+	Send(reminder model.Reminder, task model.Task) error
+}
+
+// メールで通知する
+type emailReminderChannel struct {
+	er repository.IEmailPreferenceRepository // メールの言語の取得
+	ur repository.IUserRepository            // 送信先とタイムゾーンの取得
+	m  mailer.IMailer
+}
+
+// コンストラクタ関数
+func NewEmailReminderChannel(er repository.IEmailPreferenceRepository, ur repository.IUserRepository, m mailer.IMailer) IReminderChannel {
+	return &emailReminderChannel{er, ur, m}
+}
+
+// リマインダーメールを送信
+func (c *emailReminderChannel) Send(reminder model.Reminder, task model.Task) error {
+	pref := model.EmailPreference{}
+	if err := c.er.GetEmailPreference(&pref, reminder.UserId); err != nil {
+		return err
+	}
+	user := model.User{}
+	if err := c.ur.GetUserById(&user, reminder.UserId); err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return err
+	}
+	msg, err := mailer.Render(mailer.TemplateReminder, pref.Language, user.Email, mailer.ReminderData{
+		Title: task.Title,
+		Due:   task.DueAt.In(loc).Format(mailTimeLayout),
+	})
+	if err != nil {
+		return err
+	}
+	return c.m.Send(msg)
+}
+
+// 指定されたURLへJSONをPOSTして通知する
+type webhookReminderChannel struct {
+	ur     repository.IUserRepository // 署名のシークレットの取得
+	client *http.Client
+}
+
+// コンストラクタ関数
+func NewWebhookReminderChannel(ur repository.IUserRepository) IReminderChannel {
+	return &webhookReminderChannel{ur, newWebhookClient()}
+}
+
+// webhook の送信に使うHTTPクライアント
+// 送信先はユーザーが指定するため、サーバー内部のサービスに送信されないよう
+// 名前解決後のアドレスを接続の直前に確認し、リダイレクトには従わない（3xx は失敗として扱う）
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: checkWebhookAddress}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // 環境変数のプロキシを経由すると送信先のアドレスを確認できないため使わない
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   webhookTimeout,
+			ResponseHeaderTimeout: webhookTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// 接続先のアドレスがループバック、プライベート、リンクローカルなどの場合は接続しない
+func checkWebhookAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !validator.IsPublicAddress(ip) {
+		return fmt.Errorf("%w: %s", errWebhookAddressNotAllowed, ip)
+	}
+	return nil
+}
+
+// webhook で送信するJSON
+type reminderWebhookPayload struct {
+	Event         string             `json:"event"`
+	ReminderId    uint               `json:"reminder_id"`
+	OffsetMinutes int                `json:"offset_minutes"`
+	Task          model.TaskResponse `json:"task"`
+}
+
+// webhook を送信
+// ユーザーごとのシークレットによる本文のHMAC-SHA256署名を X-Webhook-Signature ヘッダーに付与する
+func (c *webhookReminderChannel) Send(reminder model.Reminder, task model.Task) error {
+	secret, err := userWebhookSecret(c.ur, reminder.UserId)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(reminderWebhookPayload{
+		Event:         "task.reminder",
+		ReminderId:    reminder.ID,
+		OffsetMinutes: reminder.OffsetMinutes,
+		Task:          toTaskResponse(task),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, reminder.WebhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// 2xx 以外は失敗として再送の対象にする
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// ユーザーの webhook の署名のシークレットを取得（まだない場合は生成して保存する）
+func userWebhookSecret(ur repository.IUserRepository, userId uint) (string, error) {
+	user := model.User{}
+	if err := ur.GetUserById(&user, userId); err != nil {
+		return "", err
+	}
+	if user.WebhookSecret != "" {
+		return user.WebhookSecret, nil
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := ur.SetWebhookSecret(userId, secret, ""); err != nil {
+		// 同時に生成された場合は、保存された方を使う
+		if errors.Is(err, repository.ErrNotFound) {
+			if err := ur.GetUserById(&user, userId); err != nil {
+				return "", err
+			}
+			return user.WebhookSecret, nil
+		}
+		return "", err
+	}
+	return secret, nil
+}
+
+// アプリ内通知で通知する
+type inAppReminderChannel struct {
+	nu INotificationUsecase
+}
+
+// コンストラクタ関数
+func NewInAppReminderChannel(nu INotificationUsecase) IReminderChannel {
+	return &inAppReminderChannel{nu}
+}
+
+// 受信箱に通知を追加（接続中であればリアルタイムに配信される）
+func (c *inAppReminderChannel) Send(reminder model.Reminder, task model.Task) error {
+	c.nu.NotifyReminder(reminder.UserId, task)
+	return nil
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+const (
+	reminderBatchSize   = 100       // 1回の取得で処理するリマインダーの件数
+	reminderMaxAttempts = 5         // 送信に失敗した場合に再送する最大回数
+	reminderGracePeriod = time.Hour // 停止などで遅れた場合に、通知時刻からどれだけ経過しても送信するか
+)
+
+// リマインダーに関するユースケースを定義
+type IReminderUsecase interface {
+	GetReminders(userId uint, taskId uint) ([]model.ReminderResponse, error)                          // タスクに設定した自分のリマインダーを取得
+	CreateReminder(reminder model.Reminder, userId uint, taskId uint) (model.ReminderResponse, error) // リマインダーを作成
+	DeleteReminder(userId uint, taskId uint, reminderId uint) error                                   // リマインダーを削除
+	SendDueReminders(now time.Time) error                                                             // 通知時刻を迎えたリマインダーを送信（スケジューラーから呼び出す）
+	GetWebhookSecret(userId uint) (model.WebhookSecretResponse, error)                                // webhook の署名のシークレットを取得
+	RotateWebhookSecret(userId uint) (model.WebhookSecretResponse, error)                             // webhook の署名のシークレットを再発行する
+}
+
+type reminderUsecase struct {
+	rr       repository.IReminderRepository // リマインダーのリポジトリ
+	rv       validator.IReminderValidator   // リマインダーのバリデーション
+	tr       repository.ITaskRepository     // 対象のタスクの取得
+	ur       repository.IUserRepository     // webhook の署名のシークレットの管理
+	channels map[string]IReminderChannel    // 通知方法ごとの送信処理
+}
+
+// コンストラクタ関数
+// channels には model.ReminderChannelEmail などをキーにして送信処理を渡す
+func NewReminderUsecase(rr repository.IReminderRepository, rv validator.IReminderValidator, tr repository.ITaskRepository,
+	ur repository.IUserRepository, channels map[string]IReminderChannel) IReminderUsecase {
+	return &reminderUsecase{rr, rv, tr, ur, channels}
+}
+
+// タスクに設定した自分のリマインダーを取得
+func (ru *reminderUsecase) GetReminders(userId uint, taskId uint) ([]model.ReminderResponse, error) {
+	reminders := []model.Reminder{}
+	if err := ru.rr.GetReminders(&reminders, userId, taskId); err != nil {
+		return nil, err
+	}
+	resReminders := []model.ReminderResponse{}
+	for _, v := range reminders {
+		resReminders = append(resReminders, toReminderResponse(v))
+	}
+	return resReminders, nil
+}
+
+// リマインダーを作成（閲覧できるタスクにのみ設定できる）
+func (ru *reminderUsecase) CreateReminder(reminder model.Reminder, userId uint, taskId uint) (model.ReminderResponse, error) {
+	if err := ru.rv.ReminderValidate(reminder); err != nil {
+		return model.ReminderResponse{}, err
+	}
+	task := model.Task{}
+	if err := ru.tr.GetTaskById(&task, userId, taskId); err != nil {
+		return model.ReminderResponse{}, err
+	}
+	newReminder := model.Reminder{
+		OffsetMinutes: reminder.OffsetMinutes,
+		Channel:       reminder.Channel,
+		WebhookUrl:    reminder.WebhookUrl,
+		TaskId:        task.ID,
+		UserId:        userId,
+	}
+	if err := ru.rr.CreateReminder(&newReminder); err != nil {
+		return model.ReminderResponse{}, err
+	}
+	return toReminderResponse(newReminder), nil
+}
+
+// リマインダーを削除
+func (ru *reminderUsecase) DeleteReminder(userId uint, taskId uint, reminderId uint) error {
+	return ru.rr.DeleteReminder(userId, taskId, reminderId)
+}
+
+// 通知時刻を迎えたリマインダーを送信
+// 取得した時点で送信済みとして記録されるため、送信に失敗したものだけを再送できる状態に戻す
+func (ru *reminderUsecase) SendDueReminders(now time.Time) error {
+	for {
+		reminders := []model.Reminder{}
+		if err := ru.rr.ClaimDueReminders(&reminders, now, now.Add(-reminderGracePeriod), reminderBatchSize); err != nil {
+			return err
+		}
+		failed := false
+		for _, r := range reminders {
+			if err := ru.send(r); err != nil {
+				failed = true
+				log.Printf("reminder: failed to send reminder %d via %s: %v", r.ID, r.Channel, err)
+				if err := ru.rr.ReleaseReminder(r.ID, reminderMaxAttempts); err != nil {
+					log.Printf("reminder: failed to release reminder %d: %v", r.ID, err)
+				}
+			}
+		}
+		// 取得件数が上限未満であれば、対象のリマインダーはすべて処理済み
+		// 失敗したものがある場合は、すぐに再送しないよう次回の実行に回す
+		if len(reminders) < reminderBatchSize || failed {
+			return nil
+		}
+	}
+}
+
+// 1件のリマインダーを送信
+func (ru *reminderUsecase) send(reminder model.Reminder) error {
+	channel, ok := ru.channels[reminder.Channel]
+	if !ok {
+		return fmt.Errorf("unknown reminder channel: %s", reminder.Channel)
+	}
+	task := model.Task{}
+	if err := ru.tr.GetTaskById(&task, reminder.UserId, reminder.TaskId); err != nil {
+		return err
+	}
+	return channel.Send(reminder, task)
+}
+
+// リマインダーをレスポンス形式に変換
+func toReminderResponse(reminder model.Reminder) model.ReminderResponse {
+	return model.ReminderResponse{
+		ID:            reminder.ID,
+		OffsetMinutes: reminder.OffsetMinutes,
+		Channel:       reminder.Channel,
+		WebhookUrl:    reminder.WebhookUrl,
+		SentAt:        reminder.SentAt,
+		CreatedAt:     reminder.CreatedAt,
+		TaskId:        reminder.TaskId,
+	}
+}
+
+// webhook の署名のシークレットを取得（まだない場合は生成する）
+func (ru *reminderUsecase) GetWebhookSecret(userId uint) (model.WebhookSecretResponse, error) {
+	secret, err := userWebhookSecret(ru.ur, userId)
+	if err != nil {
+		return model.WebhookSecretResponse{}, err
+	}
+	return model.WebhookSecretResponse{Secret: secret}, nil
+}
+
+// webhook の署名のシークレットを再発行する（以前のシークレットによる署名は送信されなくなる）
+func (ru *reminderUsecase) RotateWebhookSecret(userId uint) (model.WebhookSecretResponse, error) {
+	user := model.User{}
+	if err := ru.ur.GetUserById(&user, userId); err != nil {
+		return model.WebhookSecretResponse{}, err
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return model.WebhookSecretResponse{}, err
+	}
+	if err := ru.ur.SetWebhookSecret(userId, secret, user.WebhookSecret); err != nil {
+		// 同時に再発行された場合
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.WebhookSecretResponse{}, ErrConflict
+		}
+		return model.WebhookSecretResponse{}, err
+	}
+	return model.WebhookSecretResponse{Secret: secret}, nil
+}
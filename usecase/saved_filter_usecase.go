@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// 保存済みフィルターに関するユースケースを定義
+type ISavedFilterUsecase interface {
+	GetAllSavedFilters(userId uint) ([]model.SavedFilterResponse, error)                                       // フィルター一覧を取得
+	GetSavedFilterById(userId uint, filterId uint) (model.SavedFilterResponse, error)                          // 特定のフィルターを取得
+	CreateSavedFilter(filter model.SavedFilter) (model.SavedFilterResponse, error)                             // フィルターを作成
+	UpdateSavedFilter(filter model.SavedFilter, userId uint, filterId uint) (model.SavedFilterResponse, error) // フィルターを更新
+	DeleteSavedFilter(userId uint, filterId uint) error                                                        // フィルターを削除
+	GetFilteredTasks(userId uint, filterId uint) ([]model.TaskResponse, error)                                 // フィルターを適用してタスクを取得
+}
+
+type savedFilterUsecase struct {
+	fr repository.ISavedFilterRepository // 保存済みフィルターのリポジトリ
+	fv validator.ISavedFilterValidator   // 保存済みフィルターのバリデーション
+	tu ITaskUsecase                      // クエリの実行に使用するタスクのユースケース
+}
+
+// コンストラクタ関数
+func NewSavedFilterUsecase(fr repository.ISavedFilterRepository, fv validator.ISavedFilterValidator, tu ITaskUsecase) ISavedFilterUsecase {
+	return &savedFilterUsecase{fr, fv, tu}
+}
+
+// フィルター一覧を取得
+func (fu *savedFilterUsecase) GetAllSavedFilters(userId uint) ([]model.SavedFilterResponse, error) {
+	filters := []model.SavedFilter{}
+	if err := fu.fr.GetAllSavedFilters(&filters, userId); err != nil {
+		return nil, err
+	}
+	resFilters := []model.SavedFilterResponse{}
+	for _, v := range filters {
+		resFilters = append(resFilters, toSavedFilterResponse(v))
+	}
+	return resFilters, nil
+}
+
+// 特定のフィルターを取得
+func (fu *savedFilterUsecase) GetSavedFilterById(userId uint, filterId uint) (model.SavedFilterResponse, error) {
+	filter := model.SavedFilter{}
+	if err := fu.fr.GetSavedFilterById(&filter, userId, filterId); err != nil {
+		return model.SavedFilterResponse{}, err
+	}
+	return toSavedFilterResponse(filter), nil
+}
+
+// フィルターを作成
+func (fu *savedFilterUsecase) CreateSavedFilter(filter model.SavedFilter) (model.SavedFilterResponse, error) {
+	// 名前とクエリのバリデーション
+	if err := fu.fv.SavedFilterValidate(filter); err != nil {
+		return model.SavedFilterResponse{}, err
+	}
+	// リクエストで指定できる項目のみを保存する（関連するユーザーが作成されないようにする）
+	newFilter := model.SavedFilter{
+		Name:   filter.Name,
+		Query:  filter.Query,
+		Pinned: filter.Pinned,
+		UserId: filter.UserId,
+	}
+	if err := fu.fr.CreateSavedFilter(&newFilter); err != nil {
+		return model.SavedFilterResponse{}, err
+	}
+	return toSavedFilterResponse(newFilter), nil
+}
+
+// フィルターを更新
+func (fu *savedFilterUsecase) UpdateSavedFilter(filter model.SavedFilter, userId uint, filterId uint) (model.SavedFilterResponse, error) {
+	// 名前とクエリのバリデーション
+	if err := fu.fv.SavedFilterValidate(filter); err != nil {
+		return model.SavedFilterResponse{}, err
+	}
+	if err := fu.fr.UpdateSavedFilter(&filter, userId, filterId); err != nil {
+		return model.SavedFilterResponse{}, err
+	}
+	return toSavedFilterResponse(filter), nil
+}
+
+// フィルターを削除
+func (fu *savedFilterUsecase) DeleteSavedFilter(userId uint, filterId uint) error {
+	if err := fu.fr.DeleteSavedFilter(userId, filterId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// 保存済みのクエリでタスクを検索
+func (fu *savedFilterUsecase) GetFilteredTasks(userId uint, filterId uint) ([]model.TaskResponse, error) {
+	filter := model.SavedFilter{}
+	if err := fu.fr.GetSavedFilterById(&filter, userId, filterId); err != nil {
+		return nil, err
+	}
+	return fu.tu.SearchTasks(userId, filter.Query)
+}
+
+// フィルターをレスポンス形式に変換
+func toSavedFilterResponse(filter model.SavedFilter) model.SavedFilterResponse {
+	return model.SavedFilterResponse{
+		ID:        filter.ID,
+		Name:      filter.Name,
+		Query:     filter.Query,
+		Pinned:    filter.Pinned,
+		CreatedAt: filter.CreatedAt,
+		UpdatedAt: filter.UpdatedAt,
+	}
+}
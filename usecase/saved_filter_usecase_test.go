@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// 保存済みフィルター
+type fakeSavedFilterRepository struct {
+	repository.ISavedFilterRepository
+	filters []model.SavedFilter
+}
+
+func (fr *fakeSavedFilterRepository) CreateSavedFilter(filter *model.SavedFilter) error {
+	filter.ID = uint(len(fr.filters) + 1)
+	fr.filters = append(fr.filters, *filter)
+	return nil
+}
+
+func TestCreateSavedFilterIgnoresNestedUser(t *testing.T) {
+	fr := &fakeSavedFilterRepository{}
+	fu := NewSavedFilterUsecase(fr, validator.NewSavedFilterValidator(), nil)
+
+	body := `{"id":5,"name":"work","query":"label:work","pinned":true,"user_id":2,
+		"user":{"email":"new@example.com","password":"secret","email_verified_at":"2026-01-01T00:00:00Z"}}`
+	filter := model.SavedFilter{}
+	if err := json.Unmarshal([]byte(body), &filter); err != nil {
+		t.Fatal(err)
+	}
+	filter.UserId = 1
+
+	if _, err := fu.CreateSavedFilter(filter); err != nil {
+		t.Fatal(err)
+	}
+	want := []model.SavedFilter{{ID: 1, Name: "work", Query: "label:work", Pinned: true, UserId: 1}}
+	if !reflect.DeepEqual(fr.filters, want) {
+		t.Errorf("saved filters = %+v, want %+v", fr.filters, want)
+	}
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+)
+
+// セッション（ログインした端末）に関するユースケースのインターフェース定義
+type ISessionUsecase interface {
+	GetSessions(userId uint, currentSessionId uint) ([]model.SessionResponse, error) // 有効なセッションの一覧を取得
+	RevokeSession(userId uint, sessionId uint) error                                 // セッションを失効させ、その端末をログアウトさせる
+}
+
+type sessionUsecase struct {
+	sr repository.ISessionRepository
+	rr repository.IRefreshTokenRepository
+}
+
+// コンストラクタ関数
+func NewSessionUsecase(sr repository.ISessionRepository, rr repository.IRefreshTokenRepository) ISessionUsecase {
+	return &sessionUsecase{sr, rr}
+}
+
+// 有効なセッションの一覧を、最後にアクセスした順に取得
+// currentSessionId はリクエストに使われたアクセストークンのセッション
+func (su *sessionUsecase) GetSessions(userId uint, currentSessionId uint) ([]model.SessionResponse, error) {
+	sessions := []model.Session{}
+	if err := su.sr.GetActiveSessions(&sessions, userId, time.Now()); err != nil {
+		return nil, err
+	}
+	resSessions := []model.SessionResponse{}
+	for _, v := range sessions {
+		s := model.SessionResponse{
+			ID:         v.ID,
+			UserAgent:  v.UserAgent,
+			IPAddress:  v.IPAddress,
+			CreatedAt:  v.CreatedAt,
+			LastSeenAt: v.LastSeenAt,
+			Current:    v.ID == currentSessionId,
+		}
+		resSessions = append(resSessions, s)
+	}
+	return resSessions, nil
+}
+
+// セッションを失効させる
+// アクセストークンは次のリクエストから拒否され、リフレッシュトークンも使えなくなる
+func (su *sessionUsecase) RevokeSession(userId uint, sessionId uint) error {
+	now := time.Now()
+	if err := su.sr.RevokeSession(userId, sessionId, now); err != nil {
+		return err
+	}
+	return su.rr.RevokeSessionRefreshTokens(sessionId, now)
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// メールのリンクなどに含める、署名付きのトークン
+// <内容と有効期限>.<署名> をそれぞれ base64url で表し、署名は SECRET による HMAC-SHA256
+// purpose（用途）も署名に含めるため、ほかの用途のトークンとしては使えない
+
+// 署名付きのトークンを作成
+func signToken(purpose string, payload string, expiresAt time.Time) string {
+	body := payload + "|" + strconv.FormatInt(expiresAt.Unix(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(body)) + "." +
+		base64.RawURLEncoding.EncodeToString(tokenSignature(purpose, body))
+}
+
+// 署名と有効期限を検証して、トークンの内容を取り出す
+func verifySignedToken(purpose string, token string, now time.Time) (string, bool) {
+	encodedBody, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", false
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encodedBody)
+	if err != nil {
+		return "", false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, tokenSignature(purpose, string(body))) {
+		return "", false
+	}
+	i := strings.LastIndex(string(body), "|")
+	if i < 0 {
+		return "", false
+	}
+	exp, err := strconv.ParseInt(string(body[i+1:]), 10, 64)
+	if err != nil || !time.Unix(exp, 0).After(now) {
+		return "", false
+	}
+	return string(body[:i]), true
+}
+
+// 用途と内容に対する署名
+func tokenSignature(purpose string, body string) []byte {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET")))
+	mac.Write([]byte(purpose + "|" + body))
+	return mac.Sum(nil)
+}
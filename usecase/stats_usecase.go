@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// 集計期間の上限（日数）
+const maxStatsRangeDays = 366
+
+// 統計情報のパラメータが不正な場合のエラー
+var ErrInvalidStatsParameter = newError(KindInvalid, "invalid_stats_parameter", "invalid stats parameter")
+
+// 統計情報に関するユースケースを定義
+type IStatsUsecase interface {
+	// 統計情報を取得する
+	// from, to は YYYY-MM-DD 形式（省略時は直近30日）、interval は day または week、
+	// tz は省略時にユーザーに設定されたタイムゾーンを使用する（IANAのタイムゾーン名でなければ検証エラー）
+	GetStats(userId uint, from, to, interval, tz string) (model.StatsResponse, error)
+}
+
+type statsUsecase struct {
+	sr repository.IStatsRepository // 統計情報のリポジトリ
+	ur repository.IUserRepository  // タイムゾーン取得のためのユーザーリポジトリ
+}
+
+// コンストラクタ関数
+func NewStatsUsecase(sr repository.IStatsRepository, ur repository.IUserRepository) IStatsUsecase {
+	return &statsUsecase{sr, ur}
+}
+
+// 統計情報を取得する
+func (su *statsUsecase) GetStats(userId uint, from, to, interval, tz string) (model.StatsResponse, error) {
+	// タイムゾーンを決定（指定がなければユーザーの設定を使用）
+	var loc *time.Location
+	var err error
+	if tz == "" {
+		if loc, err = userLocation(su.ur, userId); err != nil {
+			return model.StatsResponse{}, err
+		}
+	} else if !validator.IsTimezone(tz) {
+		return model.StatsResponse{}, validator.NewFieldError("tz", validator.CodeInvalidTimezone, nil)
+	} else if loc, err = time.LoadLocation(tz); err != nil {
+		return model.StatsResponse{}, err
+	}
+
+	// 集計期間を決定（指定がなければ今日までの30日間）
+	today := time.Now().In(loc)
+	toDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		if toDate, err = time.Parse("2006-01-02", to); err != nil {
+			return model.StatsResponse{}, fmt.Errorf("%w: invalid to date %q", ErrInvalidStatsParameter, to)
+		}
+	}
+	fromDate := toDate.AddDate(0, 0, -29)
+	if from != "" {
+		if fromDate, err = time.Parse("2006-01-02", from); err != nil {
+			return model.StatsResponse{}, fmt.Errorf("%w: invalid from date %q", ErrInvalidStatsParameter, from)
+		}
+	}
+	if fromDate.After(toDate) {
+		return model.StatsResponse{}, fmt.Errorf("%w: from must not be after to", ErrInvalidStatsParameter)
+	}
+	if toDate.Sub(fromDate) > maxStatsRangeDays*24*time.Hour {
+		return model.StatsResponse{}, fmt.Errorf("%w: range must not exceed %d days", ErrInvalidStatsParameter, maxStatsRangeDays)
+	}
+	if interval == "" {
+		interval = "day"
+	}
+	if interval != "day" && interval != "week" {
+		return model.StatsResponse{}, fmt.Errorf("%w: interval must be day or week", ErrInvalidStatsParameter)
+	}
+
+	res := model.StatsResponse{
+		Timezone: loc.String(),
+		From:     fromDate.Format("2006-01-02"),
+		To:       toDate.Format("2006-01-02"),
+		Interval: interval,
+	}
+
+	// ステータスごとの件数
+	res.CountsByStatus = []model.StatusCount{}
+	if err := su.sr.CountByStatus(&res.CountsByStatus, userId); err != nil {
+		return model.StatsResponse{}, err
+	}
+
+	// 期間ごとの作成数と完了数
+	res.Throughput = []model.ThroughputPoint{}
+	if err := su.sr.GetThroughput(&res.Throughput, userId, fromDate, toDate, interval, loc.String()); err != nil {
+		return model.StatsResponse{}, err
+	}
+
+	// 平均リードタイム
+	var leadTime sql.NullFloat64
+	if err := su.sr.GetAverageLeadTime(&leadTime, userId, fromDate, toDate, loc.String()); err != nil {
+		return model.StatsResponse{}, err
+	}
+	if leadTime.Valid {
+		res.AverageLeadTimeHours = &leadTime.Float64
+	}
+
+	// 連続完了日数（最終日が今日または昨日であれば継続中とみなす）
+	streaks := []model.Streak{}
+	if err := su.sr.GetStreaks(&streaks, userId, loc.String()); err != nil {
+		return model.StatsResponse{}, err
+	}
+	todayStr := today.Format("2006-01-02")
+	yesterdayStr := today.AddDate(0, 0, -1).Format("2006-01-02")
+	for i, s := range streaks {
+		if res.LongestStreak == nil || s.Length > res.LongestStreak.Length {
+			res.LongestStreak = &streaks[i]
+		}
+		if s.End == todayStr || s.End == yesterdayStr {
+			res.CurrentStreak = s.Length
+		}
+	}
+
+	// バーンダウン
+	res.Burndown = []model.BurndownPoint{}
+	if err := su.sr.GetBurndown(&res.Burndown, userId, fromDate, toDate, loc.String()); err != nil {
+		return model.StatsResponse{}, err
+	}
+	return res, nil
+}
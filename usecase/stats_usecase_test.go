@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// 統計情報（集計結果は空で、渡されたタイムゾーンを記録する）
+type fakeStatsRepository struct {
+	repository.IStatsRepository
+	tz []string
+}
+
+func (sr *fakeStatsRepository) CountByStatus(counts *[]model.StatusCount, userId uint) error {
+	return nil
+}
+
+func (sr *fakeStatsRepository) GetThroughput(points *[]model.ThroughputPoint, userId uint, from, to time.Time, interval, tz string) error {
+	sr.tz = append(sr.tz, tz)
+	return nil
+}
+
+func (sr *fakeStatsRepository) GetAverageLeadTime(hours *sql.NullFloat64, userId uint, from, to time.Time, tz string) error {
+	sr.tz = append(sr.tz, tz)
+	return nil
+}
+
+func (sr *fakeStatsRepository) GetStreaks(streaks *[]model.Streak, userId uint, tz string) error {
+	sr.tz = append(sr.tz, tz)
+	return nil
+}
+
+func (sr *fakeStatsRepository) GetBurndown(points *[]model.BurndownPoint, userId uint, from, to time.Time, tz string) error {
+	sr.tz = append(sr.tz, tz)
+	return nil
+}
+
+func TestGetStatsTimezone(t *testing.T) {
+	user := model.User{ID: 1, Email: "user@example.com", Timezone: "Asia/Tokyo"}
+
+	tests := []struct {
+		name string
+		tz   string
+		want string // DB に渡すタイムゾーン（空なら検証エラー）
+	}{
+		{"省略時はユーザーのタイムゾーン", "", "Asia/Tokyo"},
+		{"IANAのタイムゾーン名", "America/New_York", "America/New_York"},
+		{"UTC", "UTC", "UTC"},
+		{"サーバーのタイムゾーン", "Local", ""},
+		{"存在しないタイムゾーン", "Mars/Olympus", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := &fakeStatsRepository{}
+			su := NewStatsUsecase(sr, newFakeUserRepository(user))
+
+			res, err := su.GetStats(user.ID, "", "", "", tt.tz)
+			if tt.want == "" {
+				var verr *validator.ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("err = %v, want *validator.ValidationError", err)
+				}
+				if len(sr.tz) != 0 {
+					t.Errorf("repository was called with %v", sr.tz)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Timezone != tt.want {
+				t.Errorf("Timezone = %q, want %q", res.Timezone, tt.want)
+			}
+			for _, tz := range sr.tz {
+				if tz != tt.want {
+					t.Errorf("repository tz = %q, want %q", tz, tt.want)
+				}
+			}
+		})
+	}
+}
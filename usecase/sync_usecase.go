@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+)
+
+const (
+	syncPageSize    = 500 // GET /sync で1回に返す変更の最大件数
+	maxSyncChanges  = 500 // POST /sync で1回に送信できる変更の最大件数
+	syncTokenPrefix = "v1:"
+)
+
+// 同期トークンや送信された変更が不正な場合のエラー
+var ErrInvalidSyncRequest = newError(KindInvalid, "invalid_sync_request", "invalid sync request")
+
+// 差分同期に関するユースケースを定義
+type ISyncUsecase interface {
+	GetChanges(userId uint, since string) (model.SyncResponse, error)                  // since トークン以降の変更を取得（空の場合は最初から）
+	ApplyChanges(userId uint, req model.SyncRequest) (model.SyncUploadResponse, error) // オフラインでの変更をまとめて適用
+}
+
+type syncUsecase struct {
+	sr repository.ISyncRepository // 変更の取得
+	tu ITaskUsecase               // オフラインでの変更の適用（バリデーションや通知を含む）
+}
+
+// コンストラクタ関数
+func NewSyncUsecase(sr repository.ISyncRepository, tu ITaskUsecase) ISyncUsecase {
+	return &syncUsecase{sr, tu}
+}
+
+// since トークン以降の変更を取得
+// タスク・コメント・削除の記録を change_seq の順に並べ、最大 syncPageSize 件を返す
+func (su *syncUsecase) GetChanges(userId uint, since string) (model.SyncResponse, error) {
+	seq, err := decodeSyncToken(since)
+	if err != nil {
+		return model.SyncResponse{}, err
+	}
+
+	// 各種類とも1件多く取得し、続きがあるかどうかを判定する
+	tasks := []model.Task{}
+	if err := su.sr.GetChangedTasks(&tasks, userId, seq, syncPageSize+1); err != nil {
+		return model.SyncResponse{}, err
+	}
+	comments := []model.Comment{}
+	if err := su.sr.GetChangedComments(&comments, userId, seq, syncPageSize+1); err != nil {
+		return model.SyncResponse{}, err
+	}
+	tombstones := []model.Tombstone{}
+	if err := su.sr.GetTombstones(&tombstones, userId, seq, syncPageSize+1); err != nil {
+		return model.SyncResponse{}, err
+	}
+
+	// 3種類の変更を change_seq の順に並べる
+	type change struct {
+		seq   int64
+		apply func(res *model.SyncResponse)
+	}
+	changes := []change{}
+	for _, t := range tasks {
+		t := t
+		changes = append(changes, change{t.ChangeSeq, func(res *model.SyncResponse) {
+			res.Tasks = append(res.Tasks, toTaskResponse(t))
+		}})
+	}
+	for _, c := range comments {
+		c := c
+		changes = append(changes, change{c.ChangeSeq, func(res *model.SyncResponse) {
+			res.Comments = append(res.Comments, toCommentResponse(c))
+		}})
+	}
+	for _, d := range tombstones {
+		d := d
+		changes = append(changes, change{d.ChangeSeq, func(res *model.SyncResponse) {
+			res.Deleted = append(res.Deleted, model.SyncDeleted{Type: d.EntityType, ID: d.EntityId, DeletedAt: d.CreatedAt})
+		}})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].seq < changes[j].seq })
+
+	res := model.SyncResponse{
+		Tasks:    []model.TaskResponse{},
+		Comments: []model.CommentResponse{},
+		Deleted:  []model.SyncDeleted{},
+	}
+	if len(changes) > syncPageSize {
+		changes = changes[:syncPageSize]
+		res.HasMore = true
+	}
+	for _, c := range changes {
+		c.apply(&res)
+		seq = c.seq
+	}
+	res.Next = encodeSyncToken(seq)
+	return res, nil
+}
+
+// オフラインでの変更をまとめて適用
+// 変更は送信された順に1件ずつ適用し、結果を同じ順に返す（1件が失敗してもほかの変更は適用される）
+func (su *syncUsecase) ApplyChanges(userId uint, req model.SyncRequest) (model.SyncUploadResponse, error) {
+	if len(req.Changes) > maxSyncChanges {
+		return model.SyncUploadResponse{}, ErrInvalidSyncRequest
+	}
+	res := model.SyncUploadResponse{Results: []model.SyncResult{}}
+	for _, c := range req.Changes {
+		res.Results = append(res.Results, su.applyChange(userId, c))
+	}
+	return res, nil
+}
+
+// 1件の変更を適用
+func (su *syncUsecase) applyChange(userId uint, c model.SyncChange) model.SyncResult {
+	result := model.SyncResult{ClientId: c.ClientId}
+	switch c.Op {
+	case model.SyncOpCreate:
+		task := c.Task.Task
+		task.ID = 0
+		task.UserId = userId
+		taskRes, err := su.tu.CreateTask(task)
+		if err != nil {
+			return su.failed(result, userId, 0, err)
+		}
+		result.Status, result.Task = model.SyncStatusApplied, &taskRes
+
+	case model.SyncOpUpdate:
+		var (
+			taskRes model.TaskResponse
+			err     error
+		)
+		if c.BaseVersion != nil {
+			taskRes, err = su.tu.UpdateTaskIfUnmodified(c.Task, userId, c.ID, *c.BaseVersion)
+		} else {
+			taskRes, err = su.tu.UpdateTask(c.Task, userId, c.ID)
+		}
+		if err != nil {
+			return su.failed(result, userId, c.ID, err)
+		}
+		result.Status, result.Task = model.SyncStatusApplied, &taskRes
+
+	case model.SyncOpDelete:
+		// 削除は競合を確認せずに適用する
+		if err := su.tu.DeleteTask(userId, c.ID); err != nil {
+			return su.failed(result, userId, c.ID, err)
+		}
+		result.Status = model.SyncStatusApplied
+
+	default:
+		result.Status, result.Error = model.SyncStatusError, "unknown op: "+c.Op
+	}
+	return result
+}
+
+// 適用に失敗した変更の結果を作成
+// 競合した場合はサーバー側のタスクを、対象が閲覧できない場合は not_found を返す
+func (su *syncUsecase) failed(result model.SyncResult, userId uint, taskId uint, err error) model.SyncResult {
+	if errors.Is(err, ErrTaskConflict) {
+		result.Status, result.Error = model.SyncStatusConflict, err.Error()
+		if current, err := su.tu.GetTaskById(userId, taskId); err == nil {
+			result.Task = &current
+		}
+		return result
+	}
+	if taskId != 0 {
+		if _, getErr := su.tu.GetTaskById(userId, taskId); getErr != nil {
+			result.Status = model.SyncStatusNotFound
+			return result
+		}
+	}
+	result.Status, result.Error = model.SyncStatusError, err.Error()
+	return result
+}
+
+// 同期トークンを作成（クライアントからは中身の分からない文字列として扱う）
+func encodeSyncToken(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(syncTokenPrefix + strconv.FormatInt(seq, 10)))
+}
+
+// 同期トークンを解析（空の場合は最初から）
+func decodeSyncToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(b), syncTokenPrefix) {
+		return 0, ErrInvalidSyncRequest
+	}
+	seq, err := strconv.ParseInt(strings.TrimPrefix(string(b), syncTokenPrefix), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, ErrInvalidSyncRequest
+	}
+	return seq, nil
+}
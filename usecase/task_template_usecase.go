@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// テンプレート内のプレースホルダ（例: {{name}}）
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// テンプレートの展開に必要な変数が指定されていない場合のエラー
+var ErrMissingTemplateVariable = newError(KindInvalid, "missing_template_variable", "missing template variable")
+
+// タスクテンプレートに関するユースケースを定義
+type ITaskTemplateUsecase interface {
+	GetAllTaskTemplates(userId uint) ([]model.TaskTemplateResponse, error)                                            // テンプレート一覧を取得
+	GetTaskTemplateById(userId uint, templateId uint) (model.TaskTemplateResponse, error)                             // 特定のテンプレートを取得
+	CreateTaskTemplate(template model.TaskTemplate) (model.TaskTemplateResponse, error)                               // テンプレートを作成
+	UpdateTaskTemplate(template model.TaskTemplate, userId uint, templateId uint) (model.TaskTemplateResponse, error) // テンプレートを更新
+	DeleteTaskTemplate(userId uint, templateId uint) error                                                            // テンプレートを削除
+	Instantiate(userId uint, templateId uint, req model.TaskTemplateInstantiateRequest) ([]model.TaskResponse, error) // テンプレートからタスクを一括作成
+}
+
+type taskTemplateUsecase struct {
+	ttr repository.ITaskTemplateRepository // テンプレートのリポジトリ
+	ttv validator.ITaskTemplateValidator   // テンプレートのバリデーション
+	tr  repository.ITaskRepository         // タスク作成に使用するリポジトリ
+	tv  validator.ITaskValidator           // 作成するタスクのバリデーション
+}
+
+// コンストラクタ関数
+func NewTaskTemplateUsecase(ttr repository.ITaskTemplateRepository, ttv validator.ITaskTemplateValidator,
+	tr repository.ITaskRepository, tv validator.ITaskValidator) ITaskTemplateUsecase {
+	return &taskTemplateUsecase{ttr, ttv, tr, tv}
+}
+
+// テンプレート一覧を取得
+func (ttu *taskTemplateUsecase) GetAllTaskTemplates(userId uint) ([]model.TaskTemplateResponse, error) {
+	templates := []model.TaskTemplate{}
+	if err := ttu.ttr.GetAllTaskTemplates(&templates, userId); err != nil {
+		return nil, err
+	}
+	resTemplates := []model.TaskTemplateResponse{}
+	for _, v := range templates {
+		resTemplates = append(resTemplates, toTaskTemplateResponse(v))
+	}
+	return resTemplates, nil
+}
+
+// 特定のテンプレートを取得
+func (ttu *taskTemplateUsecase) GetTaskTemplateById(userId uint, templateId uint) (model.TaskTemplateResponse, error) {
+	template := model.TaskTemplate{}
+	if err := ttu.ttr.GetTaskTemplateById(&template, userId, templateId); err != nil {
+		return model.TaskTemplateResponse{}, err
+	}
+	return toTaskTemplateResponse(template), nil
+}
+
+// テンプレートを作成
+func (ttu *taskTemplateUsecase) CreateTaskTemplate(template model.TaskTemplate) (model.TaskTemplateResponse, error) {
+	if err := ttu.ttv.TaskTemplateValidate(template); err != nil {
+		return model.TaskTemplateResponse{}, err
+	}
+	// リクエストで指定できる項目のみを保存する（関連するユーザーが作成されないようにする）
+	newTemplate := model.TaskTemplate{
+		Name:        template.Name,
+		Description: template.Description,
+		Items:       template.Items,
+		UserId:      template.UserId,
+	}
+	if err := ttu.ttr.CreateTaskTemplate(&newTemplate); err != nil {
+		return model.TaskTemplateResponse{}, err
+	}
+	return toTaskTemplateResponse(newTemplate), nil
+}
+
+// テンプレートを更新
+func (ttu *taskTemplateUsecase) UpdateTaskTemplate(template model.TaskTemplate, userId uint, templateId uint) (model.TaskTemplateResponse, error) {
+	if err := ttu.ttv.TaskTemplateValidate(template); err != nil {
+		return model.TaskTemplateResponse{}, err
+	}
+	if err := ttu.ttr.UpdateTaskTemplate(&template, userId, templateId); err != nil {
+		return model.TaskTemplateResponse{}, err
+	}
+	return toTaskTemplateResponse(template), nil
+}
+
+// テンプレートを削除
+func (ttu *taskTemplateUsecase) DeleteTaskTemplate(userId uint, templateId uint) error {
+	if err := ttu.ttr.DeleteTaskTemplate(userId, templateId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// テンプレートからタスクを一括作成
+// 変数を埋め込んだうえで、親子関係を保ったまま1つのトランザクションで作成する
+func (ttu *taskTemplateUsecase) Instantiate(userId uint, templateId uint, req model.TaskTemplateInstantiateRequest) ([]model.TaskResponse, error) {
+	template := model.TaskTemplate{}
+	if err := ttu.ttr.GetTaskTemplateById(&template, userId, templateId); err != nil {
+		return nil, err
+	}
+
+	// テンプレートで使われている変数がすべて指定されているか確認
+	missing := []string{}
+	for _, name := range templateVariables(template.Items) {
+		if _, ok := req.Variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingTemplateVariable, strings.Join(missing, ", "))
+	}
+
+	baseDate := time.Now()
+	if req.BaseDate != nil {
+		baseDate = *req.BaseDate
+	}
+
+	resTasks := []model.TaskResponse{}
+	err := ttu.tr.Transaction(func(tr repository.ITaskRepository) error {
+		var create func(items []model.TaskBlueprint, parentId *uint) error
+		create = func(items []model.TaskBlueprint, parentId *uint) error {
+			for _, item := range items {
+				task := model.Task{
+					Title:        substituteVariables(item.Title, req.Variables),
+					Status:       model.TaskStatusOpen,
+					Labels:       model.Labels{},
+					CustomFields: model.CustomFieldValues{},
+					ParentId:     parentId,
+					UserId:       userId,
+				}
+				for _, label := range item.Labels {
+					task.Labels = append(task.Labels, substituteVariables(label, req.Variables))
+				}
+				if item.DueOffsetDays != nil {
+					dueAt := baseDate.AddDate(0, 0, *item.DueOffsetDays)
+					task.DueAt = &dueAt
+				}
+				// 通常のタスク作成と同じバリデーションを適用
+				if err := ttu.tv.TaskValidate(task); err != nil {
+					return err
+				}
+				if err := tr.CreateTask(&task); err != nil {
+					return err
+				}
+				resTasks = append(resTasks, toTaskResponse(task))
+				if err := create(item.Children, &task.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return create(template.Items, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resTasks, nil
+}
+
+// プレースホルダを変数の値で置き換える
+func substituteVariables(s string, variables map[string]string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := templatePlaceholder.FindStringSubmatch(m)[1]
+		if v, ok := variables[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// テンプレート内で使われている変数名を重複なく取得（名前順）
+func templateVariables(items []model.TaskBlueprint) []string {
+	seen := map[string]bool{}
+	var walk func(items []model.TaskBlueprint)
+	walk = func(items []model.TaskBlueprint) {
+		for _, item := range items {
+			texts := append([]string{item.Title}, item.Labels...)
+			for _, text := range texts {
+				for _, m := range templatePlaceholder.FindAllStringSubmatch(text, -1) {
+					seen[m[1]] = true
+				}
+			}
+			walk(item.Children)
+		}
+	}
+	walk(items)
+	names := []string{}
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// テンプレートをレスポンス形式に変換
+func toTaskTemplateResponse(template model.TaskTemplate) model.TaskTemplateResponse {
+	return model.TaskTemplateResponse{
+		ID:          template.ID,
+		Name:        template.Name,
+		Description: template.Description,
+		Items:       template.Items,
+		Variables:   templateVariables(template.Items),
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+}
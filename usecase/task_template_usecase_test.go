@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// タスクテンプレート
+type fakeTaskTemplateRepository struct {
+	repository.ITaskTemplateRepository
+	templates []model.TaskTemplate
+}
+
+func (ttr *fakeTaskTemplateRepository) CreateTaskTemplate(template *model.TaskTemplate) error {
+	template.ID = uint(len(ttr.templates) + 1)
+	ttr.templates = append(ttr.templates, *template)
+	return nil
+}
+
+func TestCreateTaskTemplateIgnoresNestedUser(t *testing.T) {
+	ttr := &fakeTaskTemplateRepository{}
+	ttu := NewTaskTemplateUsecase(ttr, validator.NewTaskTemplateValidator(), nil, nil)
+
+	body := `{"id":5,"name":"release","description":"手順","items":[{"title":"tag {{version}}"}],"user_id":2,
+		"user":{"email":"new@example.com","password":"secret","email_verified_at":"2026-01-01T00:00:00Z"}}`
+	template := model.TaskTemplate{}
+	if err := json.Unmarshal([]byte(body), &template); err != nil {
+		t.Fatal(err)
+	}
+	template.UserId = 1
+
+	if _, err := ttu.CreateTaskTemplate(template); err != nil {
+		t.Fatal(err)
+	}
+	want := []model.TaskTemplate{{
+		ID:          1,
+		Name:        "release",
+		Description: "手順",
+		Items:       model.TaskBlueprints{{Title: "tag {{version}}"}},
+		UserId:      1,
+	}}
+	if !reflect.DeepEqual(ttr.templates, want) {
+		t.Errorf("saved templates = %+v, want %+v", ttr.templates, want)
+	}
+}
@@ -1,32 +1,62 @@
 package usecase
 
 import (
+	"errors"
+	"time"
+
 	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/query"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/quickadd"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
 	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
 )
 
+// 自分自身にタスクを割り当てようとした場合のエラー
+var ErrInvalidAssignee = newError(KindInvalid, "invalid_assignee", "cannot assign a task to its owner")
+
+// 取得したときからタスクが変更されていた場合のエラー（オフラインでの更新の競合）
+var ErrTaskConflict = newError(KindConflict, "task_conflict", "task was modified by someone else")
+
+// スヌーズの期限が現在より前の場合のエラー
+var ErrInvalidSnooze = newError(KindInvalid, "invalid_snooze", "snooze time must be in the future")
+
 // タスクに関連するユースケース（ビジネスロジック）を定義
 type ITaskUsecase interface {
-	GetAllTasks(userId uint) ([]model.TaskResponse, error)                            //ユーザーIDに基づいて全タスクを取得
-	GetTaskById(userId uint, taskId uint) (model.TaskResponse, error)                 //特定のタスクIDに基づいてタスクを取得
-	CreateTask(task model.Task) (model.TaskResponse, error)                           //新しいタスクを作成
-	UpdateTask(task model.Task, UserId uint, taskId uint) (model.TaskResponse, error) //既存のタスクを更新
-	DeleteTask(userId uint, taskId uint) error                                        //タスクを削除
+	GetAllTasks(userId uint) ([]model.TaskResponse, error)                                                               //ユーザーIDに基づいて全タスクを取得
+	SearchTasks(userId uint, q string) ([]model.TaskResponse, error)                                                     //検索クエリに一致するタスクを取得
+	GetAssignedTasks(userId uint, q string) ([]model.TaskResponse, error)                                                //自分が担当者になっているタスクを取得（q は省略可）
+	GetTaskById(userId uint, taskId uint) (model.TaskResponse, error)                                                    //特定のタスクIDに基づいてタスクを取得
+	CreateTask(task model.Task) (model.TaskResponse, error)                                                              //新しいタスクを作成
+	QuickAddTask(userId uint, req model.QuickAddRequest) (model.QuickAddResponse, error)                                 //1行のテキストを解析してタスクを作成
+	UpdateTask(update model.TaskUpdate, UserId uint, taskId uint) (model.TaskResponse, error)                            //既存のタスクの送信された項目を更新
+	UpdateTaskIfUnmodified(update model.TaskUpdate, userId uint, taskId uint, version int64) (model.TaskResponse, error) //version が一致する場合のみタスクを更新
+	DeleteTask(userId uint, taskId uint) error                                                                           //タスクを削除
+	AssignTask(userId uint, taskId uint, email string) (model.TaskResponse, error)                                       //タスクを他のユーザーに割り当てる
+	UnassignTask(userId uint, taskId uint) (model.TaskResponse, error)                                                   //割り当てを解除する
+	RespondToAssignment(userId uint, taskId uint, accept bool) (model.TaskResponse, error)                               //割り当てを承諾または辞退する
+	SnoozeTask(userId uint, taskId uint, until time.Time) (model.TaskResponse, error)                                    //タスクをスヌーズする
+	UnsnoozeTask(userId uint, taskId uint) (model.TaskResponse, error)                                                   //スヌーズを解除する
+	GetToday(userId uint) (model.TodayResponse, error)                                                                   //「My Day」と今日が期限・期限切れのタスクを取得
+	AddToMyDay(userId uint, taskId uint) error                                                                           //今日の「My Day」にタスクを追加
+	RemoveFromMyDay(userId uint, taskId uint) error                                                                      //今日の「My Day」からタスクを外す
 }
 
 // taskUsecase 構造体は ITaskUsecase インターフェースを実装
 type taskUsecase struct {
-	tr repository.ITaskRepository //タスクに関するリポジトリ
-	tv validator.ITaskValidator //タスクに関するバリデーション
+	tr repository.ITaskRepository    //タスクに関するリポジトリ
+	tv validator.ITaskValidator      //タスクに関するバリデーション
+	ur repository.IUserRepository    //タイムゾーン取得のためのユーザーリポジトリ
+	pr repository.IProjectRepository //カスタムフィールドの定義を取得するプロジェクトのリポジトリ
+	nu INotificationUsecase          //割り当てや変更の通知
 }
 
-//コンストラクタ関数
-func NewTaskUsecase(tr repository.ITaskRepository, tv validator.ITaskValidator) ITaskUsecase {
-	return &taskUsecase{tr, tv}
+// コンストラクタ関数
+func NewTaskUsecase(tr repository.ITaskRepository, tv validator.ITaskValidator, ur repository.IUserRepository,
+	pr repository.IProjectRepository, nu INotificationUsecase) ITaskUsecase {
+	return &taskUsecase{tr, tv, ur, pr, nu}
 }
 
-//ユーザーIDに基づいてすべてのタスクを取得
+// ユーザーIDに基づいてすべてのタスクを取得
 func (tu taskUsecase) GetAllTasks(userId uint) ([]model.TaskResponse, error) {
 	tasks := []model.Task{}
 	// リポジトリからタスクを取得
@@ -37,18 +67,65 @@ func (tu taskUsecase) GetAllTasks(userId uint) ([]model.TaskResponse, error) {
 	// タスクをレスポンス形式に変換
 	resTasks := []model.TaskResponse{}
 	for _, v := range tasks {
-		t := model.TaskResponse{
-			ID:        v.ID,
-			Title:     v.Title,
-			CreatedAt: v.CreatedAt,
-			UpdatedAt: v.UpdatedAt,
-		}
-		resTasks = append(resTasks, t)
+		resTasks = append(resTasks, toTaskResponse(v))
 	}
 	return resTasks, nil
 }
 
-//特定のタスクをIDで取得
+// 検索クエリに一致するタスクを取得
+func (tu taskUsecase) SearchTasks(userId uint, q string) ([]model.TaskResponse, error) {
+	// クエリ文字列を解析（エラーには位置情報が含まれる）
+	parsed, err := query.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+
+	// 「今日」などの日付はユーザーのタイムゾーンで判定
+	loc, err := userLocation(tu.ur, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []model.Task{}
+	// リポジトリから条件に一致するタスクを取得
+	if err := tu.tr.SearchTasks(&tasks, userId, parsed, time.Now().In(loc)); err != nil {
+		return nil, err
+	}
+
+	// タスクをレスポンス形式に変換
+	resTasks := []model.TaskResponse{}
+	for _, v := range tasks {
+		resTasks = append(resTasks, toTaskResponse(v))
+	}
+	return resTasks, nil
+}
+
+// 自分が担当者になっているタスクを取得
+func (tu taskUsecase) GetAssignedTasks(userId uint, q string) ([]model.TaskResponse, error) {
+	// 検索クエリを解析（空の場合は条件なし）
+	parsed, err := query.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := userLocation(tu.ur, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []model.Task{}
+	if err := tu.tr.GetAssignedTasks(&tasks, userId, parsed, time.Now().In(loc)); err != nil {
+		return nil, err
+	}
+
+	// タスクをレスポンス形式に変換
+	resTasks := []model.TaskResponse{}
+	for _, v := range tasks {
+		resTasks = append(resTasks, toTaskResponse(v))
+	}
+	return resTasks, nil
+}
+
+// 特定のタスクをIDで取得
 func (tu taskUsecase) GetTaskById(userId uint, taskId uint) (model.TaskResponse, error) {
 	task := model.Task{}
 	// リポジトリからタスクを取得
@@ -57,64 +134,438 @@ func (tu taskUsecase) GetTaskById(userId uint, taskId uint) (model.TaskResponse,
 	}
 
 	// タスクをレスポンス形式に変換
-	resTask := model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
-	}
+	resTask := toTaskResponse(task)
 	return resTask, nil
 }
 
-//新しいタスクを作成
+// 新しいタスクを作成
 func (tu taskUsecase) CreateTask(task model.Task) (model.TaskResponse, error) {
+	clearServerManagedFields(&task)
+	setTaskDefaults(&task)
 	// タスクのバリデーション
 	if err := tu.tv.TaskValidate(task); err != nil {
 		return model.TaskResponse{}, err
 	}
+	// サブタスクの場合は親タスクが自分のものであることを確認
+	// 担当者は親タスクを閲覧できても、サブタスクは追加できない（閲覧もできない場合は存在しない親タスクとして扱う）
+	if task.ParentId != nil {
+		parent := model.Task{}
+		if err := tu.tr.GetTaskById(&parent, task.UserId, *task.ParentId); err != nil {
+			return model.TaskResponse{}, err
+		}
+		if parent.UserId != task.UserId {
+			return model.TaskResponse{}, ErrForbidden
+		}
+	}
+	// カスタムフィールドの値を検証
+	if err := tu.validateCustomFields(task, task.UserId); err != nil {
+		return model.TaskResponse{}, err
+	}
 
 	// リポジトリでタスクを作成
 	if err := tu.tr.CreateTask(&task); err != nil {
 		return model.TaskResponse{}, err
 	}
+	// タイトル内のメンションを通知
+	tu.nu.NotifyMentions(task.UserId, task, task.Title, "")
 
 	// 作成されたタスクをレスポンス形式に変換
-	resTask := model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
-	}
+	resTask := toTaskResponse(task)
 	return resTask, nil
 }
 
-//既存のタスクを更新
-func (tu taskUsecase) UpdateTask(task model.Task, userId uint, taskId uint) (model.TaskResponse, error) {
+// 1行のテキストを解析してタスクを作成
+// dry_run の場合は解析結果のみを返す
+func (tu taskUsecase) QuickAddTask(userId uint, req model.QuickAddRequest) (model.QuickAddResponse, error) {
+	// 「明日」などの日付はユーザーのタイムゾーンで解釈
+	loc, err := userLocation(tu.ur, userId)
+	if err != nil {
+		return model.QuickAddResponse{}, err
+	}
+	parsed := quickadd.Parse(req.Text, time.Now().In(loc))
+	res := model.QuickAddResponse{Parsed: parsed}
+	if req.DryRun {
+		return res, nil
+	}
+
+	// 解析結果から通常のタスク作成処理を呼び出す
+	task := model.Task{
+		Title:      parsed.Title,
+		DueAt:      parsed.DueAt,
+		Labels:     parsed.Labels,
+		Priority:   parsed.Priority,
+		Recurrence: parsed.Recurrence,
+		UserId:     userId,
+	}
+	taskRes, err := tu.CreateTask(task)
+	if err != nil {
+		return model.QuickAddResponse{}, err
+	}
+	res.Task = &taskRes
+	return res, nil
+}
+
+// 既存のタスクを更新（送信された項目のみ）
+func (tu taskUsecase) UpdateTask(update model.TaskUpdate, userId uint, taskId uint) (model.TaskResponse, error) {
+	return tu.updateTask(update, userId, taskId, nil)
+}
+
+// 取得したときから変更されていない（version が一致する）場合のみタスクを更新
+// 変更されていた場合は ErrTaskConflict を返す
+func (tu taskUsecase) UpdateTaskIfUnmodified(update model.TaskUpdate, userId uint, taskId uint, version int64) (model.TaskResponse, error) {
+	return tu.updateTask(update, userId, taskId, &version)
+}
+
+// タスクを更新（version が指定された場合は一致する場合のみ）
+func (tu taskUsecase) updateTask(update model.TaskUpdate, userId uint, taskId uint, version *int64) (model.TaskResponse, error) {
+	// 変更前のタスクを取得（省略された項目の補完と、新たに追加されたメンションの判定に使用）
+	before := model.Task{}
+	if err := tu.tr.GetTaskById(&before, userId, taskId); err != nil {
+		return model.TaskResponse{}, err
+	}
+	// 送信された項目を変更前のタスクに反映し、更新後のタスク全体を検証する
+	task := applyTaskUpdate(before, update)
+	setTaskDefaults(&task)
 	// タスクのバリデーション
 	if err := tu.tv.TaskValidate(task); err != nil {
 		return model.TaskResponse{}, err
 	}
+	// カスタムフィールドの値を検証（プロジェクトはタスクの作成者のもの）
+	// プロジェクトとカスタムフィールドのどちらも変更しない場合は、保存済みの値をそのまま残す
+	if update.Has("project_id") || update.Has("custom_fields") {
+		if err := tu.validateCustomFields(task, before.UserId); err != nil {
+			return model.TaskResponse{}, err
+		}
+	}
 
 	// リポジトリでタスクを更新
-	if err := tu.tr.UpdateTask(&task, userId, taskId); err != nil {
-		return model.TaskResponse{}, err
+	if version == nil {
+		if err := tu.tr.UpdateTask(&task, update.Fields, userId, taskId); err != nil {
+			return model.TaskResponse{}, tu.forbiddenIfReadable(err, userId, taskId)
+		}
+	} else {
+		if before.ChangeSeq != *version {
+			return model.TaskResponse{}, ErrTaskConflict
+		}
+		if err := tu.tr.UpdateTaskIfUnmodified(&task, update.Fields, userId, taskId, *version); err != nil {
+			// 取得してから更新するまでの間に変更された場合も競合とする
+			current := model.Task{}
+			if tu.tr.GetTaskById(&current, userId, taskId) == nil && current.ChangeSeq != *version {
+				return model.TaskResponse{}, ErrTaskConflict
+			}
+			return model.TaskResponse{}, tu.forbiddenIfReadable(err, userId, taskId)
+		}
 	}
+	// 新しいメンションと、作成者・担当者への変更を通知
+	tu.nu.NotifyMentions(userId, task, task.Title, before.Title)
+	tu.nu.NotifyFollowers(userId, task, model.NotificationTaskUpdated)
 
 	// 更新されたタスクをレスポンス形式に変換
-	resTask := model.TaskResponse{
-		ID:        task.ID,
-		Title:     task.Title,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
-	}
+	resTask := toTaskResponse(task)
 	return resTask, nil
 }
 
-//タスクを削除
+// タスクを削除
 func (tu taskUsecase) DeleteTask(userId uint, taskId uint) error {
 	// リポジトリでタスクを削除
 	if err := tu.tr.DeleteTask(userId, taskId); err != nil {
+		return tu.forbiddenIfReadable(err, userId, taskId)
+	}
+	return nil
+}
+
+// タスクを他のユーザーに割り当てる（作成者のみ）
+func (tu taskUsecase) AssignTask(userId uint, taskId uint, email string) (model.TaskResponse, error) {
+	// メールアドレスから担当者を取得
+	assignee := model.User{}
+	if err := tu.ur.GetUserByEmail(&assignee, email); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.TaskResponse{}, ErrUnknownAssignee
+		}
+		return model.TaskResponse{}, err
+	}
+	if assignee.ID == userId {
+		return model.TaskResponse{}, ErrInvalidAssignee
+	}
+
+	task := model.Task{}
+	if err := tu.tr.AssignTask(&task, userId, taskId, assignee.ID); err != nil {
+		return model.TaskResponse{}, tu.forbiddenIfReadable(err, userId, taskId)
+	}
+	// 担当者に割り当てを通知
+	tu.nu.NotifyAssigned(userId, task)
+	return toTaskResponse(task), nil
+}
+
+// 割り当てを解除する（作成者のみ）
+func (tu taskUsecase) UnassignTask(userId uint, taskId uint) (model.TaskResponse, error) {
+	task := model.Task{}
+	if err := tu.tr.UnassignTask(&task, userId, taskId); err != nil {
+		return model.TaskResponse{}, tu.forbiddenIfReadable(err, userId, taskId)
+	}
+	return toTaskResponse(task), nil
+}
+
+// 割り当てを承諾または辞退する（担当者のみ）
+func (tu taskUsecase) RespondToAssignment(userId uint, taskId uint, accept bool) (model.TaskResponse, error) {
+	status := model.AssignmentDeclined
+	if accept {
+		status = model.AssignmentAccepted
+	}
+	task := model.Task{}
+	if err := tu.tr.RespondToAssignment(&task, userId, taskId, status); err != nil {
+		return model.TaskResponse{}, tu.forbiddenIfReadable(err, userId, taskId)
+	}
+	// 作成者に承諾・辞退を通知
+	tu.nu.NotifyFollowers(userId, task, model.NotificationTaskUpdated)
+	return toTaskResponse(task), nil
+}
+
+// タスクをスヌーズする（指定した日時まで一覧に表示しない）
+func (tu taskUsecase) SnoozeTask(userId uint, taskId uint, until time.Time) (model.TaskResponse, error) {
+	if !until.After(time.Now()) {
+		return model.TaskResponse{}, ErrInvalidSnooze
+	}
+	task := model.Task{}
+	if err := tu.tr.SnoozeTask(&task, userId, taskId, &until); err != nil {
+		return model.TaskResponse{}, tu.forbiddenIfReadable(err, userId, taskId)
+	}
+	return toTaskResponse(task), nil
+}
+
+// スヌーズを解除する
+func (tu taskUsecase) UnsnoozeTask(userId uint, taskId uint) (model.TaskResponse, error) {
+	task := model.Task{}
+	if err := tu.tr.SnoozeTask(&task, userId, taskId, nil); err != nil {
+		return model.TaskResponse{}, tu.forbiddenIfReadable(err, userId, taskId)
+	}
+	return toTaskResponse(task), nil
+}
+
+// 「My Day」に選んだタスクと、今日が期限・期限切れのタスクを取得
+// 「今日」はユーザーのタイムゾーンで判定するため、現地時間の0時に選択がリセットされる
+func (tu taskUsecase) GetToday(userId uint) (model.TodayResponse, error) {
+	loc, err := userLocation(tu.ur, userId)
+	if err != nil {
+		return model.TodayResponse{}, err
+	}
+	now := time.Now().In(loc)
+
+	selected := []model.Task{}
+	if err := tu.tr.GetMyDayTasks(&selected, userId, now); err != nil {
+		return model.TodayResponse{}, err
+	}
+	dueToday, err := ownAndAcceptedTasks(tu.tr, userId, "status:open due:today", now)
+	if err != nil {
+		return model.TodayResponse{}, err
+	}
+	overdue, err := ownAndAcceptedTasks(tu.tr, userId, "status:open due:overdue", now)
+	if err != nil {
+		return model.TodayResponse{}, err
+	}
+
+	// 選択済みのタスクは期限の一覧に重複して表示しない
+	picked := map[uint]bool{}
+	res := model.TodayResponse{
+		Date:     now.Format("2006-01-02"),
+		Selected: []model.TaskResponse{},
+		DueToday: []model.TaskResponse{},
+		Overdue:  []model.TaskResponse{},
+	}
+	for _, v := range selected {
+		picked[v.ID] = true
+		res.Selected = append(res.Selected, toTaskResponse(v))
+	}
+	for _, v := range dueToday {
+		if !picked[v.ID] {
+			res.DueToday = append(res.DueToday, toTaskResponse(v))
+		}
+	}
+	for _, v := range overdue {
+		if !picked[v.ID] {
+			res.Overdue = append(res.Overdue, toTaskResponse(v))
+		}
+	}
+	return res, nil
+}
+
+// 今日の「My Day」にタスクを追加（閲覧できるタスクのみ）
+func (tu taskUsecase) AddToMyDay(userId uint, taskId uint) error {
+	task := model.Task{}
+	if err := tu.tr.GetTaskById(&task, userId, taskId); err != nil {
 		return err
 	}
+	loc, err := userLocation(tu.ur, userId)
+	if err != nil {
+		return err
+	}
+	return tu.tr.AddToMyDay(userId, taskId, time.Now().In(loc))
+}
+
+// 今日の「My Day」からタスクを外す
+func (tu taskUsecase) RemoveFromMyDay(userId uint, taskId uint) error {
+	loc, err := userLocation(tu.ur, userId)
+	if err != nil {
+		return err
+	}
+	return tu.tr.RemoveFromMyDay(userId, taskId, time.Now().In(loc))
+}
+
+// 自分のタスクと、承諾済みの担当しているタスクから検索クエリに一致するものを取得
+func ownAndAcceptedTasks(tr repository.ITaskRepository, userId uint, q string, now time.Time) ([]model.Task, error) {
+	parsed, err := query.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+	owned := []model.Task{}
+	if err := tr.SearchTasks(&owned, userId, parsed, now); err != nil {
+		return nil, err
+	}
+	assigned := []model.Task{}
+	if err := tr.GetAssignedTasks(&assigned, userId, parsed, now); err != nil {
+		return nil, err
+	}
+	for _, t := range assigned {
+		if t.AssignmentStatus == model.AssignmentAccepted {
+			owned = append(owned, t)
+		}
+	}
+	return owned, nil
+}
+
+// 送信された項目のみを変更前のタスクに反映する
+func applyTaskUpdate(task model.Task, update model.TaskUpdate) model.Task {
+	for _, field := range update.Fields {
+		switch field {
+		case "title":
+			task.Title = update.Title
+		case "status":
+			task.Status = update.Status
+		case "due_at":
+			task.DueAt = update.DueAt
+		case "labels":
+			task.Labels = update.Labels
+		case "priority":
+			task.Priority = update.Priority
+		case "recurrence":
+			task.Recurrence = update.Recurrence
+		case "project_id":
+			task.ProjectId = update.ProjectId
+		case "custom_fields":
+			task.CustomFields = update.CustomFields
+		}
+	}
+	return task
+}
+
+// 作成時にクライアントから指定できない項目をクリアする
+func clearServerManagedFields(task *model.Task) {
+	task.ID = 0
+	// 関連するユーザーやタスクが作成されないよう、関連は常に空にする
+	task.User = model.User{}
+	task.Parent, task.Assignee, task.Project = nil, nil, nil
+	// 担当者は POST /tasks/:taskId/assign でのみ設定する（承諾・辞退と通知を経ずに他のユーザーの一覧に追加させない）
+	task.AssigneeId = nil
+	task.AssignmentStatus = ""
+	// スヌーズは POST /tasks/:taskId/snooze でのみ設定する
+	task.SnoozedUntil = nil
+	// 完了日時は更新時と同様にステータスから決める
+	task.CompletedAt = nil
+	if task.Status == model.TaskStatusDone {
+		now := time.Now()
+		task.CompletedAt = &now
+	}
+}
+
+// 省略された項目に既定値を設定
+func setTaskDefaults(task *model.Task) {
+	if task.Status == "" {
+		task.Status = model.TaskStatusOpen
+	}
+	if task.Labels == nil {
+		task.Labels = model.Labels{}
+	}
+	if task.CustomFields == nil {
+		task.CustomFields = model.CustomFieldValues{}
+	}
+	// null を指定したカスタムフィールドは値なしとして扱う
+	for key, value := range task.CustomFields {
+		if value == nil {
+			delete(task.CustomFields, key)
+		}
+	}
+	if task.Priority == "" {
+		task.Priority = model.TaskPriorityNone
+	}
+}
+
+// 操作できなかったタスクが閲覧はできる場合（担当者が作成者のみの操作をした場合など）は ErrForbidden に置き換える
+// 閲覧もできない場合は、タスクの存在を明かさないよう元のエラー（存在しない）のまま返す
+func (tu taskUsecase) forbiddenIfReadable(err error, userId uint, taskId uint) error {
+	if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	task := model.Task{}
+	if tu.tr.GetTaskById(&task, userId, taskId) == nil {
+		return ErrForbidden
+	}
+	return err
+}
+
+// カスタムフィールドの値を、タスクのプロジェクトで定義されたフィールドに照らして検証
+// プロジェクトはタスクの作成者（ownerId）のものである必要がある
+func (tu taskUsecase) validateCustomFields(task model.Task, ownerId uint) error {
+	fields := []model.CustomField{}
+	if task.ProjectId != nil {
+		project := model.Project{}
+		if err := tu.pr.GetProjectById(&project, ownerId, *task.ProjectId); err != nil {
+			return err
+		}
+		if err := tu.pr.GetCustomFields(&fields, ownerId, *task.ProjectId); err != nil {
+			return err
+		}
+	}
+	if err := tu.tv.CustomFieldValuesValidate(task.CustomFields, fields); err != nil {
+		return err
+	}
+	// user の値には存在するユーザーのみ指定できる
+	for _, field := range fields {
+		id, ok := task.CustomFields[field.Key].(float64)
+		if field.Type != model.CustomFieldUser || !ok {
+			continue
+		}
+		user := model.User{}
+		if err := tu.ur.GetUserById(&user, uint(id)); err != nil {
+			return validator.NewFieldError("custom_fields."+field.Key, validator.CodeUnknownUser, nil)
+		}
+	}
 	return nil
 }
+
+// タスクをレスポンス形式に変換
+func toTaskResponse(task model.Task) model.TaskResponse {
+	return model.TaskResponse{
+		ID:               task.ID,
+		Title:            task.Title,
+		Status:           task.Status,
+		DueAt:            task.DueAt,
+		Labels:           task.Labels,
+		Priority:         task.Priority,
+		Recurrence:       task.Recurrence,
+		CompletedAt:      task.CompletedAt,
+		SnoozedUntil:     task.SnoozedUntil,
+		ParentId:         task.ParentId,
+		CreatedAt:        task.CreatedAt,
+		UpdatedAt:        task.UpdatedAt,
+		UserId:           task.UserId,
+		AssigneeId:       task.AssigneeId,
+		AssignmentStatus: task.AssignmentStatus,
+		Version:          task.ChangeSeq,
+		Checklist: model.ChecklistProgress{
+			Checked: task.ChecklistChecked,
+			Total:   task.ChecklistTotal,
+		},
+		ProjectId:    task.ProjectId,
+		CustomFields: task.CustomFields,
+	}
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/push"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+// タスク
+// GetTaskById は DB と同じく作成者と承諾待ち・承諾済みの担当者が取得でき、
+// UpdateTask は作成者のタスクの fields で指定された列のみを更新する
+type fakeTaskRepository struct {
+	repository.ITaskRepository
+	tasks   map[uint]model.Task
+	updated [][]string // UpdateTask に渡された fields
+}
+
+func (tr *fakeTaskRepository) GetTaskById(task *model.Task, userId uint, taskId uint) error {
+	t, ok := tr.tasks[taskId]
+	if !ok || !canReadTask(t, userId) {
+		return repository.ErrNotFound
+	}
+	*task = t
+	return nil
+}
+
+func (tr *fakeTaskRepository) UpdateTask(task *model.Task, fields []string, userId uint, taskId uint) error {
+	stored, ok := tr.tasks[taskId]
+	if !ok || stored.UserId != userId {
+		return repository.ErrNotFound
+	}
+	tr.updated = append(tr.updated, fields)
+	stored = applyTaskUpdate(stored, model.TaskUpdate{Task: *task, Fields: fields})
+	tr.tasks[taskId] = stored
+	*task = stored
+	return nil
+}
+
+func (tr *fakeTaskRepository) CreateTask(task *model.Task) error {
+	task.ID = uint(len(tr.tasks) + 1)
+	tr.tasks[task.ID] = *task
+	return nil
+}
+
+// タスクのユースケース（通知は作成者のみが受け取る）
+func newTestTaskUsecase(tr *fakeTaskRepository, users ...model.User) ITaskUsecase {
+	ur := newFakeUserRepository(users...)
+	nu := NewNotificationUsecase(&fakeNotificationRepository{}, ur, push.NewHub())
+	return NewTaskUsecase(tr, validator.NewTaskValidator(validator.LoadConfig()), ur, nil, nu)
+}
+
+func TestCreateTaskIgnoresServerManagedFields(t *testing.T) {
+	tr := &fakeTaskRepository{tasks: map[uint]model.Task{}}
+	tu := newTestTaskUsecase(tr, model.User{ID: 1, Email: "owner@example.com"})
+
+	body := `{"id":5,"title":"task","user_id":2,"assignee_id":2,"assignment_status":"accepted",
+		"snoozed_until":"2030-01-01T00:00:00Z","completed_at":"2020-01-01T00:00:00Z",
+		"user":{"email":"new@example.com","password":"secret","email_verified_at":"2026-01-01T00:00:00Z"}}`
+	task := model.Task{}
+	if err := json.Unmarshal([]byte(body), &task); err != nil {
+		t.Fatal(err)
+	}
+	task.UserId = 1
+
+	if _, err := tu.CreateTask(task); err != nil {
+		t.Fatal(err)
+	}
+	want := model.Task{
+		ID:           1,
+		Title:        "task",
+		Status:       model.TaskStatusOpen,
+		Labels:       model.Labels{},
+		Priority:     model.TaskPriorityNone,
+		UserId:       1,
+		CustomFields: model.CustomFieldValues{},
+	}
+	if got := tr.tasks[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("saved task = %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdateTaskOnlyChangesSentFields(t *testing.T) {
+	due := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	stored := model.Task{
+		ID:           1,
+		UserId:       1,
+		Title:        "牛乳を買う",
+		Status:       model.TaskStatusOpen,
+		DueAt:        &due,
+		Labels:       model.Labels{"home"},
+		Priority:     model.TaskPriorityHigh,
+		Recurrence:   model.TaskRecurrenceWeekly,
+		CustomFields: model.CustomFieldValues{},
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+		want   func(task model.Task) model.Task
+	}{
+		{
+			name:   "タイトルのみ",
+			body:   `{"title":"パンを買う"}`,
+			fields: []string{"title"},
+			want: func(task model.Task) model.Task {
+				task.Title = "パンを買う"
+				return task
+			},
+		},
+		{
+			name:   "null で期限をクリア",
+			body:   `{"due_at":null}`,
+			fields: []string{"due_at"},
+			want: func(task model.Task) model.Task {
+				task.DueAt = nil
+				return task
+			},
+		},
+		{
+			name:   "更新できない項目は無視",
+			body:   `{"id":2,"user_id":2,"assignee_id":2,"assignment_status":"accepted","priority":"low"}`,
+			fields: []string{"priority"},
+			want: func(task model.Task) model.Task {
+				task.Priority = model.TaskPriorityLow
+				return task
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := model.TaskUpdate{}
+			if err := json.Unmarshal([]byte(tt.body), &update); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(update.Fields, tt.fields) {
+				t.Fatalf("Fields = %v, want %v", update.Fields, tt.fields)
+			}
+
+			tr := &fakeTaskRepository{tasks: map[uint]model.Task{stored.ID: stored}}
+			ur := newFakeUserRepository(model.User{ID: 1, Email: "owner@example.com"})
+			nu := NewNotificationUsecase(&fakeNotificationRepository{}, ur, push.NewHub())
+			tu := NewTaskUsecase(tr, validator.NewTaskValidator(validator.LoadConfig()), ur, nil, nu)
+
+			if _, err := tu.UpdateTask(update, stored.UserId, stored.ID); err != nil {
+				t.Fatal(err)
+			}
+			if len(tr.updated) != 1 || !reflect.DeepEqual(tr.updated[0], tt.fields) {
+				t.Errorf("updated fields = %v, want %v", tr.updated, tt.fields)
+			}
+			if got, want := tr.tasks[stored.ID], tt.want(stored); !reflect.DeepEqual(got, want) {
+				t.Errorf("task = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestUpdateTaskValidatesSentFields(t *testing.T) {
+	stored := model.Task{ID: 1, UserId: 1, Title: "牛乳を買う", Status: model.TaskStatusOpen, Priority: model.TaskPriorityNone}
+	tr := &fakeTaskRepository{tasks: map[uint]model.Task{stored.ID: stored}}
+	tu := NewTaskUsecase(tr, validator.NewTaskValidator(validator.LoadConfig()), newFakeUserRepository(), nil, nil)
+
+	update := model.TaskUpdate{}
+	if err := json.Unmarshal([]byte(`{"title":""}`), &update); err != nil {
+		t.Fatal(err)
+	}
+	_, err := tu.UpdateTask(update, stored.UserId, stored.ID)
+	var verr *validator.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want validation error", err)
+	}
+	if len(tr.updated) != 0 {
+		t.Errorf("task was updated: %v", tr.updated)
+	}
+}
+
+func TestCreateSubtaskRequiresOwnParent(t *testing.T) {
+	owner := model.User{ID: 1, Email: "owner@example.com"}
+	assignee := model.User{ID: 2, Email: "assignee@example.com"}
+	outsider := model.User{ID: 3, Email: "outsider@example.com"}
+	parentId := uint(1)
+
+	tests := []struct {
+		name   string
+		userId uint
+		status string
+		err    error
+	}{
+		{"作成者", owner.ID, model.AssignmentPending, nil},
+		{"承諾待ちの担当者", assignee.ID, model.AssignmentPending, ErrForbidden},
+		{"承諾済みの担当者", assignee.ID, model.AssignmentAccepted, ErrForbidden},
+		{"辞退した担当者", assignee.ID, model.AssignmentDeclined, repository.ErrNotFound},
+		{"無関係のユーザー", outsider.ID, model.AssignmentPending, repository.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := model.Task{ID: parentId, UserId: owner.ID, Title: "parent", AssigneeId: &assignee.ID, AssignmentStatus: tt.status}
+			tr := &fakeTaskRepository{tasks: map[uint]model.Task{parentId: parent}}
+			tu := newTestTaskUsecase(tr, owner, assignee, outsider)
+
+			_, err := tu.CreateTask(model.Task{Title: "subtask", UserId: tt.userId, ParentId: &parentId})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if created := len(tr.tasks) > 1; created != (tt.err == nil) {
+				t.Errorf("subtask created = %v", created)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/repository"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/totp"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/validator"
+)
+
+const (
+	recoveryCodeCount = 10              // 一度に発行するリカバリーコードの数
+	defaultTotpIssuer = "Echo-REST-API" // 認証アプリに表示するサービス名（TOTP_ISSUER で変更できる）
+)
+
+var (
+	// 2段階認証がすでに有効な場合のエラー
+	ErrTwoFactorEnabled = newError(KindConflict, "two_factor_enabled", "two-factor authentication is already enabled")
+	// 2段階認証が有効でない場合のエラー
+	ErrTwoFactorNotEnabled = newError(KindConflict, "two_factor_not_enabled", "two-factor authentication is not enabled")
+	// 設定を開始せずに確定しようとした場合のエラー
+	ErrTwoFactorNotSetUp = newError(KindConflict, "two_factor_not_set_up", "two-factor authentication setup has not been started")
+)
+
+// 2段階認証（TOTP）の設定に関するユースケースのインターフェース定義
+type ITwoFactorUsecase interface {
+	GetStatus(userId uint) (model.TwoFactorStatusResponse, error)                                             // 2段階認証の設定状況を取得
+	Setup(userId uint) (model.TwoFactorSetupResponse, error)                                                  // シークレットを発行して設定を開始する
+	Confirm(userId uint, req model.TwoFactorCodeRequest) (model.RecoveryCodesResponse, error)                 // 認証アプリのコードで設定を確定し、リカバリーコードを発行する
+	RegenerateRecoveryCodes(userId uint, req model.TwoFactorCodeRequest) (model.RecoveryCodesResponse, error) // リカバリーコードを再発行する
+	Disable(userId uint, req model.TwoFactorCodeRequest) error                                                // 2段階認証を無効にする
+}
+
+type twoFactorUsecase struct {
+	ur repository.IUserRepository
+	uv validator.IUserValidator
+	cr repository.IRecoveryCodeRepository
+}
+
+// コンストラクタ関数
+func NewTwoFactorUsecase(ur repository.IUserRepository, uv validator.IUserValidator, cr repository.IRecoveryCodeRepository) ITwoFactorUsecase {
+	return &twoFactorUsecase{ur, uv, cr}
+}
+
+// 2段階認証の設定状況と、未使用のリカバリーコードの数を取得
+func (tu *twoFactorUsecase) GetStatus(userId uint) (model.TwoFactorStatusResponse, error) {
+	user := model.User{}
+	if err := tu.ur.GetUserById(&user, userId); err != nil {
+		return model.TwoFactorStatusResponse{}, err
+	}
+	res := model.TwoFactorStatusResponse{Enabled: user.TotpEnabledAt != nil}
+	if res.Enabled {
+		if err := tu.cr.CountUnusedRecoveryCodes(&res.RecoveryCodesRemaining, userId); err != nil {
+			return model.TwoFactorStatusResponse{}, err
+		}
+	}
+	return res, nil
+}
+
+// 新しいシークレットを発行して、2段階認証の設定を開始する
+// 確定するまでは無効のままで、再度呼び出すとシークレットは新しいものに置き換わる
+func (tu *twoFactorUsecase) Setup(userId uint) (model.TwoFactorSetupResponse, error) {
+	user := model.User{}
+	if err := tu.ur.GetUserById(&user, userId); err != nil {
+		return model.TwoFactorSetupResponse{}, err
+	}
+	if user.TotpEnabledAt != nil {
+		return model.TwoFactorSetupResponse{}, ErrTwoFactorEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return model.TwoFactorSetupResponse{}, err
+	}
+	if err := tu.ur.SetTotpSecret(userId, secret); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.TwoFactorSetupResponse{}, ErrTwoFactorEnabled
+		}
+		return model.TwoFactorSetupResponse{}, err
+	}
+	return model.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer(), user.Email, secret),
+	}, nil
+}
+
+// 認証アプリに表示されたコードで設定を確定し、2段階認証を有効にする
+// リカバリーコードはこのレスポンスでのみ返す
+func (tu *twoFactorUsecase) Confirm(userId uint, req model.TwoFactorCodeRequest) (model.RecoveryCodesResponse, error) {
+	if err := tu.uv.TwoFactorCodeValidate(req); err != nil {
+		return model.RecoveryCodesResponse{}, err
+	}
+	user := model.User{}
+	if err := tu.ur.GetUserById(&user, userId); err != nil {
+		return model.RecoveryCodesResponse{}, err
+	}
+	if user.TotpEnabledAt != nil {
+		return model.RecoveryCodesResponse{}, ErrTwoFactorEnabled
+	}
+	if user.TotpSecret == "" {
+		return model.RecoveryCodesResponse{}, ErrTwoFactorNotSetUp
+	}
+	step, ok := totp.Validate(user.TotpSecret, strings.TrimSpace(req.Code), time.Now())
+	if !ok {
+		return model.RecoveryCodesResponse{}, validator.NewFieldError("code", validator.CodeIncorrect, nil)
+	}
+	// 有効にした後にコードの保存に失敗してリカバリーコードがない状態にならないよう、先に保存する
+	codes, err := tu.replaceRecoveryCodes(userId)
+	if err != nil {
+		return model.RecoveryCodesResponse{}, err
+	}
+	if err := tu.ur.EnableTotp(userId, user.TotpSecret, step, time.Now()); err != nil {
+		// 同時に設定を開始し直した、または確定した場合
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.RecoveryCodesResponse{}, ErrConflict
+		}
+		return model.RecoveryCodesResponse{}, err
+	}
+	return codes, nil
+}
+
+// 確認コードかリカバリーコードを確認して、リカバリーコードを再発行する（以前のコードは使えなくなる）
+func (tu *twoFactorUsecase) RegenerateRecoveryCodes(userId uint, req model.TwoFactorCodeRequest) (model.RecoveryCodesResponse, error) {
+	if err := tu.checkCode(userId, req); err != nil {
+		return model.RecoveryCodesResponse{}, err
+	}
+	return tu.replaceRecoveryCodes(userId)
+}
+
+// 確認コードかリカバリーコードを確認して、2段階認証を無効にする
+// シークレットとリカバリーコードは削除する
+func (tu *twoFactorUsecase) Disable(userId uint, req model.TwoFactorCodeRequest) error {
+	if err := tu.checkCode(userId, req); err != nil {
+		return err
+	}
+	if err := tu.ur.DisableTotp(userId); err != nil {
+		return err
+	}
+	return tu.cr.DeleteRecoveryCodes(userId)
+}
+
+// 2段階認証が有効で、確認コードかリカバリーコードが正しいことを確認する
+func (tu *twoFactorUsecase) checkCode(userId uint, req model.TwoFactorCodeRequest) error {
+	if err := tu.uv.TwoFactorCodeValidate(req); err != nil {
+		return err
+	}
+	user := model.User{}
+	if err := tu.ur.GetUserById(&user, userId); err != nil {
+		return err
+	}
+	if user.TotpEnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+	ok, err := verifySecondFactor(tu.ur, tu.cr, user, req.Code, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return validator.NewFieldError("code", validator.CodeIncorrect, nil)
+	}
+	return nil
+}
+
+// 新しいリカバリーコードを発行して保存する
+func (tu *twoFactorUsecase) replaceRecoveryCodes(userId uint) (model.RecoveryCodesResponse, error) {
+	res := model.RecoveryCodesResponse{RecoveryCodes: make([]string, recoveryCodeCount)}
+	stored := make([]model.RecoveryCode, recoveryCodeCount)
+	for i := range res.RecoveryCodes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return model.RecoveryCodesResponse{}, err
+		}
+		res.RecoveryCodes[i] = code
+		stored[i] = model.RecoveryCode{UserId: userId, CodeHash: hashToken(normalizeRecoveryCode(code))}
+	}
+	if err := tu.cr.ReplaceRecoveryCodes(userId, stored); err != nil {
+		return model.RecoveryCodesResponse{}, err
+	}
+	return res, nil
+}
+
+// 認証アプリの確認コード、またはリカバリーコードを検証する（どちらも一度しか使えない）
+// 2段階認証が有効なユーザーの user を受け取り、コードが正しくない場合は false を返す
+func verifySecondFactor(ur repository.IUserRepository, cr repository.IRecoveryCodeRepository, user model.User, code string, now time.Time) (bool, error) {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(user.TotpSecret, code, now); ok {
+		// 使用済みのステップの場合は、同じコードが再び使われたとみなす
+		if err := ur.UseTotpStep(user.ID, step); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	if err := cr.UseRecoveryCode(user.ID, hashToken(normalizeRecoveryCode(code)), now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// リカバリーコードを生成（xxxxx-xxxxx の形式の16進数）
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(b)
+	return code[:5] + "-" + code[5:], nil
+}
+
+// 入力されたリカバリーコードの区切りや空白、大文字小文字の違いを無視する
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// 認証アプリに表示するサービス名
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return defaultTotpIssuer
+}
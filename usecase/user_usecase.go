@@ -1,7 +1,15 @@
 package usecase
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
@@ -11,21 +19,101 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	accessTokenTTL  = 15 * time.Minute    // アクセストークン（JWT）の有効期間
+	refreshTokenTTL = 30 * 24 * time.Hour // リフレッシュトークンの有効期間（再発行のたびに延長される）
+
+	maxUserAgentLength = 512 // セッションに保存する User-Agent の最大文字数
+
+	emailVerificationTTL       = 24 * time.Hour       // メールアドレスの確認リンクの有効期間
+	verificationResendInterval = time.Minute          // 確認リンクを再送できる間隔（ユーザーごと）
+	purposeEmailVerification   = "email_verification" // 確認リンクのトークンの用途
+
+	passwordResetTTL            = time.Hour   // パスワードの再設定リンクの有効期間
+	passwordResetResendInterval = time.Minute // 再設定リンクを送信できる間隔（ユーザーごと）
+
+	mfaTokenTTL     = 5 * time.Minute // ログインの2段階目（確認コードの送信）までの有効期間
+	purposeMfaLogin = "mfa_login"     // ログインの2段階目のトークンの用途
+)
+
+// メールアドレスの確認に関する方針（EMAIL_VERIFICATION_POLICY で設定）
+type EmailVerificationPolicy string
+
+const (
+	VerificationOptional EmailVerificationPolicy = "none"  // 確認しなくてもすべての機能を使える（既定）
+	VerificationForTasks EmailVerificationPolicy = "tasks" // 確認するまでタスクを作成できない
+	VerificationForLogin EmailVerificationPolicy = "login" // 確認するまでログインできない（タスクの作成も同様）
+)
+
+// 環境変数からメールアドレスの確認に関する方針を読み込む（未設定または不正な値の場合は none）
+func LoadEmailVerificationPolicy() EmailVerificationPolicy {
+	switch p := EmailVerificationPolicy(os.Getenv("EMAIL_VERIFICATION_POLICY")); p {
+	case VerificationForTasks, VerificationForLogin:
+		return p
+	}
+	return VerificationOptional
+}
+
+// メールアドレスを確認するまでタスクを作成できないかどうか
+func (p EmailVerificationPolicy) BlocksTaskCreation() bool {
+	return p == VerificationForTasks || p == VerificationForLogin
+}
+
+var (
+	// リフレッシュトークンが存在しない、または有効期限が切れている場合のエラー
+	ErrInvalidRefreshToken = newError(KindUnauthorized, "invalid_refresh_token", "refresh token is invalid or expired")
+	// 使用済みのリフレッシュトークンが再び使われた場合のエラー（同じログインのトークンはすべて失効させる）
+	ErrRefreshTokenReused = newError(KindUnauthorized, "refresh_token_reused", "refresh token was already used")
+	// パスワードの再設定トークンが正しくない、期限切れ、または使用済みの場合のエラー
+	ErrInvalidResetToken = newError(KindInvalid, "invalid_reset_token", "password reset link is invalid or expired")
+	// 確認リンクのトークンが正しくない、期限切れ、または使用済みの場合のエラー
+	ErrInvalidVerificationToken = newError(KindInvalid, "invalid_verification_token", "verification link is invalid or expired")
+	// メールアドレスを確認するまで利用できない操作の場合のエラー
+	ErrEmailNotVerified = newError(KindForbidden, "email_not_verified", "email address is not verified")
+	// ログインの2段階目のトークンが正しくない、または期限切れの場合のエラー（最初からログインし直す）
+	ErrInvalidMfaToken = newError(KindUnauthorized, "invalid_mfa_token", "two-factor login is invalid or expired")
+	// ログインの2段階目で、確認コードやリカバリーコードが正しくない場合のエラー
+	ErrInvalidTwoFactorCode = newError(KindUnauthorized, "invalid_two_factor_code", "two-factor authentication code is incorrect")
+)
+
 // ユーザーに関するユースケースのインターフェース定義
 type IUserUsecase interface {
-	SignUp(user model.User) (model.UserResponse, error) // 新規ユーザー登録
-	LogIn(user model.User) (string, error)              // ログインしJWTトークンを返す
+	SignUp(user model.User) (model.UserResponse, error)                                                            // 新規ユーザー登録
+	LogIn(user model.User, meta model.SessionMeta) (model.LoginResult, error)                                      // ログインしてセッションを作成し、トークンを返す（2段階目が必要な場合は2段階目のトークンを返す）
+	LogInMfa(req model.MfaLoginRequest, meta model.SessionMeta) (model.AuthTokens, error)                          // 2段階目のトークンと確認コードでログインし、トークンを返す
+	RefreshTokens(refreshToken string) (model.AuthTokens, error)                                                   // リフレッシュトークンを新しいものに置き換え、アクセストークンを再発行
+	LogOut(accessToken string, refreshToken string) error                                                          // ログアウトしたトークンとセッションを失効させる
+	LogOutAll(userId uint) error                                                                                   // すべての端末のトークンとセッションを失効させる
+	ChangePassword(userId uint, req model.PasswordChangeRequest, meta model.SessionMeta) (model.AuthTokens, error) // パスワードを変更し、ほかの端末のトークンを失効させる
+	VerifyEmail(token string) error                                                                                // 確認リンクのトークンでメールアドレスを確認済みにする
+	ResendVerificationEmail(email string) error                                                                    // 確認リンクを再送する
+	ForgotPassword(email string) error                                                                             // パスワードの再設定リンクを送信する
+	ResetPassword(req model.PasswordResetRequest) error                                                            // 再設定リンクのトークンでパスワードを再設定する
+	GetUser(userId uint) (model.UserResponse, error)                                                               // ログイン中のユーザーの情報を取得
+	ChangeEmail(userId uint, req model.EmailChangeRequest) (model.UserResponse, error)                             // メールアドレスを変更し、新しいアドレスに確認リンクを送信する
+	DeleteAccount(userId uint, req model.AccountDeleteRequest) error                                               // パスワードを確認してアカウントを削除する
 }
 
 // ユースケースの構造体（リポジトリとバリデータへの依存を持つ）
 type userUsecase struct {
-	ur repository.IUserRepository // データアクセス
-	uv validator.IUserValidator   // 入力バリデーション
+	ur repository.IUserRepository          // データアクセス
+	uv validator.IUserValidator            // 入力バリデーション
+	eu IEmailUsecase                       // サインアップ確認メールの送信
+	rr repository.IRefreshTokenRepository  // リフレッシュトークンの保存
+	tr repository.IRevokedTokenRepository  // ログアウトで失効させたアクセストークンの保存
+	sr repository.ISessionRepository       // ログインした端末ごとのセッション
+	pr repository.IPasswordResetRepository // パスワードの再設定トークンの保存
+	cr repository.IRecoveryCodeRepository  // 2段階認証のリカバリーコード
+	kr repository.IPasskeyRepository       // ログインの2段階目に使えるパスキー
+	vp EmailVerificationPolicy             // メールアドレスの確認に関する方針
 }
 
 // ユースケースのコンストラクタ関数
-func NewUserUsecase(ur repository.IUserRepository, uv validator.IUserValidator) IUserUsecase {
-	return &userUsecase{ur, uv}
+func NewUserUsecase(ur repository.IUserRepository, uv validator.IUserValidator, eu IEmailUsecase,
+	rr repository.IRefreshTokenRepository, tr repository.IRevokedTokenRepository, sr repository.ISessionRepository,
+	pr repository.IPasswordResetRepository, cr repository.IRecoveryCodeRepository, kr repository.IPasskeyRepository,
+	vp EmailVerificationPolicy) IUserUsecase {
+	return &userUsecase{ur, uv, eu, rr, tr, sr, pr, cr, kr, vp}
 }
 
 // ユーザー登録処理
@@ -39,46 +127,597 @@ func (uu *userUsecase) SignUp(user model.User) (model.UserResponse, error) {
 	if err != nil {
 		return model.UserResponse{}, err
 	}
+	// メールアドレスの確認リンクに含める値
+	nonce, err := newOpaqueToken()
+	if err != nil {
+		return model.UserResponse{}, err
+	}
+	now := time.Now()
 	// ハッシュ化済みのユーザー情報を作成
-	newUser := model.User{Email: user.Email, Password: string(hash)}
+	newUser := model.User{
+		Email:                   user.Email,
+		Password:                string(hash),
+		Timezone:                user.Timezone,
+		EmailVerificationNonce:  nonce,
+		EmailVerificationSentAt: &now,
+	}
 	// DBへ新規ユーザー登録
 	if err := uu.ur.CreateUser(&newUser); err != nil {
+		// メールアドレスの一意制約に違反した場合は登録済み
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.UserResponse{}, ErrEmailTaken
+		}
 		return model.UserResponse{}, err
 	}
+	// 確認メールはレスポンスを待たせないよう非同期で送信
+	go uu.eu.SendSignupConfirmation(newUser, verificationURL(newUser.ID, nonce, now))
 	// レスポンス用に必要な情報のみ返す
-	resUser := model.UserResponse{
-		ID:    newUser.ID,
-		Email: newUser.Email,
-	}
-	return resUser, nil
+	return toUserResponse(newUser), nil
 }
 
 // ログイン処理（トークン発行）
-func (uu *userUsecase) LogIn(user model.User) (string, error) {
+// 2段階認証が有効、またはパスキーを登録しているユーザーは、トークンの代わりに2段階目のトークンを返し、
+// LogInMfa で確認コードを、またはパスキーのユースケースでパスキーの認証を受け取ってから発行する
+func (uu *userUsecase) LogIn(user model.User, meta model.SessionMeta) (model.LoginResult, error) {
 	// 入力バリデーション
 	if err := uu.uv.UserValidate(user); err != nil {
-		return "", err
+		return model.LoginResult{}, err
 	}
 	// 入力されたメールアドレスでDBからユーザー取得
 	storedUser := model.User{}
+	// ユーザーが存在しない場合とパスワードが違う場合は、区別できないよう同じエラーを返す
 	if err := uu.ur.GetUserByEmail(&storedUser, user.Email); err != nil {
-		return "", err
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.LoginResult{}, ErrInvalidCredentials
+		}
+		return model.LoginResult{}, err
 	}
 	// パスワードの照合（bcryptのハッシュ比較）
 	err := bcrypt.CompareHashAndPassword([]byte(storedUser.Password), []byte(user.Password))
 	if err != nil {
-		return "", err
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return model.LoginResult{}, ErrInvalidCredentials
+		}
+		return model.LoginResult{}, err
+	}
+	// パスワードが正しい場合のみ、確認が済んでいないことを伝える
+	if uu.vp == VerificationForLogin && storedUser.EmailVerifiedAt == nil {
+		return model.LoginResult{}, ErrEmailNotVerified
+	}
+	now := time.Now()
+	// 2段階目が必要な場合は、確認コードかパスキーの認証を受け取るまでセッションを作成しない
+	methods, err := mfaMethods(uu.kr, storedUser)
+	if err != nil {
+		return model.LoginResult{}, err
+	}
+	if len(methods) > 0 {
+		expiresAt := now.Add(mfaTokenTTL)
+		return model.LoginResult{
+			MfaToken:          signToken(purposeMfaLogin, strconv.FormatUint(uint64(storedUser.ID), 10), expiresAt),
+			MfaTokenExpiresAt: expiresAt,
+			MfaMethods:        methods,
+		}, nil
+	}
+	tokens, err := startSession(uu.sr, uu.rr, storedUser.ID, meta, now)
+	if err != nil {
+		return model.LoginResult{}, err
+	}
+	return model.LoginResult{Tokens: tokens}, nil
+}
+
+// ログインの2段階目（2段階目のトークンと、認証アプリの確認コードまたはリカバリーコードでトークンを発行）
+func (uu *userUsecase) LogInMfa(req model.MfaLoginRequest, meta model.SessionMeta) (model.AuthTokens, error) {
+	if err := uu.uv.MfaLoginValidate(req); err != nil {
+		return model.AuthTokens{}, err
+	}
+	now := time.Now()
+	user, err := mfaTokenUser(uu.ur, uu.kr, req.MfaToken, now)
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	// パスキーのみを登録しているユーザーは、確認コードではなくパスキーで2段階目を行う
+	if user.TotpEnabledAt == nil {
+		return model.AuthTokens{}, ErrInvalidTwoFactorCode
+	}
+	ok, err := verifySecondFactor(uu.ur, uu.cr, user, req.Code, now)
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	if !ok {
+		return model.AuthTokens{}, ErrInvalidTwoFactorCode
+	}
+	return startSession(uu.sr, uu.rr, user.ID, meta, now)
+}
+
+// リフレッシュトークンを新しいものに置き換え（ローテーション）、アクセストークンを再発行
+// 使用済みのトークンが再び使われた場合は、トークンが盗まれた可能性があるため同じファミリーのトークンをすべて失効させる
+func (uu *userUsecase) RefreshTokens(refreshToken string) (model.AuthTokens, error) {
+	if refreshToken == "" {
+		return model.AuthTokens{}, ErrInvalidRefreshToken
+	}
+	now := time.Now()
+	tokens := model.AuthTokens{}
+	current := model.RefreshToken{}
+	reused := false
+	err := uu.rr.Transaction(func(rr repository.IRefreshTokenRepository) error {
+		if err := rr.GetRefreshTokenForUpdate(&current, hashToken(refreshToken)); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		// 失効はエラーを返すとロールバックされるため、ここでは失効させてコミットし、呼び出し元でエラーを返す
+		if current.UsedAt != nil || current.RevokedAt != nil {
+			reused = true
+			return rr.RevokeTokenFamily(current.FamilyId, now)
+		}
+		// セッションに紐づかない（セッションの導入前に発行した）トークンは再発行に使えない
+		if !current.ExpiresAt.After(now) || current.SessionId == 0 {
+			return ErrInvalidRefreshToken
+		}
+		if err := rr.MarkRefreshTokenUsed(current.ID, now); err != nil {
+			return err
+		}
+		var err error
+		tokens, err = issueTokens(rr, current.UserId, current.FamilyId, current.SessionId, now)
+		return err
+	})
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	if reused {
+		// 同じセッションのアクセストークンも使えないようにする（失効済みの場合は何もしない）
+		if err := uu.sr.RevokeSession(current.UserId, current.SessionId, now); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return model.AuthTokens{}, err
+		}
+		return model.AuthTokens{}, ErrRefreshTokenReused
+	}
+	// セッションの有効期限を新しいリフレッシュトークンに合わせる
+	// トークンはすでに置き換わっているため、失敗してもエラーにはせずログに残す
+	if err := uu.sr.ExtendSession(current.SessionId, tokens.RefreshTokenExpiresAt); err != nil {
+		log.Printf("failed to extend session %d: %v", current.SessionId, err)
+	}
+	return tokens, nil
+}
+
+// ログアウト（アクセストークンと、同じログインで発行したリフレッシュトークン、セッションを失効させる）
+// トークンが無効・期限切れの場合は、失効させる必要がないため何もしない
+func (uu *userUsecase) LogOut(accessToken string, refreshToken string) error {
+	now := time.Now()
+	if claims, ok := parseAccessToken(accessToken); ok {
+		jti, _ := claims["jti"].(string)
+		userId, _ := claims["user_id"].(float64)
+		sessionId, _ := claims["sid"].(float64)
+		exp, _ := claims["exp"].(float64)
+		if sessionId != 0 {
+			// リフレッシュトークンを送信しないクライアントもあるため、セッションのリフレッシュトークンもまとめて失効させる
+			if err := uu.sr.RevokeSession(uint(userId), uint(sessionId), now); err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+			if err := uu.rr.RevokeSessionRefreshTokens(uint(sessionId), now); err != nil {
+				return err
+			}
+		}
+		if jti != "" {
+			// 有効期限が過ぎればJWTの検証で拒否されるため、それまでの間だけ記録する
+			revoked := model.RevokedToken{Jti: jti, UserId: uint(userId), ExpiresAt: time.Unix(int64(exp), 0)}
+			if err := uu.tr.RevokeToken(&revoked); err != nil {
+				return err
+			}
+		}
+	}
+	if refreshToken != "" {
+		// アクセストークンの期限が切れていても、リフレッシュトークンからセッションを特定して失効させる
+		if err := uu.sr.RevokeSessionByRefreshToken(hashToken(refreshToken), now); err != nil {
+			return err
+		}
+		if err := uu.rr.RevokeTokenFamilyByHash(hashToken(refreshToken), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// すべての端末からログアウト（発行済みのアクセストークンとリフレッシュトークン、セッションをすべて失効させる）
+func (uu *userUsecase) LogOutAll(userId uint) error {
+	now := time.Now()
+	if err := uu.ur.RevokeUserTokens(userId, tokensValidAfter(now)); err != nil {
+		return err
+	}
+	return uu.revokeAllSessions(userId, now)
+}
+
+// パスワードを変更
+// 発行済みのトークンはすべて失効させ、変更した端末には新しいトークンを発行する
+func (uu *userUsecase) ChangePassword(userId uint, req model.PasswordChangeRequest, meta model.SessionMeta) (model.AuthTokens, error) {
+	if err := uu.uv.PasswordChangeValidate(req); err != nil {
+		return model.AuthTokens{}, err
+	}
+	user := model.User{}
+	if err := uu.ur.GetUserById(&user, userId); err != nil {
+		return model.AuthTokens{}, err
+	}
+	// 現在のパスワードの照合
+	if err := checkPassword(user, req.CurrentPassword, "current_password"); err != nil {
+		return model.AuthTokens{}, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 10)
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+
+	now := time.Now()
+	if err := uu.ur.UpdatePassword(userId, string(hash), tokensValidAfter(now)); err != nil {
+		return model.AuthTokens{}, err
+	}
+	if err := uu.revokeAllSessions(userId, now); err != nil {
+		return model.AuthTokens{}, err
+	}
+	return startSession(uu.sr, uu.rr, userId, meta, now)
+}
+
+// ログイン中のユーザーの情報を取得
+func (uu *userUsecase) GetUser(userId uint) (model.UserResponse, error) {
+	user := model.User{}
+	if err := uu.ur.GetUserById(&user, userId); err != nil {
+		return model.UserResponse{}, err
+	}
+	return toUserResponse(user), nil
+}
+
+// メールアドレスを変更する
+// 新しいアドレスは確認が済むまで未確認として扱い、確認リンクを新しいアドレスに送信する（以前の確認リンクは使えなくなる）
+func (uu *userUsecase) ChangeEmail(userId uint, req model.EmailChangeRequest) (model.UserResponse, error) {
+	if err := uu.uv.EmailChangeValidate(req); err != nil {
+		return model.UserResponse{}, err
+	}
+	user := model.User{}
+	if err := uu.ur.GetUserById(&user, userId); err != nil {
+		return model.UserResponse{}, err
+	}
+	if err := checkPassword(user, req.CurrentPassword, "current_password"); err != nil {
+		return model.UserResponse{}, err
+	}
+	// 現在と同じアドレスの場合は何もしない
+	if req.NewEmail == user.Email {
+		return toUserResponse(user), nil
+	}
+	nonce, err := newOpaqueToken()
+	if err != nil {
+		return model.UserResponse{}, err
+	}
+	now := time.Now()
+	if err := uu.ur.UpdateEmail(userId, req.NewEmail, nonce, now); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.UserResponse{}, ErrEmailTaken
+		}
+		return model.UserResponse{}, err
+	}
+	user.Email = req.NewEmail
+	user.EmailVerifiedAt = nil
+	go uu.eu.SendVerificationEmail(user, verificationURL(user.ID, nonce, now))
+	return toUserResponse(user), nil
+}
+
+// パスワードを確認してアカウントを削除する
+// タスクやセッションなどのユーザーに紐づくデータもすべて削除されるため、発行済みのトークンも使えなくなる
+func (uu *userUsecase) DeleteAccount(userId uint, req model.AccountDeleteRequest) error {
+	if err := uu.uv.AccountDeleteValidate(req); err != nil {
+		return err
+	}
+	user := model.User{}
+	if err := uu.ur.GetUserById(&user, userId); err != nil {
+		return err
 	}
-	// JWTトークン生成（有効期限12時間）
+	if err := checkPassword(user, req.Password, "password"); err != nil {
+		return err
+	}
+	return uu.ur.DeleteUser(userId)
+}
+
+// パスワードの再設定リンクを送信する
+// 登録されているメールアドレスかどうかを知られないよう、未登録や送信の間隔が短すぎる場合も何もせず成功とする
+func (uu *userUsecase) ForgotPassword(email string) error {
+	user := model.User{}
+	if err := uu.ur.GetUserByEmail(&user, email); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	// 以前に送信したリンクは使えなくなる
+	token := model.PasswordResetToken{
+		UserId:    user.ID,
+		TokenHash: hashToken(secret),
+		ExpiresAt: now.Add(passwordResetTTL),
+	}
+	if err := uu.pr.CreatePasswordResetToken(&token, now.Add(-passwordResetResendInterval)); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil
+		}
+		return err
+	}
+	go uu.eu.SendPasswordReset(user, os.Getenv("FE_URL")+"/reset-password?token="+url.QueryEscape(secret))
+	return nil
+}
+
+// 再設定リンクのトークンでパスワードを再設定する
+// トークンは一度だけ使え、発行済みのトークンとセッションはすべて失効させる（再度ログインが必要）
+func (uu *userUsecase) ResetPassword(req model.PasswordResetRequest) error {
+	if err := uu.uv.PasswordResetValidate(req); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 10)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	token := model.PasswordResetToken{}
+	if err := uu.pr.ConsumePasswordResetToken(&token, hashToken(req.Token), now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := uu.ur.UpdatePassword(token.UserId, string(hash), tokensValidAfter(now)); err != nil {
+		return err
+	}
+	return uu.revokeAllSessions(token.UserId, now)
+}
+
+// ユーザーのリフレッシュトークンとセッションをすべて失効させる
+// （アクセストークンは tokens_valid_after の更新で無効にする）
+func (uu *userUsecase) revokeAllSessions(userId uint, now time.Time) error {
+	if err := uu.rr.RevokeUserRefreshTokens(userId, now); err != nil {
+		return err
+	}
+	return uu.sr.RevokeUserSessions(userId, now)
+}
+
+// 確認リンクのトークンでメールアドレスを確認済みにする
+// トークンは署名と有効期限を検証し、ユーザーに保存した値と一致する場合のみ受け付ける（一度使うと無効になる）
+func (uu *userUsecase) VerifyEmail(token string) error {
+	now := time.Now()
+	payload, ok := verifySignedToken(purposeEmailVerification, token, now)
+	if !ok {
+		return ErrInvalidVerificationToken
+	}
+	id, nonce, ok := strings.Cut(payload, ":")
+	userId, err := strconv.ParseUint(id, 10, 64)
+	if !ok || err != nil {
+		return ErrInvalidVerificationToken
+	}
+	if err := uu.ur.VerifyEmail(uint(userId), nonce, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	return nil
+}
+
+// 確認リンクを再送する（以前に送信したリンクは使えなくなる）
+// 登録されているメールアドレスかどうかを知られないよう、未登録・確認済み・再送の間隔が短すぎる場合も何もせず成功とする
+func (uu *userUsecase) ResendVerificationEmail(email string) error {
+	user := model.User{}
+	if err := uu.ur.GetUserByEmail(&user, email); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	nonce, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := uu.ur.SetEmailVerificationNonce(user.ID, nonce, now, now.Add(-verificationResendInterval)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	go uu.eu.SendVerificationEmail(user, verificationURL(user.ID, nonce, now))
+	return nil
+}
+
+// 新しいセッションを作成し、最初のトークンを発行する（ログインごとに新しいトークンのファミリーを開始）
+func startSession(sr repository.ISessionRepository, rr repository.IRefreshTokenRepository, userId uint, meta model.SessionMeta, now time.Time) (model.AuthTokens, error) {
+	session := model.Session{
+		UserId:     userId,
+		UserAgent:  truncateRunes(meta.UserAgent, maxUserAgentLength),
+		IPAddress:  meta.IPAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(refreshTokenTTL),
+	}
+	if err := sr.CreateSession(&session); err != nil {
+		return model.AuthTokens{}, err
+	}
+	familyId, err := newOpaqueToken()
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	return issueTokens(rr, userId, familyId, session.ID, now)
+}
+
+// ログインの2段階目のトークンを検証して、ログイン中のユーザーを取得する
+// 2段階目のトークンを発行した後に2段階認証を無効にし、パスキーもすべて削除した場合は、最初からログインし直す
+func mfaTokenUser(ur repository.IUserRepository, kr repository.IPasskeyRepository, mfaToken string, now time.Time) (model.User, error) {
+	payload, ok := verifySignedToken(purposeMfaLogin, mfaToken, now)
+	if !ok {
+		return model.User{}, ErrInvalidMfaToken
+	}
+	userId, err := strconv.ParseUint(payload, 10, 64)
+	if err != nil {
+		return model.User{}, ErrInvalidMfaToken
+	}
+	user := model.User{}
+	if err := ur.GetUserById(&user, uint(userId)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.User{}, ErrInvalidMfaToken
+		}
+		return model.User{}, err
+	}
+	methods, err := mfaMethods(kr, user)
+	if err != nil {
+		return model.User{}, err
+	}
+	if len(methods) == 0 {
+		return model.User{}, ErrInvalidMfaToken
+	}
+	return user, nil
+}
+
+// パスワードでのログインの2段階目に使える方法（空の場合は2段階目は不要）
+// 2段階認証（TOTP）が有効な場合は確認コード、パスキーを登録している場合はパスキーを使える
+func mfaMethods(kr repository.IPasskeyRepository, user model.User) ([]string, error) {
+	methods := []string{}
+	if user.TotpEnabledAt != nil {
+		methods = append(methods, model.MfaMethodTotp)
+	}
+	var count int64
+	if err := kr.CountPasskeys(&count, user.ID); err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		methods = append(methods, model.MfaMethodPasskey)
+	}
+	return methods, nil
+}
+
+// アクセストークン（JWT）と、ファミリーに追加する新しいリフレッシュトークンを発行
+// sid はトークンを発行したセッションのID（セッションを失効させるとアクセストークンも使えなくなる）
+func issueTokens(rr repository.IRefreshTokenRepository, userId uint, familyId string, sessionId uint, now time.Time) (model.AuthTokens, error) {
+	// JWTトークン生成（jti はログアウトで個別に失効させるためのID）
+	jti, err := newOpaqueToken()
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	accessExpiresAt := now.Add(accessTokenTTL)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": storedUser.ID,
-		"exp":     time.Now().Add(time.Hour * 12).Unix(),
+		"user_id": userId,
+		"jti":     jti,
+		"sid":     sessionId,
+		"iat":     now.Unix(),
+		"exp":     accessExpiresAt.Unix(),
 	})
 	// 環境変数にあるシークレットキーで署名
-	tokenString, err := token.SignedString([]byte(os.Getenv("SECRET")))
+	accessToken, err := token.SignedString([]byte(os.Getenv("SECRET")))
+	if err != nil {
+		return model.AuthTokens{}, err
+	}
+
+	// リフレッシュトークンはハッシュのみを保存する
+	refreshToken, err := newOpaqueToken()
 	if err != nil {
+		return model.AuthTokens{}, err
+	}
+	stored := model.RefreshToken{
+		UserId:    userId,
+		FamilyId:  familyId,
+		SessionId: sessionId,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(refreshTokenTTL),
+	}
+	if err := rr.CreateRefreshToken(&stored); err != nil {
+		return model.AuthTokens{}, err
+	}
+	return model.AuthTokens{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// レスポンス用にユーザーの情報のうち必要なものだけを取り出す
+func toUserResponse(user model.User) model.UserResponse {
+	return model.UserResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		Timezone:         user.Timezone,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		TwoFactorEnabled: user.TotpEnabledAt != nil,
+	}
+}
+
+// 保存されているハッシュとパスワードを照合する
+// 一致しない場合は field の入力値が正しくないことを表す検証エラーを返す
+func checkPassword(user model.User, password string, field string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return validator.NewFieldError(field, validator.CodeIncorrect, nil)
+		}
+		return err
+	}
+	return nil
+}
+
+// メールアドレスの確認リンク（フロントエンドのページで POST /verify-email にトークンを送信する）
+func verificationURL(userId uint, nonce string, now time.Time) string {
+	token := signToken(purposeEmailVerification, strconv.FormatUint(uint64(userId), 10)+":"+nonce, now.Add(emailVerificationTTL))
+	return os.Getenv("FE_URL") + "/verify-email?token=" + url.QueryEscape(token)
+}
+
+// 署名と有効期限を検証して、アクセストークンのクレームを取り出す
+func parseAccessToken(accessToken string) (jwt.MapClaims, bool) {
+	token, err := jwt.Parse(accessToken, func(t *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("SECRET")), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	return claims, ok
+}
+
+// tokens_valid_after に保存する日時
+// JWT の iat は秒単位のため、秒未満を切り捨てて同じ秒に発行した新しいトークンが無効にならないようにする
+func tokensValidAfter(now time.Time) time.Time {
+	return now.Truncate(time.Second)
+}
+
+// 推測されにくいランダムなトークンを生成
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
-	// トークン文字列を返す
-	return tokenString, nil
+	return hex.EncodeToString(b), nil
+}
+
+// 保存用のトークンのハッシュ（SHA-256）
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// 文字列を先頭から最大 max 文字に切り詰める
+func truncateRunes(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
+
+// ユーザーに設定されたタイムゾーンを取得
+func userLocation(ur repository.IUserRepository, userId uint) (*time.Location, error) {
+	user := model.User{}
+	if err := ur.GetUserById(&user, userId); err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return loc, nil
 }
@@ -0,0 +1,28 @@
+package validator
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type IChecklistValidator interface {
+	ChecklistItemValidate(item model.ChecklistItem) error
+}
+
+type checklistValidator struct {
+	cfg Config // テキストの長さの上限
+}
+
+func NewChecklistValidator(cfg Config) IChecklistValidator {
+	return &checklistValidator{cfg}
+}
+
+func (cv *checklistValidator) ChecklistItemValidate(item model.ChecklistItem) error {
+	return toValidationError(validation.ValidateStruct(&item,
+		validation.Field( // テキストの検証
+			&item.Text,
+			required(),
+			maxLength(cv.cfg.ChecklistTextMaxLength),
+		),
+	))
+}
@@ -0,0 +1,28 @@
+package validator
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type ICommentValidator interface {
+	CommentValidate(comment model.Comment) error
+}
+
+type commentValidator struct {
+	cfg Config // 本文の長さの上限
+}
+
+func NewCommentValidator(cfg Config) ICommentValidator {
+	return &commentValidator{cfg}
+}
+
+func (cv *commentValidator) CommentValidate(comment model.Comment) error {
+	return toValidationError(validation.ValidateStruct(&comment,
+		validation.Field( // 本文の検証
+			&comment.Body,
+			required(),
+			maxLength(cv.cfg.CommentMaxLength),
+		),
+	))
+}
@@ -0,0 +1,41 @@
+package validator
+
+import (
+	"os"
+	"strconv"
+)
+
+// 入力値の長さの上限・下限（環境変数で変更できる）
+type Config struct {
+	TaskTitleMaxLength     int // TASK_TITLE_MAX_LENGTH: タスクのタイトル（既定は10文字）
+	LabelMaxLength         int // LABEL_MAX_LENGTH: ラベル（既定は30文字）
+	EmailMaxLength         int // EMAIL_MAX_LENGTH: メールアドレス（既定は30文字）
+	PasswordMinLength      int // PASSWORD_MIN_LENGTH: パスワード（既定は6文字以上）
+	PasswordMaxLength      int // PASSWORD_MAX_LENGTH: パスワード（既定は30文字以下）
+	CommentMaxLength       int // COMMENT_MAX_LENGTH: コメント（既定は1000文字）
+	ChecklistTextMaxLength int // CHECKLIST_TEXT_MAX_LENGTH: チェックリストの項目（既定は200文字）
+	CustomTextMaxLength    int // CUSTOM_TEXT_MAX_LENGTH: text のカスタムフィールドの値（既定は500文字）
+}
+
+// 環境変数から設定を読み込む（未設定または不正な値の場合は既定値を使う）
+func LoadConfig() Config {
+	return Config{
+		TaskTitleMaxLength:     envInt("TASK_TITLE_MAX_LENGTH", 10),
+		LabelMaxLength:         envInt("LABEL_MAX_LENGTH", 30),
+		EmailMaxLength:         envInt("EMAIL_MAX_LENGTH", 30),
+		PasswordMinLength:      envInt("PASSWORD_MIN_LENGTH", 6),
+		PasswordMaxLength:      envInt("PASSWORD_MAX_LENGTH", 30),
+		CommentMaxLength:       envInt("COMMENT_MAX_LENGTH", 1000),
+		ChecklistTextMaxLength: envInt("CHECKLIST_TEXT_MAX_LENGTH", 200),
+		CustomTextMaxLength:    envInt("CUSTOM_TEXT_MAX_LENGTH", 500),
+	}
+}
+
+// 正の整数の環境変数を読み込む
+func envInt(name string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || n < 1 {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/mailer"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type IEmailPreferenceValidator interface {
+	EmailPreferenceValidate(pref model.EmailPreference) error
+}
+
+type emailPreferenceValidator struct{}
+
+func NewEmailPreferenceValidator() IEmailPreferenceValidator {
+	return &emailPreferenceValidator{}
+}
+
+func (ev *emailPreferenceValidator) EmailPreferenceValidate(pref model.EmailPreference) error {
+	return toValidationError(validation.ValidateStruct(&pref,
+		validation.Field( // 言語の検証
+			&pref.Language,
+			required(),
+			oneOf(mailer.Languages...),
+		),
+		validation.Field( // 送信時刻の検証（0〜23時）
+			&pref.DigestHour,
+			between(0, 23)...,
+		),
+	))
+}
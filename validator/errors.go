@@ -0,0 +1,178 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// 検証エラーのコード（クライアントはメッセージではなくコードで判定する）
+const (
+	CodeRequired        = "required"         // 必須項目が空
+	CodeTooLong         = "too_long"         // 長すぎる（params: max）
+	CodeLength          = "length"           // 長さが範囲外（params: min, max）
+	CodeOutOfRange      = "out_of_range"     // 数値が範囲外（params: min, max）
+	CodeInvalidChoice   = "invalid_choice"   // 選択肢にない値（params: choices）
+	CodeInvalidFormat   = "invalid_format"   // 形式が正しくない（params: format）
+	CodeInvalidEmail    = "invalid_email"    // メールアドレスの形式ではない
+	CodeInvalidURL      = "invalid_url"      // http(s) のURLではない
+	CodeInvalidTimezone = "invalid_timezone" // タイムゾーン名として読み込めない
+	CodeInvalidType     = "invalid_type"     // 値の型が正しくない（params: expected）
+	CodeDuplicate       = "duplicate"        // 重複している
+	CodeTooMany         = "too_many"         // 件数が多すぎる（params: max）
+	CodeTooDeep         = "too_deep"         // 階層が深すぎる（params: max）
+	CodeNotAllowed      = "not_allowed"      // この条件では指定できない
+	CodeUndefinedField  = "undefined_field"  // プロジェクトに定義されていないカスタムフィールド
+	CodeUnknownUser     = "unknown_user"     // 存在しないユーザー
+	CodeIncorrect       = "incorrect"        // 登録されている値と一致しない（現在のパスワードなど）
+	CodeInvalid         = "invalid"          // その他（params: detail）
+)
+
+// 1つのフィールドの検証エラー
+type FieldError struct {
+	Field  string         // フィールド名（JSONのキー。ネストした値は "labels.0" のようにドットで区切る）
+	Code   string         // エラーコード
+	Params map[string]any // メッセージに埋め込むパラメータ
+}
+
+// 入力値の検証エラー（フィールドごとのエラーの一覧）
+// コントローラーは 422 Unprocessable Entity として、Accept-Language の言語のメッセージを付けて返す
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// ログなどで使う英語のメッセージ
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message(LanguageEnglish)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// 指定された言語のレスポンスに変換
+func (e *ValidationError) Response(lang string) model.ValidationErrorResponse {
+	res := model.ValidationErrorResponse{
+		Message: message(lang, "validation_failed", "", nil),
+		Errors:  []model.FieldErrorResponse{},
+	}
+	for _, f := range e.Fields {
+		res.Errors = append(res.Errors, model.FieldErrorResponse{
+			Field:   f.Field,
+			Code:    f.Code,
+			Message: f.Message(lang),
+			Params:  f.Params,
+		})
+	}
+	return res
+}
+
+// 1つのフィールドの検証エラーを作成（ユースケースでの検証に使う）
+func NewFieldError(field string, code string, params map[string]any) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Code: code, Params: params}}}
+}
+
+// ルールが返すエラー（エラーコードとパラメータを保持する）
+type ruleError struct {
+	code   string
+	params map[string]any
+}
+
+func (e ruleError) Error() string {
+	return message(LanguageEnglish, e.code, "value", e.params)
+}
+
+// ozzo-validation のルールが失敗した場合に、エラーコード付きのエラーを返すルール
+type codedRule struct {
+	rule   validation.Rule
+	code   string
+	params map[string]any
+}
+
+func (r codedRule) Validate(value interface{}) error {
+	err := r.rule.Validate(value)
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(validation.InternalError); ok {
+		return err
+	}
+	return ruleError{r.code, r.params}
+}
+
+// 必須項目
+func required() validation.Rule {
+	return codedRule{validation.Required, CodeRequired, nil}
+}
+
+// 最大文字数
+func maxLength(max int) validation.Rule {
+	return codedRule{validation.RuneLength(0, max), CodeTooLong, map[string]any{"max": max}}
+}
+
+// 文字数の範囲
+func length(min int, max int) validation.Rule {
+	return codedRule{validation.RuneLength(min, max), CodeLength, map[string]any{"min": min, "max": max}}
+}
+
+// 数値の範囲（Min と Max の2つのルールとして使う）
+func between(min int, max int) []validation.Rule {
+	params := map[string]any{"min": min, "max": max}
+	return []validation.Rule{
+		codedRule{validation.Min(min), CodeOutOfRange, params},
+		codedRule{validation.Max(max), CodeOutOfRange, params},
+	}
+}
+
+// 選択肢のいずれか
+func oneOf(choices ...string) validation.Rule {
+	values := make([]interface{}, len(choices))
+	for i, c := range choices {
+		values[i] = c
+	}
+	return codedRule{validation.In(values...), CodeInvalidChoice, map[string]any{"choices": strings.Join(choices, ", ")}}
+}
+
+// エラーコード付きのエラーを返すルール
+func coded(rule validation.Rule, code string, params map[string]any) validation.Rule {
+	return codedRule{rule, code, params}
+}
+
+// ozzo-validation の検証結果をフィールドごとの ValidationError に変換
+// 検証エラー以外（nil を含む）はそのまま返す
+func toValidationError(err error) error {
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		return err
+	}
+	ve := &ValidationError{}
+	collectFieldErrors(ve, "", errs)
+	if len(ve.Fields) == 0 {
+		return nil
+	}
+	sort.Slice(ve.Fields, func(i, j int) bool { return ve.Fields[i].Field < ve.Fields[j].Field })
+	return ve
+}
+
+// ネストしたエラー（validation.Each など）はフィールド名をドットで連結して展開する
+func collectFieldErrors(ve *ValidationError, prefix string, errs validation.Errors) {
+	for field, err := range errs {
+		if err == nil {
+			continue
+		}
+		name := field
+		if prefix != "" {
+			name = prefix + "." + field
+		}
+		switch e := err.(type) {
+		case validation.Errors:
+			collectFieldErrors(ve, name, e)
+		case ruleError:
+			ve.Fields = append(ve.Fields, FieldError{Field: name, Code: e.code, Params: e.params})
+		default:
+			ve.Fields = append(ve.Fields, FieldError{Field: name, Code: CodeInvalid, Params: map[string]any{"detail": err.Error()}})
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 検証エラーのメッセージの言語
+const (
+	LanguageJapanese = "ja"
+	LanguageEnglish  = "en"
+)
+
+// 既定の言語（Accept-Language に対応する言語がない場合）
+const defaultLanguage = LanguageJapanese
+
+// エラーコードごとのメッセージ（{field} と params の値を埋め込む）
+var messages = map[string]map[string]string{
+	LanguageJapanese: {
+		"validation_failed": "入力内容に誤りがあります",
+		CodeRequired:        "{field}は必須です",
+		CodeTooLong:         "{field}は{max}文字以内で入力してください",
+		CodeLength:          "{field}は{min}文字以上{max}文字以内で入力してください",
+		CodeOutOfRange:      "{field}は{min}から{max}の範囲で指定してください",
+		CodeInvalidChoice:   "{field}は次のいずれかを指定してください: {choices}",
+		CodeInvalidFormat:   "{field}の形式が正しくありません（{format}）",
+		CodeInvalidEmail:    "{field}はメールアドレスの形式で入力してください",
+		CodeInvalidURL:      "{field}はhttpまたはhttpsのURLで入力してください",
+		CodeInvalidTimezone: "{field}はタイムゾーン名（例: Asia/Tokyo）で指定してください",
+		CodeInvalidType:     "{field}の値の型が正しくありません（{expected}）",
+		CodeDuplicate:       "{field}に重複した値があります",
+		CodeTooMany:         "{field}は{max}件以内にしてください",
+		CodeTooDeep:         "{field}の階層は{max}段以内にしてください",
+		CodeNotAllowed:      "{field}はこの条件では指定できません",
+		CodeUndefinedField:  "{field}はプロジェクトに定義されていません",
+		CodeUnknownUser:     "{field}のユーザーが存在しません",
+		CodeIncorrect:       "{field}が正しくありません",
+		CodeInvalid:         "{field}が正しくありません（{detail}）",
+	},
+	LanguageEnglish: {
+		"validation_failed": "The request contains invalid fields",
+		CodeRequired:        "{field} is required",
+		CodeTooLong:         "{field} must be at most {max} characters",
+		CodeLength:          "{field} must be between {min} and {max} characters",
+		CodeOutOfRange:      "{field} must be between {min} and {max}",
+		CodeInvalidChoice:   "{field} must be one of: {choices}",
+		CodeInvalidFormat:   "{field} has an invalid format ({format})",
+		CodeInvalidEmail:    "{field} must be a valid email address",
+		CodeInvalidURL:      "{field} must be an http or https URL",
+		CodeInvalidTimezone: "{field} must be a time zone name such as Asia/Tokyo",
+		CodeInvalidType:     "{field} has an invalid type ({expected})",
+		CodeDuplicate:       "{field} must not contain duplicates",
+		CodeTooMany:         "{field} must have at most {max} items",
+		CodeTooDeep:         "{field} must be at most {max} levels deep",
+		CodeNotAllowed:      "{field} is not allowed here",
+		CodeUndefinedField:  "{field} is not defined in the project",
+		CodeUnknownUser:     "{field} refers to a user that does not exist",
+		CodeIncorrect:       "{field} is incorrect",
+		CodeInvalid:         "{field} is invalid ({detail})",
+	},
+}
+
+// 指定された言語のメッセージ
+func (e FieldError) Message(lang string) string {
+	return message(lang, e.Code, e.Field, e.Params)
+}
+
+// メッセージのテンプレートにフィールド名とパラメータを埋め込む
+func message(lang string, code string, field string, params map[string]any) string {
+	catalog, ok := messages[lang]
+	if !ok {
+		catalog = messages[defaultLanguage]
+	}
+	template, ok := catalog[code]
+	if !ok {
+		template = catalog[CodeInvalid]
+	}
+	replacements := []string{"{field}", field}
+	for k, v := range params {
+		replacements = append(replacements, "{"+k+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
+
+// Accept-Language ヘッダーから、メッセージを用意している言語のうち最も優先度の高いものを選ぶ
+// 例: "en-US,en;q=0.9,ja;q=0.8" → "en"
+func NegotiateLanguage(acceptLanguage string) string {
+	best, bestQ := defaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, q := strings.TrimSpace(part), 1.0
+		if i := strings.Index(tag, ";"); i >= 0 {
+			for _, param := range strings.Split(tag[i+1:], ";") {
+				if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+					if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+						q = parsed
+					}
+				}
+			}
+			tag = strings.TrimSpace(tag[:i])
+		}
+		// 地域などのサブタグは無視して主言語で判定する（en-US → en）
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if _, ok := messages[lang]; ok && q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,26 @@
+package validator
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type IPasskeyValidator interface {
+	PasskeyRegistrationValidate(req model.PasskeyRegistrationRequest) error
+}
+
+type passkeyValidator struct{}
+
+func NewPasskeyValidator() IPasskeyValidator {
+	return &passkeyValidator{}
+}
+
+func (kv *passkeyValidator) PasskeyRegistrationValidate(req model.PasskeyRegistrationRequest) error {
+	return toValidationError(validation.ValidateStruct(&req,
+		validation.Field( // パスキーの名前の検証
+			&req.Name,
+			required(),
+			maxLength(50),
+		),
+	))
+}
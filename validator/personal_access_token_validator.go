@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"strings"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type IPersonalAccessTokenValidator interface {
+	PersonalAccessTokenValidate(token model.PersonalAccessToken) error
+}
+
+type personalAccessTokenValidator struct{}
+
+func NewPersonalAccessTokenValidator() IPersonalAccessTokenValidator {
+	return &personalAccessTokenValidator{}
+}
+
+func (pv *personalAccessTokenValidator) PersonalAccessTokenValidate(token model.PersonalAccessToken) error {
+	return toValidationError(validation.ValidateStruct(&token,
+		validation.Field( // トークン名の検証
+			&token.Name,
+			required(),
+			maxLength(50),
+		),
+		validation.Field( // スコープの検証
+			&token.Scopes,
+			validation.By(func(value interface{}) error {
+				return scopesValidate(token.Scopes)
+			}),
+		),
+	))
+}
+
+// スコープが1つ以上あり、定義済みのスコープのみで重複がないことを検証
+// （Labels は driver.Valuer を実装しているため、Required や Each ではなくここで検証する）
+func scopesValidate(scopes model.Labels) error {
+	if len(scopes) == 0 {
+		return ruleError{CodeRequired, nil}
+	}
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		if !isPersonalAccessTokenScope(scope) {
+			return ruleError{CodeInvalidChoice, map[string]any{"choices": strings.Join(model.PersonalAccessTokenScopes, ", ")}}
+		}
+		if seen[scope] {
+			return ruleError{CodeDuplicate, nil}
+		}
+		seen[scope] = true
+	}
+	return nil
+}
+
+func isPersonalAccessTokenScope(scope string) bool {
+	for _, s := range model.PersonalAccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
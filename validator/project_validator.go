@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"regexp"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// カスタムフィールドのキーの形式（検索クエリの cf.<キー> で使えるもの）
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,29}$`)
+
+type IProjectValidator interface {
+	ProjectValidate(project model.Project) error
+	CustomFieldValidate(field model.CustomField) error
+}
+
+type projectValidator struct{}
+
+func NewProjectValidator() IProjectValidator {
+	return &projectValidator{}
+}
+
+func (pv *projectValidator) ProjectValidate(project model.Project) error {
+	return toValidationError(validation.ValidateStruct(&project,
+		validation.Field( // プロジェクト名の検証
+			&project.Name,
+			required(),
+			maxLength(30),
+		),
+	))
+}
+
+func (pv *projectValidator) CustomFieldValidate(field model.CustomField) error {
+	return toValidationError(validation.ValidateStruct(&field,
+		validation.Field( // キーの検証（英小文字で始まり、英小文字・数字・アンダースコアのみ、30文字以内）
+			&field.Key,
+			required(),
+			coded(validation.Match(customFieldKeyPattern), CodeInvalidFormat, map[string]any{"format": "^[a-z][a-z0-9_]{0,29}$"}),
+		),
+		validation.Field( // フィールド名の検証
+			&field.Name,
+			required(),
+			maxLength(30),
+		),
+		validation.Field( // 種類の検証
+			&field.Type,
+			required(),
+			oneOf(model.CustomFieldText, model.CustomFieldNumber, model.CustomFieldDate, model.CustomFieldSingleSelect,
+				model.CustomFieldMultiSelect, model.CustomFieldUser),
+		),
+		validation.Field( // 選択肢の検証
+			&field.Options,
+			validation.Each(
+				required(),
+				maxLength(50),
+			),
+			validation.By(func(value interface{}) error {
+				return optionsValidate(field.Type, field.Options)
+			}),
+		),
+	))
+}
+
+// 選択肢の数と重複を検証
+// 選択肢は single_select / multi_select の場合のみ必須で、それ以外の種類では指定できない
+// （Labels は driver.Valuer を実装しているため、Required や Length ではなくここで検証する）
+func optionsValidate(fieldType string, options model.Labels) error {
+	isSelect := fieldType == model.CustomFieldSingleSelect || fieldType == model.CustomFieldMultiSelect
+	if isSelect && len(options) == 0 {
+		return ruleError{CodeRequired, nil}
+	}
+	if !isSelect && len(options) > 0 {
+		return ruleError{CodeNotAllowed, nil}
+	}
+	if len(options) > 50 {
+		return ruleError{CodeTooMany, map[string]any{"max": 50}}
+	}
+	seen := map[string]bool{}
+	for _, option := range options {
+		if seen[option] {
+			return ruleError{CodeDuplicate, nil}
+		}
+		seen[option] = true
+	}
+	return nil
+}
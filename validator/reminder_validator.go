@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+)
+
+// リマインダーの通知タイミングの上限（期限の30日前まで）
+const maxReminderOffsetMinutes = 30 * 24 * 60
+
+// webhook の送信先として許可するURLのスキーム
+var webhookSchemePattern = regexp.MustCompile(`^https?://`)
+
+// キャリアグレードNATの共有アドレス（RFC 6598）。プライベートアドレスと同様に外部からは到達できない
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+type IReminderValidator interface {
+	ReminderValidate(reminder model.Reminder) error
+}
+
+type reminderValidator struct{}
+
+func NewReminderValidator() IReminderValidator {
+	return &reminderValidator{}
+}
+
+func (rv *reminderValidator) ReminderValidate(reminder model.Reminder) error {
+	// webhook の場合のみ送信先URLを必須にする
+	urlRules := []validation.Rule{
+		coded(is.URL, CodeInvalidURL, nil),
+		coded(validation.Match(webhookSchemePattern), CodeInvalidURL, nil),
+		validation.By(validateWebhookHost),
+	}
+	if reminder.Channel == model.ReminderChannelWebhook {
+		urlRules = append([]validation.Rule{required()}, urlRules...)
+	}
+	return toValidationError(validation.ValidateStruct(&reminder,
+		validation.Field( // 通知タイミングの検証（期限の30日前まで）
+			&reminder.OffsetMinutes,
+			between(0, maxReminderOffsetMinutes)...,
+		),
+		validation.Field( // 通知方法の検証
+			&reminder.Channel,
+			required(),
+			oneOf(model.ReminderChannelEmail, model.ReminderChannelWebhook, model.ReminderChannelInApp),
+		),
+		validation.Field(&reminder.WebhookUrl, urlRules...),
+	))
+}
+
+// 送信先のホストが localhost や内部のIPアドレスの場合は拒否する
+// ホスト名の場合は名前解決後のアドレスを送信時に確認する（usecase の webhook の送信処理）
+func validateWebhookHost(value interface{}) error {
+	raw, _ := value.(string)
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ruleError{CodeNotAllowed, nil}
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !IsPublicAddress(ip) {
+		return ruleError{CodeNotAllowed, nil}
+	}
+	return nil
+}
+
+// サーバーから外部への送信先として許可するアドレスかどうか
+// ループバック、プライベート、リンクローカル（クラウドのメタデータサービスを含む）、未指定、マルチキャストのアドレスは許可しない
+func IsPublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,37 @@
+package validator
+
+import (
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	"github.com/DaigoSugiyama0317/Echo-REST-API/query"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type ISavedFilterValidator interface {
+	SavedFilterValidate(filter model.SavedFilter) error
+}
+
+type savedFilterValidator struct{}
+
+func NewSavedFilterValidator() ISavedFilterValidator {
+	return &savedFilterValidator{}
+}
+
+func (fv *savedFilterValidator) SavedFilterValidate(filter model.SavedFilter) error {
+	if err := validation.ValidateStruct(&filter,
+		validation.Field( // フィルター名の検証
+			&filter.Name,
+			required(),
+			maxLength(30),
+		),
+		validation.Field( // クエリの検証
+			&filter.Query,
+			required(),
+			maxLength(500),
+		),
+	); err != nil {
+		return toValidationError(err)
+	}
+	// クエリが解析できるかどうかを検証（位置情報付きのエラーを返す）
+	_, err := query.Parse(filter.Query)
+	return err
+}
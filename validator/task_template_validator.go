@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"strconv"
+
+	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// テンプレートに含められるタスク数と階層の上限
+const (
+	maxTemplateItems = 200
+	maxTemplateDepth = 5
+)
+
+type ITaskTemplateValidator interface {
+	TaskTemplateValidate(template model.TaskTemplate) error
+}
+
+type taskTemplateValidator struct{}
+
+func NewTaskTemplateValidator() ITaskTemplateValidator {
+	return &taskTemplateValidator{}
+}
+
+func (ttv *taskTemplateValidator) TaskTemplateValidate(template model.TaskTemplate) error {
+	return toValidationError(validation.ValidateStruct(&template,
+		validation.Field( // テンプレート名の検証
+			&template.Name,
+			required(),
+			maxLength(30),
+		),
+		validation.Field( // 説明の検証
+			&template.Description,
+			maxLength(500),
+		),
+		validation.Field( // タスク構成の検証
+			&template.Items,
+			validation.By(validateBlueprints),
+		),
+	))
+}
+
+// タスクの設計図のツリーを検証（1件以上必須、件数と階層の上限、各タスクのタイトル必須）
+// 個々のタスクのエラーは "items.0.children.1.title" のようにネストしたフィールドとして返す
+// （TaskBlueprints は driver.Valuer を実装しているため、Required ではなくここで検証する）
+func validateBlueprints(value interface{}) error {
+	items, _ := value.(model.TaskBlueprints)
+	if len(items) == 0 {
+		return ruleError{CodeRequired, nil}
+	}
+	var count func(items []model.TaskBlueprint) int
+	count = func(items []model.TaskBlueprint) int {
+		n := len(items)
+		for _, item := range items {
+			n += count(item.Children)
+		}
+		return n
+	}
+	if count(items) > maxTemplateItems {
+		return ruleError{CodeTooMany, map[string]any{"max": maxTemplateItems}}
+	}
+
+	var walk func(items []model.TaskBlueprint, depth int) error
+	walk = func(items []model.TaskBlueprint, depth int) error {
+		if depth > maxTemplateDepth {
+			return ruleError{CodeTooDeep, map[string]any{"max": maxTemplateDepth}}
+		}
+		errs := validation.Errors{}
+		for i, item := range items {
+			itemErrs := validation.Errors{}
+			if item.Title == "" {
+				itemErrs["title"] = ruleError{CodeRequired, nil}
+			}
+			if len(item.Children) > 0 {
+				itemErrs["children"] = walk(item.Children, depth+1)
+			}
+			errs[strconv.Itoa(i)] = itemErrs.Filter()
+		}
+		return errs.Filter()
+	}
+	return walk(items, 1)
+}
@@ -1,26 +1,133 @@
 package validator
 
 import (
+	"math"
+
 	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
 type ITaskValidator interface {
 	TaskValidate(task model.Task) error
+	// カスタムフィールドの値を、タスクのプロジェクトで定義されたフィールドに照らして検証
+	CustomFieldValuesValidate(values model.CustomFieldValues, fields []model.CustomField) error
 }
 
-type TaskValidator struct{}
+type TaskValidator struct {
+	cfg Config // タイトルやラベルの長さの上限
+}
 
-func NewTaskValidator() ITaskValidator {
-	return &TaskValidator{}
+func NewTaskValidator(cfg Config) ITaskValidator {
+	return &TaskValidator{cfg}
 }
 
 func (tv *TaskValidator) TaskValidate(task model.Task) error {
-	return validation.ValidateStruct(&task, // タイトルの検証
+	return toValidationError(validation.ValidateStruct(&task, // タイトルの検証
 		validation.Field(
 			&task.Title,
-			validation.Required.Error("title is required"),            // タイトルは必須項目
-			validation.RuneLength(1, 10).Error("limited max 10 char"), // タイトルの長さは1文字以上10文字以下である必要がある
+			required(),                           // タイトルは必須項目
+			maxLength(tv.cfg.TaskTitleMaxLength), // タイトルの長さは設定された文字数以下である必要がある
+		),
+		validation.Field( // ステータスの検証
+			&task.Status,
+			oneOf(model.TaskStatusOpen, model.TaskStatusDone),
+		),
+		validation.Field( // 優先度の検証
+			&task.Priority,
+			oneOf(model.TaskPriorityNone, model.TaskPriorityLow, model.TaskPriorityMedium, model.TaskPriorityHigh),
+		),
+		validation.Field( // 繰り返しの検証（空文字は繰り返しなし）
+			&task.Recurrence,
+			oneOf(model.TaskRecurrenceDaily, model.TaskRecurrenceWeekly, model.TaskRecurrenceMonthly, model.TaskRecurrenceYearly),
 		),
-	)
+		validation.Field( // ラベルの検証
+			&task.Labels,
+			validation.Each(
+				required(),
+				maxLength(tv.cfg.LabelMaxLength),
+			),
+		),
+	))
+}
+
+func (tv *TaskValidator) CustomFieldValuesValidate(values model.CustomFieldValues, fields []model.CustomField) error {
+	errs := validation.Errors{}
+	defined := map[string]bool{}
+	for _, field := range fields {
+		defined[field.Key] = true
+		value, ok := values[field.Key]
+		if !ok {
+			if field.Required {
+				errs[field.Key] = ruleError{CodeRequired, nil}
+			}
+			continue
+		}
+		if err := tv.customFieldValueValidate(field, value); err != nil {
+			errs[field.Key] = err
+		}
+	}
+	// プロジェクトに定義されていないキーは指定できない
+	for key := range values {
+		if !defined[key] {
+			errs[key] = ruleError{CodeUndefinedField, nil}
+		}
+	}
+	return toValidationError(validation.Errors{"custom_fields": errs.Filter()}.Filter())
+}
+
+// フィールドの種類に応じて値を検証（JSONの数値は float64 として扱う）
+func (tv *TaskValidator) customFieldValueValidate(field model.CustomField, value any) error {
+	switch field.Type {
+	case model.CustomFieldText:
+		s, ok := value.(string)
+		if !ok {
+			return ruleError{CodeInvalidType, map[string]any{"expected": "string"}}
+		}
+		return validation.Validate(s, required(), maxLength(tv.cfg.CustomTextMaxLength))
+	case model.CustomFieldNumber:
+		if _, ok := value.(float64); !ok {
+			return ruleError{CodeInvalidType, map[string]any{"expected": "number"}}
+		}
+	case model.CustomFieldDate:
+		s, ok := value.(string)
+		if !ok {
+			return ruleError{CodeInvalidType, map[string]any{"expected": "string"}}
+		}
+		return validation.Validate(s,
+			required(),
+			coded(validation.Date("2006-01-02"), CodeInvalidFormat, map[string]any{"format": "YYYY-MM-DD"}),
+		)
+	case model.CustomFieldSingleSelect:
+		s, ok := value.(string)
+		if !ok {
+			return ruleError{CodeInvalidType, map[string]any{"expected": "string"}}
+		}
+		return validation.Validate(s, required(), oneOf(field.Options...))
+	case model.CustomFieldMultiSelect:
+		list, ok := value.([]any)
+		if !ok {
+			return ruleError{CodeInvalidType, map[string]any{"expected": "array of strings"}}
+		}
+		seen := map[string]bool{}
+		for _, v := range list {
+			s, ok := v.(string)
+			if !ok {
+				return ruleError{CodeInvalidType, map[string]any{"expected": "array of strings"}}
+			}
+			if err := validation.Validate(s, oneOf(field.Options...)); err != nil {
+				return err
+			}
+			if seen[s] {
+				return ruleError{CodeDuplicate, nil}
+			}
+			seen[s] = true
+		}
+	case model.CustomFieldUser:
+		// ユーザーIDの形式のみを検証（ユーザーが存在するかはユースケースで確認する）
+		id, ok := value.(float64)
+		if !ok || id < 1 || id > math.MaxUint32 || id != math.Trunc(id) {
+			return ruleError{CodeInvalidType, map[string]any{"expected": "user id"}}
+		}
+	}
+	return nil
 }
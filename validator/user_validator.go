@@ -1,38 +1,139 @@
 package validator
 
 import (
+	"time"
+
 	"github.com/DaigoSugiyama0317/Echo-REST-API/model"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
 )
 
+// 2段階認証の確認コードの最大文字数（区切りや空白を含むリカバリーコードも入る長さ）
+const maxTwoFactorCodeLength = 32
+
 // ユーザー入力の検証に必要なメソッドを定義するインターフェース
 type IUserValidator interface {
-	UserValidate(user model.User) error // ユーザーのバリデーションを実行するメソッド
+	UserValidate(user model.User) error                           // ユーザーのバリデーションを実行するメソッド
+	PasswordChangeValidate(req model.PasswordChangeRequest) error // パスワード変更のリクエストのバリデーション
+	PasswordResetValidate(req model.PasswordResetRequest) error   // パスワード再設定のリクエストのバリデーション
+	EmailChangeValidate(req model.EmailChangeRequest) error       // メールアドレス変更のリクエストのバリデーション
+	AccountDeleteValidate(req model.AccountDeleteRequest) error   // アカウント削除のリクエストのバリデーション
+	TwoFactorCodeValidate(req model.TwoFactorCodeRequest) error   // 2段階認証の確認コードのバリデーション
+	MfaLoginValidate(req model.MfaLoginRequest) error             // ログインの2段階目のリクエストのバリデーション
 }
 
 // IUserValidator インターフェースを実装する構造体
-type userValidator struct{}
+type userValidator struct {
+	cfg Config // メールアドレスとパスワードの長さの制限
+}
 
 // コンストラクタ関数
-func NewUserValidator() IUserValidator {
-	return &userValidator{}
+func NewUserValidator(cfg Config) IUserValidator {
+	return &userValidator{cfg}
 }
 
 // ユーザーのフィールドをバリデーションするメソッド
 // Ozzoバリデーションライブラリを使用して、Email と Password フィールドを検証
 func (tv *userValidator) UserValidate(user model.User) error {
-	return validation.ValidateStruct(&user, // 構造体のバリデーションを実行
+	return toValidationError(validation.ValidateStruct(&user, // 構造体のバリデーションを実行
 		validation.Field(
 			&user.Email, // Email フィールドを検証
-			validation.Required.Error("email is required"),
-			validation.RuneLength(1, 30).Error("limited max 30 char"), // 1～30文字の範囲で制限
-			is.Email.Error("is not valid email format"),               // 有効なメール形式かどうかチェック
+			required(),
+			maxLength(tv.cfg.EmailMaxLength),       // 設定された文字数以下に制限
+			coded(is.Email, CodeInvalidEmail, nil), // 有効なメール形式かどうかチェック
 		),
 		validation.Field(
 			&user.Password, // Password フィールドを検証
-			validation.Required.Error("password is required"),               // 必須チェック
-			validation.RuneLength(6, 30).Error("limited min 6 max 30 char"), // 6～30文字の範囲で制限
+			required(),     // 必須チェック
+			length(tv.cfg.PasswordMinLength, tv.cfg.PasswordMaxLength), // 設定された文字数の範囲で制限
+		),
+		validation.Field(
+			&user.Timezone, // Timezone フィールドを検証（省略可）
+			validation.By(validateTimezone),
+		),
+	))
+}
+
+// パスワード変更のリクエストをバリデーションするメソッド
+// 新しいパスワードには登録時と同じ文字数の制限を適用する
+func (tv *userValidator) PasswordChangeValidate(req model.PasswordChangeRequest) error {
+	return toValidationError(validation.ValidateStruct(&req,
+		validation.Field(&req.CurrentPassword, required()),
+		validation.Field(
+			&req.NewPassword,
+			required(),
+			length(tv.cfg.PasswordMinLength, tv.cfg.PasswordMaxLength),
 		),
-	)
+	))
+}
+
+// パスワード再設定のリクエストをバリデーションするメソッド
+// 新しいパスワードには登録時と同じ文字数の制限を適用する
+func (tv *userValidator) PasswordResetValidate(req model.PasswordResetRequest) error {
+	return toValidationError(validation.ValidateStruct(&req,
+		validation.Field(&req.Token, required()),
+		validation.Field(
+			&req.NewPassword,
+			required(),
+			length(tv.cfg.PasswordMinLength, tv.cfg.PasswordMaxLength),
+		),
+	))
+}
+
+// メールアドレス変更のリクエストをバリデーションするメソッド
+// 新しいメールアドレスには登録時と同じ制限を適用する
+func (tv *userValidator) EmailChangeValidate(req model.EmailChangeRequest) error {
+	return toValidationError(validation.ValidateStruct(&req,
+		validation.Field(
+			&req.NewEmail,
+			required(),
+			maxLength(tv.cfg.EmailMaxLength),
+			coded(is.Email, CodeInvalidEmail, nil),
+		),
+		validation.Field(&req.CurrentPassword, required()),
+	))
+}
+
+// アカウント削除のリクエストをバリデーションするメソッド
+func (tv *userValidator) AccountDeleteValidate(req model.AccountDeleteRequest) error {
+	return toValidationError(validation.ValidateStruct(&req,
+		validation.Field(&req.Password, required()),
+	))
+}
+
+// 2段階認証の確認コード（認証アプリのコードまたはリカバリーコード）をバリデーションするメソッド
+func (tv *userValidator) TwoFactorCodeValidate(req model.TwoFactorCodeRequest) error {
+	return toValidationError(validation.ValidateStruct(&req,
+		validation.Field(&req.Code, required(), maxLength(maxTwoFactorCodeLength)),
+	))
+}
+
+// ログインの2段階目のリクエストをバリデーションするメソッド
+func (tv *userValidator) MfaLoginValidate(req model.MfaLoginRequest) error {
+	return toValidationError(validation.ValidateStruct(&req,
+		validation.Field(&req.MfaToken, required()),
+		validation.Field(&req.Code, required(), maxLength(maxTwoFactorCodeLength)),
+	))
+}
+
+// IANAのタイムゾーン名として読み込めるかどうかを検証
+func validateTimezone(value interface{}) error {
+	tz, _ := value.(string)
+	if tz == "" {
+		return nil
+	}
+	if !IsTimezone(tz) {
+		return ruleError{CodeInvalidTimezone, nil}
+	}
+	return nil
+}
+
+// IANAのタイムゾーン名かどうか
+// time.LoadLocation が受け付ける空文字（UTC）と Local（サーバーのタイムゾーン）はDBで解釈できないため許可しない
+func IsTimezone(tz string) bool {
+	if tz == "" || tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
 }